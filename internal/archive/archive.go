@@ -0,0 +1,63 @@
+// Package archive is the file format of `justdoit export --format json`: every
+// mapped list with its tasks (sections, subtasks, completed and hidden tasks
+// included) and the calendar events linked to them. Task metadata travels in
+// the notes and event descriptions unchanged, so IDs inside it refer to the
+// exporting account until an import remaps them.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+)
+
+// Version is the format written by Write. Read accepts it and older ones.
+const Version = 1
+
+type Archive struct {
+	Version     int    `json:"version"`
+	ExportedAt  string `json:"exported_at"`
+	Profile     string `json:"profile,omitempty"`
+	CalendarID  string `json:"calendar_id"`
+	DefaultList string `json:"default_list,omitempty"`
+	Lists       []List `json:"lists"`
+	// Events are the time blocks linked from tasks, from CalendarID.
+	Events []*calendar.Event `json:"events"`
+}
+
+// List is one mapped task list. Name is the local name from config.json,
+// Title the name in Google Tasks.
+type List struct {
+	Name  string        `json:"name"`
+	ID    string        `json:"id"`
+	Title string        `json:"title"`
+	Tasks []*tasks.Task `json:"tasks"`
+}
+
+func Write(w io.Writer, a *Archive) error {
+	a.Version = Version
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+func Read(r io.Reader) (*Archive, error) {
+	var a Archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("not a justdoit archive: %w", err)
+	}
+	if a.Version == 0 {
+		return nil, fmt.Errorf("not a justdoit archive: missing version")
+	}
+	if a.Version > Version {
+		return nil, fmt.Errorf("archive version %d is newer than this build supports (%d)", a.Version, Version)
+	}
+	return &a, nil
+}
@@ -0,0 +1,36 @@
+package archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	in := &Archive{CalendarID: "primary", Lists: []List{{Name: "Inbox", ID: "in", Tasks: []*tasks.Task{{Id: "t1", Title: "Pay rent"}}}}}
+	if err := Write(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != Version || out.Lists[0].Tasks[0].Title != "Pay rent" {
+		t.Fatalf("round trip = %+v", out)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	for input, want := range map[string]string{
+		`{"lists": []}`:   "missing version",
+		`{"version": 99}`: "newer than this build",
+		`[1, 2]`:          "not a justdoit archive",
+	} {
+		if _, err := Read(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("Read(%s) error = %v, want %q", input, err, want)
+		}
+	}
+}
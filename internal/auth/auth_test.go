@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// memoryStore is a TokenStore kept in memory; saveErr makes Save fail and
+// forget makes it drop what it saved.
+type memoryStore struct {
+	tok     *oauth2.Token
+	saves   int
+	saveErr error
+	forget  bool
+}
+
+func (m *memoryStore) Load() (*oauth2.Token, error) {
+	if m.tok == nil {
+		return nil, os.ErrNotExist
+	}
+	return m.tok, nil
+}
+
+func (m *memoryStore) Save(tok *oauth2.Token) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if !m.forget {
+		m.tok = tok
+	}
+	m.saves++
+	return nil
+}
+
+func (m *memoryStore) Delete() error {
+	m.tok = nil
+	return nil
+}
+
+func (m *memoryStore) Name() string { return "memory" }
+
+// fakeSource hands out tok, or fails with err.
+type fakeSource struct {
+	tok *oauth2.Token
+	err error
+}
+
+func (f *fakeSource) Token() (*oauth2.Token, error) {
+	return f.tok, f.err
+}
+
+func TestValidState(t *testing.T) {
+	login := loginState{state: "abc123"}
+	if !login.validState("abc123") {
+		t.Fatalf("expected the login's own state to be valid")
+	}
+	for _, state := range []string{"", "abc12", "abc1234", "ABC123"} {
+		if login.validState(state) {
+			t.Fatalf("expected state %q to be rejected", state)
+		}
+	}
+}
+
+func TestCodeFromInput(t *testing.T) {
+	login := loginState{state: "abc123"}
+	if code, err := login.codeFromInput("  4/0bare-code \n"); err != nil || code != "4/0bare-code" {
+		t.Fatalf("expected a bare code to pass through, got %q (%v)", code, err)
+	}
+	if code, err := login.codeFromInput("http://127.0.0.1:1/callback?state=abc123&code=4/0xyz"); err != nil || code != "4/0xyz" {
+		t.Fatalf("expected the code from the redirect URL, got %q (%v)", code, err)
+	}
+	if _, err := login.codeFromInput("http://127.0.0.1:1/callback?state=other&code=4/0xyz"); err == nil || !strings.Contains(err.Error(), "state mismatch") {
+		t.Fatalf("expected a state mismatch, got %v", err)
+	}
+	if _, err := login.codeFromInput("http://127.0.0.1:1/callback?state=abc123&code="); err == nil {
+		t.Fatalf("expected an empty code to be rejected")
+	}
+}
+
+func TestPersistingSourceSavesRotatedTokens(t *testing.T) {
+	current := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}
+	store := &memoryStore{tok: current}
+	base := &fakeSource{tok: &oauth2.Token{AccessToken: "new", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}}
+	source := newPersistingSource(base, store, current)
+
+	tok, err := source.Token()
+	if err != nil || tok.AccessToken != "new" {
+		t.Fatalf("unexpected token %#v (%v)", tok, err)
+	}
+	if store.saves != 1 || store.tok.AccessToken != "new" {
+		t.Fatalf("expected the rotated token saved once, got %d save(s) of %#v", store.saves, store.tok)
+	}
+	if _, err := source.Token(); err != nil || store.saves != 1 {
+		t.Fatalf("expected an unchanged token not to be saved again, got %d save(s) (%v)", store.saves, err)
+	}
+}
+
+func TestPersistingSourceKeepsTokenWhenSaveFails(t *testing.T) {
+	current := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh"}
+	store := &memoryStore{tok: current, saveErr: errors.New("keyring locked")}
+	base := &fakeSource{tok: &oauth2.Token{AccessToken: "new", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}}
+
+	tok, err := newPersistingSource(base, store, current).Token()
+	if err != nil || tok.AccessToken != "new" {
+		t.Fatalf("expected the refreshed token despite the save failure, got %#v (%v)", tok, err)
+	}
+}
+
+func TestPersistingSourceNeedsReauth(t *testing.T) {
+	revoked := &oauth2.RetrieveError{ErrorCode: "invalid_grant", ErrorDescription: "Token has been expired or revoked."}
+	current := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh"}
+	_, err := newPersistingSource(&fakeSource{err: revoked}, &memoryStore{}, current).Token()
+	if !errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("expected ErrReauthRequired for invalid_grant, got %v", err)
+	}
+
+	expired := &oauth2.Token{AccessToken: "old", Expiry: time.Now().Add(-time.Minute)}
+	if _, err := newPersistingSource(&fakeSource{}, &memoryStore{}, expired).Token(); !errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("expected ErrReauthRequired without a refresh token, got %v", err)
+	}
+
+	outage := &oauth2.RetrieveError{ErrorCode: "temporarily_unavailable"}
+	if _, err := newPersistingSource(&fakeSource{err: outage}, &memoryStore{}, current).Token(); err == nil || errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("expected a server failure not to ask for a new login, got %v", err)
+	}
+}
+
+func TestIsRevoked(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&oauth2.RetrieveError{ErrorCode: "invalid_grant"}, true},
+		{&oauth2.RetrieveError{ErrorCode: "unauthorized_client"}, true},
+		{fmt.Errorf("refresh: %w", &oauth2.RetrieveError{ErrorCode: "invalid_grant"}), true},
+		{&oauth2.RetrieveError{ErrorCode: "invalid_request"}, false},
+		{errors.New("invalid_grant"), false},
+		{nil, false},
+	} {
+		if got := isRevoked(tc.err); got != tc.want {
+			t.Fatalf("isRevoked(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func writeTokenFile(t *testing.T, tok *oauth2.Token) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token.json")
+	if err := saveToken(path, tok); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateFileTokenMovesIntoEmptyStore(t *testing.T) {
+	path := writeTokenFile(t, &oauth2.Token{AccessToken: "a", RefreshToken: "r"})
+	store := &memoryStore{}
+	moved, err := MigrateFileToken(path, store)
+	if err != nil || !moved {
+		t.Fatalf("expected the token moved, got %v (%v)", moved, err)
+	}
+	if store.tok == nil || store.tok.RefreshToken != "r" {
+		t.Fatalf("expected the store to hold the token, got %#v", store.tok)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected token.json deleted after the copy, got %v", err)
+	}
+	if moved, err := MigrateFileToken(path, store); moved || err != nil {
+		t.Fatalf("expected nothing to do without a file, got %v (%v)", moved, err)
+	}
+}
+
+func TestMigrateFileTokenKeepsFileItDidNotCopy(t *testing.T) {
+	fileTok := &oauth2.Token{AccessToken: "a", RefreshToken: "file-refresh"}
+	for name, store := range map[string]*memoryStore{
+		"different token in store": {tok: &oauth2.Token{AccessToken: "b", RefreshToken: "store-refresh"}},
+		"save fails":               {saveErr: errors.New("keyring locked")},
+		"store drops the token":    {forget: true},
+	} {
+		path := writeTokenFile(t, fileTok)
+		before := store.tok
+		moved, err := MigrateFileToken(path, store)
+		if moved || err == nil {
+			t.Fatalf("%s: expected the migration to stop, got %v (%v)", name, moved, err)
+		}
+		if _, statErr := os.Stat(path); statErr != nil {
+			t.Fatalf("%s: expected token.json kept, got %v", name, statErr)
+		}
+		if store.tok != before {
+			t.Fatalf("%s: expected the stored token untouched, got %#v", name, store.tok)
+		}
+		if name == "different token in store" && !errors.Is(err, ErrStoreHasToken) {
+			t.Fatalf("%s: expected ErrStoreHasToken, got %v", name, err)
+		}
+	}
+}
+
+func TestMigrateFileTokenFinishesInterruptedMigration(t *testing.T) {
+	tok := &oauth2.Token{AccessToken: "a", RefreshToken: "r"}
+	path := writeTokenFile(t, tok)
+	store := &memoryStore{tok: tok}
+	if moved, err := MigrateFileToken(path, store); err != nil || !moved || store.saves != 0 {
+		t.Fatalf("expected the identical file removed without saving, got %v, %d save(s) (%v)", moved, store.saves, err)
+	}
+}
+
+func TestOpenTokenStoreAutoKeepsExistingFile(t *testing.T) {
+	path := writeTokenFile(t, &oauth2.Token{AccessToken: "a", RefreshToken: "r"})
+	for _, kind := range []string{"", StoreAuto, StoreFile} {
+		store, err := OpenTokenStore(kind, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := store.(fileStore); !ok {
+			t.Fatalf("token_store %q: expected the existing token.json in use, got %s", kind, store.Name())
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected token.json untouched, got %v", err)
+	}
+}
+
+func TestSavedClientNeverSignsIn(t *testing.T) {
+	creds := filepath.Join(t.TempDir(), "credentials.json")
+	data := `{"installed":{"client_id":"id","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`
+	if err := os.WriteFile(creds, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+	if _, err := SavedClient(ctx, creds, &memoryStore{}); !errors.Is(err, ErrNotSignedIn) {
+		t.Fatalf("expected ErrNotSignedIn without a token, got %v", err)
+	}
+	valid := &oauth2.Token{AccessToken: "a", RefreshToken: "r", Expiry: time.Now().Add(time.Hour)}
+	if client, err := SavedClient(ctx, creds, &memoryStore{tok: valid}); err != nil || client == nil {
+		t.Fatalf("expected a client from the saved token, got %v", err)
+	}
+}
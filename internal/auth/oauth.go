@@ -2,12 +2,18 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -21,7 +27,133 @@ var scopes = []string{
 	calendar.CalendarScope,
 }
 
-func Client(ctx context.Context, credentialsPath, tokenPath string) (*http.Client, error) {
+// revokeURL is Google's OAuth token revocation endpoint.
+const revokeURL = "https://oauth2.googleapis.com/revoke"
+
+// ErrNotSignedIn means no token is saved and signing in was not allowed.
+var ErrNotSignedIn = errors.New("not signed in; run `justdoit auth login`")
+
+// Client returns an HTTP client using the saved token, signing in first when
+// there is none or it was revoked.
+func Client(ctx context.Context, credentialsPath string, store TokenStore) (*http.Client, error) {
+	config, err := loadConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	client, err := savedClient(ctx, config, store)
+	switch {
+	case err == nil:
+		return client, nil
+	case errors.Is(err, ErrReauthRequired):
+		fmt.Fprintln(os.Stderr, "Saved authorization has expired or was revoked; signing in again.")
+	case !errors.Is(err, ErrNotSignedIn):
+		return nil, err
+	}
+
+	tok, err := login(config, store, NoBrowser())
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.NewClient(ctx, newPersistingSource(config.TokenSource(ctx, tok), store, tok)), nil
+}
+
+// SavedClient is Client for unattended runs: it never signs in, failing with
+// ErrNotSignedIn or ErrReauthRequired instead.
+func SavedClient(ctx context.Context, credentialsPath string, store TokenStore) (*http.Client, error) {
+	config, err := loadConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return savedClient(ctx, config, store)
+}
+
+func savedClient(ctx context.Context, config *oauth2.Config, store TokenStore) (*http.Client, error) {
+	saved, err := store.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotSignedIn
+	}
+	if err != nil {
+		// An unreadable token is as good as none; signing in replaces it.
+		return nil, fmt.Errorf("%w (%v)", ErrNotSignedIn, err)
+	}
+	source := newPersistingSource(config.TokenSource(ctx, saved), store, saved)
+	// Refresh now, so a revoked or expired grant leads to a new login
+	// instead of failing inside the first API call.
+	if _, err := source.Token(); err != nil {
+		return nil, err
+	}
+	return oauth2.NewClient(ctx, source), nil
+}
+
+// Login signs in and saves the token to store. With noBrowser the user
+// opens the URL on any machine and pastes the redirected address back, for
+// SSH sessions and containers where the loopback callback is unreachable.
+func Login(credentialsPath string, store TokenStore, noBrowser bool) (*oauth2.Token, error) {
+	config, err := loadConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return login(config, store, noBrowser)
+}
+
+// NoBrowser reports whether logins should skip the loopback callback: set
+// JUSTDOIT_NO_BROWSER, or run over SSH without a display.
+func NoBrowser() bool {
+	if os.Getenv("JUSTDOIT_NO_BROWSER") != "" {
+		return true
+	}
+	return os.Getenv("SSH_CONNECTION") != "" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// Refresh exchanges the saved refresh token for a new access token right
+// away and saves the result.
+func Refresh(ctx context.Context, credentialsPath string, store TokenStore) (*oauth2.Token, error) {
+	config, err := loadConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if saved.RefreshToken == "" {
+		return nil, ErrReauthRequired
+	}
+	expired := *saved
+	expired.Expiry = time.Now().Add(-time.Minute)
+	return newPersistingSource(config.TokenSource(ctx, &expired), store, saved).Token()
+}
+
+// Revoke asks Google to revoke tok; revoking the refresh token also ends
+// every access token issued from it.
+func Revoke(ctx context.Context, tok *oauth2.Token) error {
+	value := tok.RefreshToken
+	if value == "" {
+		value = tok.AccessToken
+	}
+	if value == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoke token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// Google answers 400 invalid_token for tokens that are already revoked.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("revoke token: %s", resp.Status)
+	}
+	return nil
+}
+
+func loadConfig(credentialsPath string) (*oauth2.Config, error) {
 	// #nosec G304 -- credentials path is user-configured
 	creds, err := os.ReadFile(credentialsPath)
 	if err != nil {
@@ -31,19 +163,58 @@ func Client(ctx context.Context, credentialsPath, tokenPath string) (*http.Clien
 	if err != nil {
 		return nil, fmt.Errorf("parse credentials: %w", err)
 	}
-	ok, err := tokenFromFile(tokenPath)
-	if err == nil {
-		return config.Client(ctx, ok), nil
-	}
+	return config, nil
+}
 
-	tok, err := getTokenFromWeb(config)
+func login(config *oauth2.Config, store TokenStore, noBrowser bool) (*oauth2.Token, error) {
+	var tok *oauth2.Token
+	var err error
+	if noBrowser {
+		tok, err = getTokenFromWebManual(config)
+	} else {
+		tok, err = getTokenFromWeb(config)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if err := saveToken(tokenPath, tok); err != nil {
-		return nil, err
+	if err := store.Save(tok); err != nil {
+		return nil, fmt.Errorf("save token to %s: %w", store.Name(), err)
 	}
-	return config.Client(ctx, tok), nil
+	return tok, nil
+}
+
+// loginState holds the per-login values that tie the authorization response
+// to this request: a random state and the PKCE verifier.
+type loginState struct {
+	state    string
+	verifier string
+}
+
+func newLoginState() (loginState, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return loginState{}, fmt.Errorf("generate state: %w", err)
+	}
+	return loginState{
+		state:    base64.RawURLEncoding.EncodeToString(buf),
+		verifier: oauth2.GenerateVerifier(),
+	}, nil
+}
+
+func (l loginState) authURL(config *oauth2.Config) string {
+	return config.AuthCodeURL(l.state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(l.verifier))
+}
+
+func (l loginState) validState(state string) bool {
+	return subtle.ConstantTimeCompare([]byte(state), []byte(l.state)) == 1
+}
+
+func (l loginState) exchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+	tok, err := config.Exchange(ctx, code, oauth2.VerifierOption(l.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+	return tok, nil
 }
 
 func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
@@ -54,6 +225,11 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 	redirectURL := fmt.Sprintf("http://%s/callback", ln.Addr().String())
 	cfg := *config
 	cfg.RedirectURL = redirectURL
+	login, err := newLoginState()
+	if err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
 
 	codeCh := make(chan string, 1)
 	errCh := make(chan error, 1)
@@ -64,17 +240,29 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 				http.NotFound(w, r)
 				return
 			}
-			if r.URL.Query().Get("state") != "state-token" {
+			query := r.URL.Query()
+			if !login.validState(query.Get("state")) {
 				http.Error(w, "Invalid state", http.StatusBadRequest)
 				return
 			}
-			code := r.URL.Query().Get("code")
+			if denied := query.Get("error"); denied != "" {
+				http.Error(w, "Authorization failed: "+denied, http.StatusBadRequest)
+				select {
+				case errCh <- fmt.Errorf("authorization failed: %s", denied):
+				default:
+				}
+				return
+			}
+			code := query.Get("code")
 			if code == "" {
 				http.Error(w, "Missing code", http.StatusBadRequest)
 				return
 			}
 			_, _ = fmt.Fprintln(w, "Auth complete. You can close this tab and return to the CLI.")
-			codeCh <- code
+			select {
+			case codeCh <- code:
+			default:
+			}
 		}),
 	}
 
@@ -84,7 +272,7 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 		}
 	}()
 
-	authURL := cfg.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+	authURL := login.authURL(&cfg)
 	fmt.Println()
 	fmt.Println("Authorize justdoit in your browser:")
 	fmt.Printf("  %s\n", clickableLink("Open authorization link", authURL))
@@ -100,7 +288,7 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		_ = srv.Shutdown(context.Background())
-		return cfg.Exchange(ctx, code)
+		return login.exchange(ctx, &cfg, code)
 	case err := <-errCh:
 		_ = srv.Shutdown(context.Background())
 		return nil, err
@@ -110,18 +298,61 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 	}
 }
 
+// getTokenFromWebManual has the user open the URL anywhere and paste back the
+// loopback address the browser is redirected to; that page failing to load is
+// expected.
 func getTokenFromWebManual(config *oauth2.Config) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Open this URL in your browser and paste the authorization code:\n%v\n", authURL)
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
+	login, err := newLoginState()
+	if err != nil {
+		return nil, err
+	}
+	cfg := *config
+	if cfg.RedirectURL == "" || strings.HasPrefix(cfg.RedirectURL, "urn:ietf:wg:oauth:2.0:oob") {
+		cfg.RedirectURL = "http://127.0.0.1/callback"
+	}
+	config = &cfg
+	authURL := login.authURL(config)
+	fmt.Println("Open this URL in a browser on any machine:")
+	fmt.Printf("  %s\n", authURL)
+	fmt.Println("After approving, the browser is sent to a page that fails to load.")
+	fmt.Print("Paste that page's full address here (or just the code): ")
+	var input string
+	if _, err := fmt.Scan(&input); err != nil {
 		return nil, fmt.Errorf("read authorization code: %w", err)
 	}
-	return config.Exchange(context.Background(), code)
+	code, err := login.codeFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return login.exchange(ctx, config, code)
 }
 
-func clickableLink(text, url string) string {
-	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
+// codeFromInput accepts a bare authorization code or the full redirect URL;
+// a URL must carry this login's state.
+func (l loginState) codeFromInput(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.Contains(input, "code=") {
+		return input, nil
+	}
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("parse redirect URL: %w", err)
+	}
+	query := parsed.Query()
+	if !l.validState(query.Get("state")) {
+		return "", fmt.Errorf("redirect URL does not match this login (state mismatch)")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("redirect URL has no authorization code")
+	}
+	return code, nil
+}
+
+func clickableLink(text, link string) string {
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", link, text)
 }
 
 func tokenFromFile(path string) (*oauth2.Token, error) {
@@ -138,17 +369,34 @@ func tokenFromFile(path string) (*oauth2.Token, error) {
 	return &tok, nil
 }
 
+// saveToken writes token through a temporary file and a rename, so a crash
+// mid-write never leaves a truncated token behind.
 func saveToken(path string, token *oauth2.Token) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return err
 	}
-	// #nosec G304 -- token path is user-configured
-	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	file, err := os.CreateTemp(dir, ".token-*.json")
 	if err != nil {
 		return err
 	}
-	defer func() { _ = file.Close() }()
+	tmp := file.Name()
+	defer func() { _ = os.Remove(tmp) }()
+	if err := file.Chmod(0o600); err != nil {
+		_ = file.Close()
+		return err
+	}
 	// #nosec G117 -- writing the OAuth token to a 0600 file is the intended behavior here.
-	return json.NewEncoder(file).Encode(token)
+	if err := json.NewEncoder(file).Encode(token); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
 }
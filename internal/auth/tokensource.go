@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrReauthRequired means the saved refresh token no longer works (revoked,
+// expired or missing) and the user has to sign in again.
+var ErrReauthRequired = errors.New("authorization expired or was revoked; run `justdoit auth login` to sign in again")
+
+// persistingSource writes every rotated token back to its store, so refreshed
+// access tokens (and any new refresh token) survive the process.
+type persistingSource struct {
+	mu    sync.Mutex
+	base  oauth2.TokenSource
+	store TokenStore
+	last  *oauth2.Token
+}
+
+func newPersistingSource(base oauth2.TokenSource, store TokenStore, current *oauth2.Token) *persistingSource {
+	return &persistingSource{base: base, store: store, last: current}
+}
+
+func (s *persistingSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last != nil && !s.last.Valid() && s.last.RefreshToken == "" {
+		return nil, ErrReauthRequired
+	}
+	tok, err := s.base.Token()
+	if err != nil {
+		if isRevoked(err) {
+			return nil, fmt.Errorf("%w (%v)", ErrReauthRequired, err)
+		}
+		return nil, fmt.Errorf("refresh token: %w", err)
+	}
+	if s.last == nil || tok.AccessToken != s.last.AccessToken || tok.RefreshToken != s.last.RefreshToken {
+		// The token works either way; failing here would only break this
+		// run, while the next one can refresh again.
+		if err := s.store.Save(tok); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: refreshed token not saved to %s: %v\n", s.store.Name(), err)
+		}
+		s.last = tok
+	}
+	return tok, nil
+}
+
+// isRevoked reports whether the token endpoint rejected the refresh token
+// itself, as opposed to a network or server failure.
+func isRevoked(err error) bool {
+	var retrieve *oauth2.RetrieveError
+	if !errors.As(err, &retrieve) {
+		return false
+	}
+	return retrieve.ErrorCode == "invalid_grant" || retrieve.ErrorCode == "unauthorized_client"
+}
+
+// tokenInfoURL reports the scopes granted to an access token.
+const tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+// Verify refreshes the stored token when needed and returns it with the
+// scopes Google granted it. It never starts a login.
+func Verify(ctx context.Context, credentialsPath string, store TokenStore) (*oauth2.Token, []string, error) {
+	config, err := loadConfig(credentialsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	saved, err := store.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	tok, err := newPersistingSource(config.TokenSource(ctx, saved), store, saved).Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenInfoURL+"?"+url.Values{"access_token": {tok.AccessToken}}.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("token info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("token info: %s", resp.Status)
+	}
+	var info struct {
+		Scope string `json:"scope"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, nil, fmt.Errorf("token info: %w", err)
+	}
+	return tok, strings.Fields(info.Scope), nil
+}
+
+// MissingScopes returns the scopes justdoit needs that granted lacks.
+func MissingScopes(granted []string) []string {
+	have := map[string]bool{}
+	for _, scope := range granted {
+		have[scope] = true
+	}
+	missing := []string{}
+	for _, scope := range scopes {
+		if !have[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Token store kinds accepted by the token_store setting.
+const (
+	StoreAuto          = "auto"
+	StoreFile          = "file"
+	StoreSecretService = "secret-service"
+	StoreKeyring       = "keyring"
+)
+
+// TokenStore keeps the OAuth token between runs. Load returns an error
+// matching os.ErrNotExist when nothing is stored.
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(*oauth2.Token) error
+	Delete() error
+	// Name describes where the token lives, for status output.
+	Name() string
+}
+
+// ErrStoreHasToken means a migration found a different token already in the
+// target store; neither the store nor token.json was changed.
+var ErrStoreHasToken = errors.New("the store already holds a different token")
+
+// OpenTokenStore returns the store for kind. tokenPath is the token.json
+// location: the file store uses it, the others key their entry by it. "auto"
+// (or "") keeps using an existing token.json and otherwise picks the Secret
+// Service when it is reachable, without querying it. Naming secret-service or
+// keyring explicitly moves a token.json left from before into that store.
+func OpenTokenStore(kind, tokenPath string) (TokenStore, error) {
+	store, err := openStore(kind, tokenPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := store.(fileStore); ok || isAuto(kind) {
+		return store, nil
+	}
+	moved, err := MigrateFileToken(tokenPath, store)
+	if errors.Is(err, ErrStoreHasToken) {
+		fmt.Fprintf(os.Stderr, "warning: %v; using the %s and leaving the file alone\n", err, store.Name())
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if moved {
+		fmt.Fprintf(os.Stderr, "Moved OAuth token from %s to the %s.\n", tokenPath, store.Name())
+	}
+	return store, nil
+}
+
+// OpenSecureStore returns the secret-service or keyring store for kind, for
+// `justdoit auth migrate`.
+func OpenSecureStore(kind, tokenPath string) (TokenStore, error) {
+	if isAuto(kind) || strings.EqualFold(strings.TrimSpace(kind), StoreFile) {
+		return nil, fmt.Errorf("migrate to %s or %s, not %q", StoreSecretService, StoreKeyring, kind)
+	}
+	return openStore(kind, tokenPath)
+}
+
+func isAuto(kind string) bool {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	return kind == "" || kind == StoreAuto
+}
+
+func openStore(kind, tokenPath string) (TokenStore, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", StoreAuto:
+		if _, err := os.Stat(tokenPath); errors.Is(err, os.ErrNotExist) && secretServiceAvailable() {
+			return secretServiceStore{account: tokenPath}, nil
+		}
+		return fileStore{path: tokenPath}, nil
+	case StoreFile:
+		return fileStore{path: tokenPath}, nil
+	case StoreSecretService:
+		if !secretServiceAvailable() {
+			return nil, fmt.Errorf("token_store %q needs secret-tool (libsecret) and a D-Bus session", kind)
+		}
+		return secretServiceStore{account: tokenPath}, nil
+	case StoreKeyring:
+		if _, err := exec.LookPath("keyctl"); err != nil {
+			return nil, fmt.Errorf("token_store %q needs keyctl (keyutils)", kind)
+		}
+		return keyringStore{description: "justdoit:" + tokenPath}, nil
+	default:
+		return nil, fmt.Errorf("unknown token_store %q (use auto, file, secret-service or keyring)", kind)
+	}
+}
+
+// MigrateFileToken copies the plaintext token.json into store and deletes
+// the file once the store gives the same token back. It reports false when
+// there is no file. When the store already holds a different token both are
+// kept and the error matches ErrStoreHasToken.
+func MigrateFileToken(tokenPath string, store TokenStore) (bool, error) {
+	tok, err := tokenFromFile(tokenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read %s for migration: %w", tokenPath, err)
+	}
+	existing, err := store.Load()
+	switch {
+	case err == nil:
+		// Identical means an earlier migration stopped before deleting.
+		if !sameToken(existing, tok) {
+			return false, fmt.Errorf("%w: %s and the %s differ", ErrStoreHasToken, tokenPath, store.Name())
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if err := store.Save(tok); err != nil {
+			return false, fmt.Errorf("migrate token to %s: %w", store.Name(), err)
+		}
+		saved, err := store.Load()
+		if err != nil {
+			return false, fmt.Errorf("migrate token to %s: read back: %w", store.Name(), err)
+		}
+		if !sameToken(saved, tok) {
+			return false, fmt.Errorf("migrate token to %s: the stored copy differs; %s was kept", store.Name(), tokenPath)
+		}
+	default:
+		return false, err
+	}
+	if err := os.Remove(tokenPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func sameToken(a, b *oauth2.Token) bool {
+	return a.AccessToken == b.AccessToken && a.RefreshToken == b.RefreshToken
+}
+
+type fileStore struct {
+	path string
+}
+
+func (s fileStore) Load() (*oauth2.Token, error) { return tokenFromFile(s.path) }
+func (s fileStore) Save(tok *oauth2.Token) error { return saveToken(s.path, tok) }
+func (s fileStore) Name() string                 { return "file " + s.path }
+
+func (s fileStore) Delete() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// secretServiceStore keeps the token in the freedesktop Secret Service
+// (GNOME Keyring, KWallet) through secret-tool.
+type secretServiceStore struct {
+	account string
+}
+
+func secretServiceAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (s secretServiceStore) attrs() []string {
+	return []string{"service", "justdoit", "account", s.account}
+}
+
+func (s secretServiceStore) Load() (*oauth2.Token, error) {
+	out, err := runTool(nil, "secret-tool", append([]string{"lookup"}, s.attrs()...)...)
+	// secret-tool exits non-zero with no output when nothing matches.
+	if len(bytes.TrimSpace(out)) == 0 {
+		if err != nil && !isExitError(err) {
+			return nil, err
+		}
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(out)
+}
+
+func (s secretServiceStore) Save(tok *oauth2.Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"store", "--label=justdoit OAuth token"}, s.attrs()...)
+	_, err = runTool(data, "secret-tool", args...)
+	return err
+}
+
+func (s secretServiceStore) Delete() error {
+	_, err := runTool(nil, "secret-tool", append([]string{"clear"}, s.attrs()...)...)
+	if isExitError(err) {
+		return nil
+	}
+	return err
+}
+
+func (s secretServiceStore) Name() string { return "Secret Service" }
+
+// keyringStore keeps the token in the Linux kernel user keyring through
+// keyctl. The keyring lives in memory: it is gone after the user's last
+// session ends, so a new login is needed after a reboot.
+type keyringStore struct {
+	description string
+}
+
+func (s keyringStore) keyID() (string, error) {
+	out, err := runTool(nil, "keyctl", "search", "@u", "user", s.description)
+	if isExitError(err) {
+		return "", os.ErrNotExist
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s keyringStore) Load() (*oauth2.Token, error) {
+	id, err := s.keyID()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runTool(nil, "keyctl", "pipe", id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(out)
+}
+
+func (s keyringStore) Save(tok *oauth2.Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	_, err = runTool(data, "keyctl", "padd", "user", s.description, "@u")
+	return err
+}
+
+func (s keyringStore) Delete() error {
+	id, err := s.keyID()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = runTool(nil, "keyctl", "unlink", id, "@u")
+	return err
+}
+
+func (s keyringStore) Name() string { return "kernel keyring" }
+
+func runTool(stdin []byte, name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- fixed tool names; arguments are not shell-interpreted
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return out, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+func decodeToken(data []byte) (*oauth2.Token, error) {
+	var tok oauth2.Token
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return nil, fmt.Errorf("decode stored token: %w", err)
+	}
+	return &tok, nil
+}
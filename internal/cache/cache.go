@@ -13,6 +13,7 @@ type Cache struct {
 	CalendarMeta map[string]CalendarMeta   `json:"calendar_meta"`
 	Calendars    map[string]*CalendarCache `json:"calendars"`
 	Tasks        *TasksCache               `json:"tasks"`
+	Handles      *Handles                  `json:"handles,omitempty"`
 	SyncedAt     string                    `json:"synced_at"`
 }
 
@@ -49,6 +50,7 @@ func Default() *Cache {
 		Tasks: &TasksCache{
 			Lists: map[string]map[string]TaskEntry{},
 		},
+		Handles: &Handles{Tasks: map[string]Handle{}},
 	}
 }
 
@@ -101,4 +103,10 @@ func ensureDefaults(cache *Cache) {
 	if cache.Tasks.Lists == nil {
 		cache.Tasks.Lists = map[string]map[string]TaskEntry{}
 	}
+	if cache.Handles == nil {
+		cache.Handles = &Handles{}
+	}
+	if cache.Handles.Tasks == nil {
+		cache.Handles.Tasks = map[string]Handle{}
+	}
 }
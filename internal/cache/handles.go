@@ -0,0 +1,70 @@
+package cache
+
+import "time"
+
+// HandleTTL is how long a handle is kept after its task was last shown.
+const HandleTTL = 30 * 24 * time.Hour
+
+// Handles maps task IDs to short numbers (#42) shown in command output. A
+// task keeps its number for as long as it keeps being listed.
+type Handles struct {
+	Tasks map[string]Handle `json:"tasks"`
+}
+
+type Handle struct {
+	N       int    `json:"n"`
+	ListID  string `json:"list_id"`
+	Section string `json:"section,omitempty"`
+	Title   string `json:"title"`
+	Seen    string `json:"seen"`
+}
+
+// Assign returns the handle number for a task, giving it the lowest free one
+// when it has none yet.
+func (h *Handles) Assign(taskID, listID, section, title string, now time.Time) int {
+	if h.Tasks == nil {
+		h.Tasks = map[string]Handle{}
+	}
+	handle, ok := h.Tasks[taskID]
+	if !ok {
+		handle.N = h.lowestFree()
+	}
+	handle.ListID = listID
+	handle.Section = section
+	handle.Title = title
+	handle.Seen = now.UTC().Format(time.RFC3339)
+	h.Tasks[taskID] = handle
+	return handle.N
+}
+
+func (h *Handles) lowestFree() int {
+	used := map[int]bool{}
+	for _, handle := range h.Tasks {
+		used[handle.N] = true
+	}
+	n := 1
+	for used[n] {
+		n++
+	}
+	return n
+}
+
+// Lookup returns the task holding handle number n.
+func (h *Handles) Lookup(n int) (string, Handle, bool) {
+	for id, handle := range h.Tasks {
+		if handle.N == n {
+			return id, handle, true
+		}
+	}
+	return "", Handle{}, false
+}
+
+// Prune forgets handles of tasks not shown since maxAge, freeing their numbers.
+func (h *Handles) Prune(now time.Time, maxAge time.Duration) {
+	for id, handle := range h.Tasks {
+		seen, err := time.Parse(time.RFC3339, handle.Seen)
+		if err != nil || now.Sub(seen) > maxAge {
+			delete(h.Tasks, id)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
 
 	"justdoit/internal/recurrence"
 	"justdoit/internal/sync"
@@ -14,12 +15,16 @@ import (
 
 func newAddCmd() *cobra.Command {
 	var (
-		list    string
-		dateStr string
-		every   string
-		timeStr string
-		section string
-		notes   string
+		list     string
+		dateStr  string
+		every    string
+		timeStr  string
+		section  string
+		parent   string
+		notes    string
+		priority string
+		startStr string
+		tagFlags []string
 	)
 	cmd := &cobra.Command{
 		Use:   "add [title]",
@@ -30,6 +35,18 @@ func newAddCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			priorityLevel, err := parsePriority(priority)
+			if err != nil {
+				return err
+			}
+			startDate, err := parseStartInput(startStr, app.Now(), app.Location)
+			if err != nil {
+				return err
+			}
+			tags, err := parseTags(tagFlags)
+			if err != nil {
+				return err
+			}
 			title := strings.Join(args, " ")
 			recurrenceFromTitle := []string{}
 			if strings.TrimSpace(every) == "" {
@@ -44,13 +61,31 @@ func newAddCmd() *cobra.Command {
 				return err
 			}
 			sectionName := strings.TrimSpace(section)
+			if sectionName != "" && parent != "" {
+				return fmt.Errorf("--section and --parent cannot be combined; subtasks follow their parent's section")
+			}
 			parentID := ""
+			if parent != "" {
+				parentRef, err := resolveTaskRef(app, list, parent)
+				if err != nil {
+					return err
+				}
+				if list != "" && parentRef.ListID != listID {
+					return fmt.Errorf("parent task is not in list %s", list)
+				}
+				listID = parentRef.ListID
+				parentTask, err := resolveSubtaskParent(app, listID, parentRef.ID)
+				if err != nil {
+					return err
+				}
+				parentID = parentTask.Id
+			}
 			if sectionName != "" {
 				sectionTask, err := ensureSectionTask(app, listID, sectionName)
 				if err != nil {
 					return err
 				}
-				parentID = sectionTask.Id
+				notes = setNotesSection(notes, sectionTask.Id)
 			}
 			baseDate, err := timeparse.ParseDate(dateStr, app.Now(), app.Location)
 			if err != nil {
@@ -87,14 +122,19 @@ func newAddCmd() *cobra.Command {
 			input := sync.CreateInput{
 				ListID:     listID,
 				Title:      title,
-				Notes:      notes,
+				Notes:      setNotesStartDate(setNotesTags(setNotesPriority(notes, priorityLevel), tags, nil), startDate),
 				Due:        due,
 				Recurrence: recurrence,
 				TimeStart:  start,
 				TimeEnd:    end,
 				ParentID:   parentID,
+				ColorID:    tagEventColor(app.Config, tags),
 			}
-			_, event, err := app.Sync.Create(input)
+			var event *calendar.Event
+			err = recordOp(app, "add", taskScope(listID), func() error {
+				_, event, err = app.Sync.Create(input)
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -110,7 +150,11 @@ func newAddCmd() *cobra.Command {
 	cmd.Flags().StringVar(&every, "every", "", "Recurrence (e.g. 'daily', 'weekly')")
 	cmd.Flags().StringVar(&timeStr, "time", "", "Time block (HH:MM-HH:MM or 1h)")
 	cmd.Flags().StringVar(&section, "section", "", "Section (sublist) name")
+	cmd.Flags().StringVar(&parent, "parent", "", "Parent task ID or #handle (creates a subtask)")
 	cmd.Flags().StringVar(&notes, "notes", "", "Notes for the task")
+	cmd.Flags().StringVar(&priority, "priority", "", "Priority (p1-p4)")
+	cmd.Flags().StringVar(&startStr, "start", "", "Start date; the task stays hidden from next until then (e.g. 'monday', '+1w')")
+	cmd.Flags().StringSliceVar(&tagFlags, "tag", nil, "Tag (repeatable, e.g. --tag errand)")
 	return cmd
 }
 
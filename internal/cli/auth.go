@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"justdoit/internal/auth"
+	"justdoit/internal/config"
+	"justdoit/internal/paths"
+)
+
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage the Google sign-in",
+	}
+	cmd.AddCommand(newAuthLoginCmd())
+	cmd.AddCommand(newAuthStatusCmd())
+	cmd.AddCommand(newAuthLogoutCmd())
+	cmd.AddCommand(newAuthRefreshCmd())
+	cmd.AddCommand(newAuthMigrateCmd())
+	return cmd
+}
+
+func newAuthLoginCmd() *cobra.Command {
+	var noBrowser bool
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Sign in to Google and save a new token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			credPath, store, err := loadTokenStore(cmd)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("no-browser") {
+				noBrowser = auth.NoBrowser()
+			}
+			tok, err := auth.Login(credPath, store, noBrowser)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Signed in. Token saved to the %s\n", store.Name())
+			if tok.RefreshToken == "" {
+				fmt.Println("Warning: no refresh token was issued; you will have to sign in again when the access token expires.")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the URL and paste the redirected address back (SSH, containers); default when over SSH without a display")
+	return cmd
+}
+
+func newAuthStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether a token is saved and when it expires",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := loadTokenStore(cmd)
+			if err != nil {
+				return err
+			}
+			tok, err := store.Load()
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Println("Not signed in. Run `justdoit auth login`.")
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read token from %s: %w", store.Name(), err)
+			}
+			fmt.Printf("Token store: %s\n", store.Name())
+			fmt.Printf("Access token: %s\n", describeExpiry(tok.Expiry, time.Now()))
+			if tok.RefreshToken != "" {
+				fmt.Println("Refresh token: present")
+			} else {
+				fmt.Println("Refresh token: missing (sign in again once the access token expires)")
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newAuthLogoutCmd() *cobra.Command {
+	var keepRemote bool
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Revoke the saved token and delete it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := loadTokenStore(cmd)
+			if err != nil {
+				return err
+			}
+			tok, loadErr := store.Load()
+			if errors.Is(loadErr, os.ErrNotExist) {
+				fmt.Println("Not signed in.")
+				return nil
+			}
+			// An unreadable token cannot be revoked, but is still removed.
+			revoke := loadErr == nil && !keepRemote
+			var revokeErr error
+			if revoke {
+				revokeErr = auth.Revoke(context.Background(), tok)
+			}
+			if err := store.Delete(); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted token from the %s\n", store.Name())
+			if revokeErr != nil {
+				return fmt.Errorf("token deleted locally, but revoking it failed (remove access at https://myaccount.google.com/permissions): %w", revokeErr)
+			}
+			if revoke {
+				fmt.Println("Access revoked.")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&keepRemote, "keep-remote", false, "Only delete the local token; do not revoke it with Google")
+	return cmd
+}
+
+func newAuthRefreshCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Exchange the refresh token for a new access token now",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			credPath, store, err := loadTokenStore(cmd)
+			if err != nil {
+				return err
+			}
+			tok, err := auth.Refresh(context.Background(), credPath, store)
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("not signed in; run `justdoit auth login`")
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Refreshed. Access token %s\n", describeExpiry(tok.Expiry, time.Now()))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newAuthMigrateCmd() *cobra.Command {
+	var to string
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move token.json into the Secret Service or the kernel keyring",
+		Long: "Copy the plaintext token.json into a secure store, delete the file once the store returns\n" +
+			"the same token, and set token_store so later runs use it. A store that already holds a\n" +
+			"different token is left alone, and so is the file.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to = strings.ToLower(strings.TrimSpace(to))
+			tokenPath, err := paths.TokenPath()
+			if err != nil {
+				return err
+			}
+			store, err := auth.OpenSecureStore(to, tokenPath)
+			if err != nil {
+				return err
+			}
+			moved, err := auth.MigrateFileToken(tokenPath, store)
+			if errors.Is(err, auth.ErrStoreHasToken) {
+				return fmt.Errorf("%w; delete %s if it is stale, or remove the stored token first", err, tokenPath)
+			}
+			if err != nil {
+				return err
+			}
+			if !moved {
+				return fmt.Errorf("no token file at %s", tokenPath)
+			}
+			fmt.Printf("Moved the token from %s to the %s\n", tokenPath, store.Name())
+
+			// Saved without overrides, so --set values stay out of the file.
+			cfgPath, err := resolveConfigPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadOrCreate(cfgPath)
+			if err != nil {
+				return err
+			}
+			if cfg.TokenStore != to {
+				cfg.TokenStore = to
+				if err := config.Save(cfgPath, cfg); err != nil {
+					return fmt.Errorf("token moved, but setting token_store failed: %w", err)
+				}
+				fmt.Printf("Set token_store to %s in %s\n", to, cfgPath)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", auth.StoreSecretService, "Target store: secret-service or keyring")
+	return cmd
+}
+
+// loadTokenStore is openTokenStore for commands that skip initApp. The
+// config is layered like initApp's, env and flag overrides included.
+func loadTokenStore(cmd *cobra.Command) (string, auth.TokenStore, error) {
+	cfgPath, err := resolveConfigPath(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+	cfg, err := config.LoadOrCreate(cfgPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := config.ApplyOverrides(cfg, configOverrides(cmd)); err != nil {
+		return "", nil, err
+	}
+	return openTokenStore(cmd, cfg)
+}
+
+// openTokenStore returns the credentials path and the token store chosen by
+// the token_store setting.
+func openTokenStore(cmd *cobra.Command, cfg *config.Config) (string, auth.TokenStore, error) {
+	credPath, _ := cmd.Flags().GetString("credentials")
+	if credPath == "" {
+		var err error
+		credPath, err = paths.CredentialsPath()
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	tokenPath, err := paths.TokenPath()
+	if err != nil {
+		return "", nil, err
+	}
+	store, err := auth.OpenTokenStore(cfg.TokenStore, tokenPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return credPath, store, nil
+}
+
+func describeExpiry(expiry, now time.Time) string {
+	if expiry.IsZero() {
+		return "no expiry recorded"
+	}
+	local := expiry.Local().Format("2006-01-02 15:04")
+	if !expiry.After(now) {
+		return fmt.Sprintf("expired %s (refreshed on next use)", local)
+	}
+	return fmt.Sprintf("valid until %s (%s left)", local, expiry.Sub(now).Round(time.Minute))
+}
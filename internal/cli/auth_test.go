@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribeExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	if got := describeExpiry(time.Time{}, now); got != "no expiry recorded" {
+		t.Fatalf("zero expiry: %q", got)
+	}
+	if got := describeExpiry(now.Add(-time.Minute), now); !strings.HasPrefix(got, "expired ") {
+		t.Fatalf("past expiry: %q", got)
+	}
+	if got := describeExpiry(now.Add(45*time.Minute), now); !strings.Contains(got, "45m0s left") {
+		t.Fatalf("future expiry: %q", got)
+	}
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+const defaultBulkConcurrency = 4
+
+type bulkOptions struct {
+	Query       string
+	Stdin       bool
+	DryRun      bool
+	Concurrency int
+}
+
+type bulkTarget struct {
+	ListName string
+	ListID   string
+	ID       string
+	Title    string
+	Section  string
+}
+
+// bulkFunc applies an operation to one task. The returned lines are printed
+// after the summary (for example tasks unblocked by a completion).
+type bulkFunc func(target bulkTarget) ([]string, error)
+
+func addBulkFlags(cmd *cobra.Command, opts *bulkOptions) {
+	cmd.Flags().StringVar(&opts.Query, "query", "", "Select tasks with a query, e.g. 'list:Inbox section:Old +errand text'")
+	cmd.Flags().BoolVar(&opts.Stdin, "stdin", false, "Read task IDs or #handles from stdin (one per line)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show the tasks that would change without changing them")
+	cmd.Flags().IntVar(&opts.Concurrency, "concurrency", defaultBulkConcurrency, "Number of tasks processed in parallel")
+}
+
+// isBulk reports whether a command should use bulk output instead of its
+// single-task messages.
+func (o bulkOptions) isBulk(targets []bulkTarget) bool {
+	return len(targets) > 1 || o.Query != "" || o.Stdin || o.DryRun
+}
+
+func (o bulkOptions) hasSource(args []string) bool {
+	return len(args) > 0 || strings.TrimSpace(o.Query) != "" || o.Stdin
+}
+
+func (t bulkTarget) label() string {
+	title := t.Title
+	if title == "" {
+		title = t.ID
+	}
+	where := []string{}
+	if t.ListName != "" {
+		where = append(where, t.ListName)
+	}
+	if t.Section != "" && t.Section != "General" {
+		where = append(where, t.Section)
+	}
+	if len(where) > 0 {
+		title += " (" + strings.Join(where, " / ") + ")"
+	}
+	return title + " [id: " + t.ID + "]"
+}
+
+// collectTargets gathers the tasks a command applies to: positional IDs or
+// handles (comma-separated values allowed), IDs read from stdin and query
+// matches. IDs are looked up in listName, or in every list when it is empty.
+func collectTargets(app *App, listName string, ids []string, opts bulkOptions) ([]bulkTarget, error) {
+	if opts.Stdin {
+		read, err := readTaskIDs(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, read...)
+	}
+	targets := []bulkTarget{}
+	seen := map[string]bool{}
+	add := func(target bulkTarget) {
+		key := target.ListID + "/" + target.ID
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		targets = append(targets, target)
+	}
+	ids = splitTaskIDs(ids)
+	for _, id := range ids {
+		target, err := resolveTaskRef(app, listName, id)
+		if err != nil {
+			return nil, err
+		}
+		add(target)
+	}
+	if strings.TrimSpace(opts.Query) != "" {
+		filter, err := parseTaskQuery(opts.Query, app.Config.Lists)
+		if err != nil {
+			return nil, err
+		}
+		if filter.List == "" && listName != "" {
+			filter.List = listName
+		}
+		results, err := filterTasks(newQueryContext(app), filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range results {
+			add(bulkTarget{ListName: item.ListName, ListID: item.ListID, ID: item.ID, Title: item.TitleVal, Section: item.Section})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no tasks matched")
+	}
+	return targets, nil
+}
+
+// parseTaskQuery understands list:<name>, section:<name> and +tag filters;
+// the remaining words must appear in the title, notes or section.
+func parseTaskQuery(query string, lists map[string]string) (taskFilter, error) {
+	filter := taskFilter{}
+	words := []string{}
+	for _, token := range splitQuickCapture(query) {
+		lower := strings.ToLower(token)
+		switch {
+		case strings.HasPrefix(lower, "list:"):
+			name := strings.TrimSpace(token[len("list:"):])
+			for listName := range lists {
+				if strings.EqualFold(listName, name) {
+					name = listName
+					break
+				}
+			}
+			if _, ok := lists[name]; !ok {
+				return taskFilter{}, fmt.Errorf("list not mapped: %s", name)
+			}
+			filter.List = name
+		case strings.HasPrefix(lower, "section:"):
+			filter.Section = strings.TrimSpace(token[len("section:"):])
+		default:
+			words = append(words, token)
+		}
+	}
+	filter.Text, filter.Tags = splitSearchQuery(strings.Join(words, " "))
+	return filter, nil
+}
+
+func splitTaskIDs(values []string) []string {
+	ids := []string{}
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// readTaskIDs reads one ID or handle per line. Lines copied from `list`,
+// `next` or `search` output are accepted too; blank lines and # comments are
+// skipped.
+func readTaskIDs(r io.Reader) ([]string, error) {
+	ids := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "- "))
+		if line == "" || (strings.HasPrefix(line, "#") && !isHandle(strings.Fields(line)[0])) {
+			continue
+		}
+		if _, rest, ok := strings.Cut(line, "[id: "); ok {
+			if id, _, ok := strings.Cut(rest, "]"); ok {
+				ids = append(ids, strings.TrimSpace(id))
+			}
+			continue
+		}
+		ids = append(ids, strings.Fields(line)[0])
+	}
+	return ids, scanner.Err()
+}
+
+func listNameForID(app *App, listID string) string {
+	for name, id := range app.Config.Lists {
+		if id == listID {
+			return name
+		}
+	}
+	return ""
+}
+
+// applyBulk runs fn over targets with at most concurrency calls in flight.
+// progress, when set, is called after each task finishes.
+func applyBulk(targets []bulkTarget, concurrency int, fn bulkFunc, progress func(done, total int)) ([][]string, []error) {
+	notes := make([][]string, len(targets))
+	errs := make([]error, len(targets))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+	jobs := make(chan int)
+	finished := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for i := range jobs {
+				notes[i], errs[i] = fn(targets[i])
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range targets {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	for done := 1; done <= len(targets); done++ {
+		<-finished
+		if progress != nil {
+			progress(done, len(targets))
+		}
+	}
+	return notes, errs
+}
+
+// runBulk previews (with --dry-run) or applies fn to every target, showing
+// progress on a terminal and reporting failures per task. The whole run is
+// one history entry.
+func runBulk(app *App, targets []bulkTarget, opts bulkOptions, verb, past string, scope opScope, fn bulkFunc) error {
+	if opts.DryRun {
+		fillTargetTitles(app, targets)
+		fmt.Printf("Would %s %d task(s):\n", verb, len(targets))
+		for _, target := range targets {
+			fmt.Printf("- %s\n", target.label())
+		}
+		return nil
+	}
+	var progress func(done, total int)
+	if isatty.IsTerminal(os.Stderr.Fd()) {
+		progress = func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%s… %d/%d", verb, done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+	var (
+		notes [][]string
+		errs  []error
+	)
+	_ = recordOp(app, verb, scope, func() error {
+		notes, errs = applyBulk(targets, opts.Concurrency, fn, progress)
+		return nil
+	})
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", targets[i].label(), err)
+		}
+	}
+	fmt.Printf("✅ %d task(s) %s\n", len(targets)-failed, past)
+	for _, lines := range notes {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d task(s) failed", failed, len(targets))
+	}
+	return nil
+}
+
+// ensureTargetSections creates section in every list touched by targets.
+func ensureTargetSections(app *App, targets []bulkTarget, section string) error {
+	done := map[string]bool{}
+	for _, target := range targets {
+		if done[target.ListID] {
+			continue
+		}
+		done[target.ListID] = true
+		if _, err := ensureSectionTask(app, target.ListID, section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fillTargetTitles(app *App, targets []bulkTarget) {
+	for i := range targets {
+		if targets[i].Title != "" {
+			continue
+		}
+		if task, err := app.Tasks.GetTask(targets[i].ListID, targets[i].ID); err == nil && task != nil {
+			targets[i].Title = task.Title
+		}
+	}
+}
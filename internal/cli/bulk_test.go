@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestReadTaskIDs(t *testing.T) {
+	input := strings.Join([]string{
+		"# pasted from list --ids",
+		"abc",
+		"",
+		"- Buy milk [id: def]",
+		"ghi trailing words",
+		"- #42 Water plants",
+		"#7",
+	}, "\n")
+	ids, err := readTaskIDs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readTaskIDs error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"abc", "def", "ghi", "#42", "#7"}) {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+	if got := splitTaskIDs([]string{"a,b", " c ", "a,,"}); !reflect.DeepEqual(got, []string{"a", "b", "c", "a"}) {
+		t.Fatalf("unexpected split %v", got)
+	}
+}
+
+func TestParseTaskQuery(t *testing.T) {
+	lists := map[string]string{"Inbox": "list-1"}
+	filter, err := parseTaskQuery(`list:inbox section:"Old stuff" +errand milk`, lists)
+	if err != nil {
+		t.Fatalf("parseTaskQuery error: %v", err)
+	}
+	if filter.List != "Inbox" || filter.Section != "Old stuff" || filter.Text != "milk" || !reflect.DeepEqual(filter.Tags, []string{"errand"}) {
+		t.Fatalf("unexpected filter %#v", filter)
+	}
+	if _, err := parseTaskQuery("list:Nope", lists); err == nil {
+		t.Fatalf("expected unknown list error")
+	}
+}
+
+func TestApplyBulkCollectsErrorsPerTask(t *testing.T) {
+	targets := []bulkTarget{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	var calls, progress int32
+	_, errs := applyBulk(targets, 2, func(target bulkTarget) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		if target.ID == "c" {
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	}, func(done, total int) {
+		atomic.AddInt32(&progress, 1)
+	})
+	if calls != 4 || progress != 4 {
+		t.Fatalf("expected 4 calls and progress updates, got %d/%d", calls, progress)
+	}
+	for i, err := range errs {
+		if (err != nil) != (targets[i].ID == "c") {
+			t.Fatalf("unexpected error for %s: %v", targets[i].ID, err)
+		}
+	}
+	status, err := bulkStatus("completed", errs)
+	if err != nil || !strings.Contains(status, "3 task(s) completed") || !strings.Contains(status, "1 failed") {
+		t.Fatalf("unexpected status %q (%v)", status, err)
+	}
+}
+
+func TestFilterTasksBySection(t *testing.T) {
+	items := []*tasks.Task{
+		{Id: "s1", Title: "Old", Notes: sectionMarker.Set("", true)},
+		{Id: "t1", Title: "Stale idea", Notes: setNotesSection("", "s1")},
+		{Id: "t2", Title: "Legacy idea", Parent: "s1"},
+		{Id: "t3", Title: "Fresh idea"},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Inbox": "list-1"},
+		Location: time.UTC,
+	}
+	results, err := filterTasks(ctx, taskFilter{List: "Inbox", Section: "old"})
+	if err != nil {
+		t.Fatalf("filterTasks error: %v", err)
+	}
+	got := []string{}
+	for _, item := range results {
+		got = append(got, item.ID)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"t1", "t2"}) {
+		t.Fatalf("unexpected matches %v", got)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -19,9 +21,12 @@ func newConfigCmd() *cobra.Command {
 	}
 	cmd.AddCommand(newConfigInitCmd())
 	cmd.AddCommand(newConfigShowCmd())
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigSetCmd())
 	cmd.AddCommand(newConfigCalendarsCmd())
 	cmd.AddCommand(newConfigCalendarSetCmd())
 	cmd.AddCommand(newConfigListsCmd())
+	cmd.AddCommand(newConfigTagColorCmd())
 	return cmd
 }
 
@@ -113,6 +118,95 @@ func newConfigCalendarsCmd() *cobra.Command {
 	return cmd
 }
 
+func newConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a config value, including env and flag overrides",
+		Long: "Print a config value as this run sees it: config.json, then JUSTDOIT_* variables, then flags.\n" +
+			"Without a key, print every key with its value and where it comes from.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveConfigPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadOrCreate(path)
+			if err != nil {
+				return err
+			}
+			applied, err := config.ApplyOverrides(cfg, configOverrides(cmd))
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				value, err := config.Get(cfg, args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Println(value)
+				return nil
+			}
+			sources := map[string]string{}
+			for _, override := range applied {
+				sources[override.Key] = override.Source
+			}
+			for _, key := range config.Keys(cfg) {
+				value, _ := config.Get(cfg, key)
+				source := sources[key]
+				if source == "" {
+					source = "config"
+				}
+				fmt.Printf("%s=%s %s\n", key, value, gray("("+source+")"))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> [value]",
+		Short: "Set a config value in config.json",
+		Long: "Set a config value in config.json. Keys are the config.json fields, lists.<name> and tag_colors.<tag>.\n" +
+			"An empty or missing value resets a field to its default and removes a list or tag color.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveConfigPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadOrCreate(path)
+			if err != nil {
+				return err
+			}
+			value := ""
+			if len(args) == 2 {
+				value = args[1]
+			}
+			if err := config.Set(cfg, args[0], value); err != nil {
+				return err
+			}
+			if err := config.Save(path, cfg); err != nil {
+				return err
+			}
+			current, err := config.Get(cfg, args[0])
+			if err != nil {
+				fmt.Printf("%s removed\n", args[0])
+			} else {
+				fmt.Printf("%s=%s\n", args[0], current)
+			}
+			for _, override := range configOverrides(cmd) {
+				if override.Key == args[0] {
+					fmt.Printf("Note: %s overrides this value.\n", override.Source)
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
 func newConfigCalendarSetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set-calendar [calendarID]",
@@ -138,6 +232,69 @@ func newConfigCalendarSetCmd() *cobra.Command {
 	return cmd
 }
 
+func newConfigTagColorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag-color [tag] [colorId|none]",
+		Short: "Map a tag to a calendar event color (1-11)",
+		Args:  cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveConfigPath(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadOrCreate(path)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				if len(cfg.TagColors) == 0 {
+					fmt.Println("(no tag colors)")
+					return nil
+				}
+				tags := make([]string, 0, len(cfg.TagColors))
+				for tag := range cfg.TagColors {
+					tags = append(tags, tag)
+				}
+				sort.Strings(tags)
+				for _, tag := range tags {
+					fmt.Printf("+%s -> %s\n", tag, cfg.TagColors[tag])
+				}
+				return nil
+			}
+			tag, ok := normalizeTag(args[0])
+			if !ok {
+				return fmt.Errorf("invalid tag: %s", args[0])
+			}
+			if len(args) == 1 {
+				if color, ok := cfg.TagColors[tag]; ok {
+					fmt.Printf("+%s -> %s\n", tag, color)
+				} else {
+					fmt.Printf("+%s has no color\n", tag)
+				}
+				return nil
+			}
+			color := strings.TrimSpace(args[1])
+			if strings.EqualFold(color, "none") {
+				delete(cfg.TagColors, tag)
+			} else {
+				if n, err := strconv.Atoi(color); err != nil || n < 1 || n > 11 {
+					return fmt.Errorf("invalid color: %s (use 1-11)", color)
+				}
+				if cfg.TagColors == nil {
+					cfg.TagColors = map[string]string{}
+				}
+				cfg.TagColors[tag] = color
+			}
+			if err := config.Save(path, cfg); err != nil {
+				return err
+			}
+			fmt.Printf("Tag color updated: +%s\n", tag)
+			return nil
+		},
+	}
+	return cmd
+}
+
 func newConfigListsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "lists",
@@ -148,6 +305,52 @@ func newConfigListsCmd() *cobra.Command {
 	cmd.AddCommand(newConfigListsRemoveCmd())
 	cmd.AddCommand(newConfigListsRemoteCmd())
 	cmd.AddCommand(newConfigListsCreateCmd())
+	cmd.AddCommand(newConfigListsSyncCmd())
+	return cmd
+}
+
+func newConfigListsSyncCmd() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Map new Google Tasks lists, follow renames and unmap deleted lists",
+		Long: "Map new Google Tasks lists by title, follow renames (the old name becomes an alias) and unmap deleted lists.\n" +
+			"Renames are detected from the Google title recorded at the previous sync; a list you named yourself keeps its name.\n" +
+			"With auto_lists set to true this also happens when the TUI opens or refreshes the week view.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			remote, err := app.Tasks.ListTaskLists()
+			if err != nil {
+				return err
+			}
+			result := syncListMappings(app.Config, remote, !dryRun)
+			if result.empty() {
+				fmt.Println("List mappings match Google Tasks.")
+				if result.changed() && !dryRun {
+					// Only the Google titles were recorded.
+					return app.SaveConfig()
+				}
+				return nil
+			}
+			for _, line := range result.lines() {
+				fmt.Println(line)
+			}
+			if dryRun {
+				return nil
+			}
+			for _, name := range result.Removed {
+				if name == app.Config.DefaultList {
+					fmt.Printf("The default list %q was deleted; pick another with: justdoit config set default_list <name>\n", name)
+				}
+			}
+			return app.SaveConfig()
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report the differences")
 	return cmd
 }
 
@@ -169,8 +372,17 @@ func newConfigListsListCmd() *cobra.Command {
 				keys = append(keys, k)
 			}
 			sort.Strings(keys)
+			aliases := map[string][]string{}
+			for alias, target := range cfg.ListAliases {
+				aliases[target] = append(aliases[target], alias)
+			}
 			for _, k := range keys {
-				fmt.Printf("- %s: %s\n", k, cfg.Lists[k])
+				suffix := ""
+				if names := aliases[k]; len(names) > 0 {
+					sort.Strings(names)
+					suffix = gray(" (aliases: " + strings.Join(names, ", ") + ")")
+				}
+				fmt.Printf("- %s: %s%s\n", k, cfg.Lists[k], suffix)
 			}
 			return nil
 		},
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"justdoit/internal/metadata"
+)
+
+// taskStartDate is the day a task becomes actionable (YYYY-MM-DD). Until then
+// it is deferred and hidden from next.
+var taskStartDate = metadata.String("justdoit_start")
+
+func notesStartDate(notes string, loc *time.Location) (time.Time, bool) {
+	raw, ok := taskStartDate.Get(notes)
+	if !ok || raw == "" {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// setNotesStartDate stores date as the start date; a zero date clears it.
+func setNotesStartDate(notes string, date time.Time) string {
+	if date.IsZero() {
+		return taskStartDate.Remove(notes)
+	}
+	return taskStartDate.Set(notes, date.Format("2006-01-02"))
+}
+
+// parseStartInput parses a --start value; "" and "none" mean no start date.
+func parseStartInput(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "none") {
+		return time.Time{}, nil
+	}
+	return parseDeferInput(value, now, loc)
+}
+
+// isDeferred reports whether notes hold a start date after today.
+func isDeferred(notes string, today time.Time) bool {
+	start, ok := notesStartDate(notes, today.Location())
+	return ok && start.After(today)
+}
+
+// buildDeferredItems lists open tasks whose start date is still ahead,
+// grouped by the day they start.
+func buildDeferredItems(ctx queryContext) ([]list.Item, error) {
+	if ctx.Tasks == nil {
+		return nil, fmt.Errorf("task client is not initialized")
+	}
+	if ctx.Location == nil {
+		ctx.Location = time.Local
+	}
+	if ctx.Now == nil {
+		ctx.Now = func() time.Time { return time.Now().In(ctx.Location) }
+	}
+	now := ctx.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ctx.Location)
+	listNames := sortedListNames(ctx.Lists)
+	if len(listNames) == 0 {
+		return nil, fmt.Errorf("no lists configured")
+	}
+	deferred := []taskItem{}
+	for _, listName := range listNames {
+		listID := ctx.Lists[listName]
+		items, err := ctx.Tasks.ListTasksWithOptions(listID, false, false, false, "")
+		if err != nil {
+			return nil, err
+		}
+		sections := buildSectionIndex(items)
+		for _, item := range items {
+			if item == nil || item.Status == "completed" || isSectionTask(item) || isSubtask(item, sections) {
+				continue
+			}
+			if !isDeferred(item.Notes, today) {
+				continue
+			}
+			deferred = append(deferred, newNextTaskItem(item, listName, listID, resolveSectionName(item, sections), ctx.Location))
+		}
+	}
+	return groupDeferredItems(deferred), nil
+}
+
+// groupDeferredItems sorts deferred tasks by start day, priority and title
+// under one header per day.
+func groupDeferredItems(deferred []taskItem) []list.Item {
+	sort.SliceStable(deferred, func(i, j int) bool {
+		if !deferred[i].Start.Equal(deferred[j].Start) {
+			return deferred[i].Start.Before(deferred[j].Start)
+		}
+		if pi, pj := priorityRank(deferred[i].Priority), priorityRank(deferred[j].Priority); pi != pj {
+			return pi < pj
+		}
+		return deferred[i].TitleVal < deferred[j].TitleVal
+	})
+	result := []list.Item{}
+	header := ""
+	for _, item := range deferred {
+		if day := "Starts " + item.Start.Format("Mon 2006-01-02"); day != header {
+			header = day
+			result = append(result, taskItem{TitleVal: header, IsHeader: true})
+		}
+		result = append(result, item)
+	}
+	if len(result) == 0 {
+		result = append(result, taskItem{TitleVal: "(no deferred tasks)", IsHeader: true})
+	}
+	return result
+}
@@ -4,46 +4,49 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"justdoit/internal/sync"
 )
 
 func newDeleteCmd() *cobra.Command {
 	var (
 		list      string
 		keepEvent bool
+		match     titleQuery
+		bulk      bulkOptions
 	)
 	cmd := &cobra.Command{
-		Use:   "delete [taskID]",
-		Short: "Delete a task (and linked calendar event)",
-		Args:  cobra.ExactArgs(1),
+		Use:   "delete [taskID...]",
+		Short: "Delete tasks (and linked calendar events)",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if !bulk.hasSource(args) && !match.isSet() {
+				return fmt.Errorf("requires [taskID...], --title, --query or --stdin")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			app, err := initApp(cmd)
 			if err != nil {
 				return err
 			}
-			listID, err := resolveListID(app, list, list != "")
-			if err != nil {
-				return err
+			var targets []bulkTarget
+			if bulk.hasSource(args) {
+				targets, err = collectTargets(app, list, args, bulk)
+			} else {
+				var target bulkTarget
+				target, err = resolveTaskByTitle(app, list, match, false)
+				targets = []bulkTarget{target}
 			}
-
-			taskID := args[0]
-			task, err := app.Tasks.GetTask(listID, taskID)
 			if err != nil {
 				return err
 			}
-
-			if !keepEvent {
-				if eventID, ok := sync.ExtractMetadata(task.Notes, sync.TaskEventIDKey); ok {
-					_ = app.Calendar.DeleteEvent(app.Config.CalendarID, eventID)
-				} else {
-					if event, err := app.Calendar.FindEventByTaskID(app.Config.CalendarID, taskID); err == nil {
-						_ = app.Calendar.DeleteEvent(app.Config.CalendarID, event.Id)
-					}
-				}
+			if bulk.isBulk(targets) {
+				return runBulk(app, targets, bulk, "delete", "deleted", targetScope(targets), func(target bulkTarget) ([]string, error) {
+					return nil, deleteTask(app, target.ListID, target.ID, !keepEvent)
+				})
 			}
 
-			if err := app.Tasks.DeleteTask(listID, taskID); err != nil {
+			if err := recordOp(app, "delete", targetScope(targets), func() error {
+				return deleteTask(app, targets[0].ListID, targets[0].ID, !keepEvent)
+			}); err != nil {
 				return err
 			}
 
@@ -55,8 +58,13 @@ func newDeleteCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json); found from the task ID when omitted")
 	cmd.Flags().BoolVar(&keepEvent, "keep-event", false, "Do not delete the linked calendar event")
+	cmd.Flags().StringVar(&match.Text, "title", "", "Delete a task by title, fuzzy matched across lists (alternative to taskID)")
+	cmd.Flags().StringVar(&match.Section, "section", "", "Only match tasks in this section when using --title")
+	cmd.Flags().BoolVar(&match.First, "first", false, "With --title, take the best match instead of asking")
+	cmd.Flags().BoolVar(&match.Strict, "strict", false, "With --title, only accept an exact title and never ask")
+	addBulkFlags(cmd, &bulk)
 
 	return cmd
 }
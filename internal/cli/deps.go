@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	stdsync "sync"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/metadata"
+)
+
+// taskBlockedBy lists the IDs of the tasks that must be completed first. IDs
+// may point to tasks in any configured list.
+var taskBlockedBy = metadata.List("justdoit_blocked_by")
+
+type graphNode struct {
+	ListName string
+	ListID   string
+	Task     *tasks.Task
+}
+
+type taskGraph map[string]graphNode
+
+func newBlockCmd() *cobra.Command {
+	var (
+		on     []string
+		remove bool
+	)
+	cmd := &cobra.Command{
+		Use:   "block [taskID] --on [taskID]",
+		Short: "Mark a task as blocked by other tasks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(on) == 0 {
+				return fmt.Errorf("--on is required")
+			}
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			graph, err := loadTaskGraph(app.Tasks, app.Config.Lists)
+			if err != nil {
+				return err
+			}
+			ref, err := resolveTaskRef(app, "", args[0])
+			if err != nil {
+				return err
+			}
+			node, ok := graph[ref.ID]
+			if !ok {
+				return fmt.Errorf("task not found in configured lists: %s", args[0])
+			}
+			task := node.Task
+			for _, blockerRef := range on {
+				blockerID := blockerRef
+				if resolved, err := resolveTaskRef(app, "", blockerRef); err == nil {
+					blockerID = resolved.ID
+				} else if !remove {
+					return err
+				}
+				blocker, ok := graph[blockerID]
+				if !ok && !remove {
+					return fmt.Errorf("task not found in configured lists: %s", blockerID)
+				}
+				if remove {
+					task.Notes = setNotesBlockers(task.Notes, nil, []string{blockerID})
+					continue
+				}
+				if graph.wouldCycle(task.Id, blockerID) {
+					return fmt.Errorf("cannot block %q on %q: dependency cycle", task.Title, blocker.Task.Title)
+				}
+				task.Notes = setNotesBlockers(task.Notes, []string{blockerID}, nil)
+			}
+			if err := recordOp(app, "block", taskScope(node.ListID, task.Id), func() error {
+				_, err := app.Tasks.UpdateTask(node.ListID, task)
+				return err
+			}); err != nil {
+				return err
+			}
+			if remove {
+				fmt.Println("🔓 Dependency removed")
+			} else {
+				fmt.Println("⛔ Task blocked")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&on, "on", nil, "Blocking task ID or #handle (repeatable)")
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove the dependency instead of adding it")
+	return cmd
+}
+
+func newDepsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps [taskID]",
+		Short: "Show what a task is blocked by and what it blocks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			graph, err := loadTaskGraph(app.Tasks, app.Config.Lists)
+			if err != nil {
+				return err
+			}
+			ref, err := resolveTaskRef(app, "", args[0])
+			if err != nil {
+				return err
+			}
+			node, ok := graph[ref.ID]
+			if !ok {
+				return fmt.Errorf("task not found in configured lists: %s", args[0])
+			}
+			status := "ready"
+			if graph.isBlocked(node.Task) {
+				status = "blocked"
+			}
+			fmt.Printf("%s (%s) [%s]\n", node.Task.Title, node.ListName, status)
+			fmt.Println("\nBlocked by")
+			if len(notesBlockers(node.Task.Notes)) == 0 {
+				fmt.Println("- (nothing)")
+			} else {
+				graph.printBlockers(node.Task.Id, 0, map[string]bool{node.Task.Id: true})
+			}
+			fmt.Println("\nBlocking")
+			dependents := graph.dependents(node.Task.Id)
+			if len(dependents) == 0 {
+				fmt.Println("- (nothing)")
+			}
+			for _, dep := range dependents {
+				fmt.Printf("- %s (%s) [id: %s]\n", dep.Task.Title, dep.ListName, dep.Task.Id)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func notesBlockers(notes string) []string {
+	ids, _ := taskBlockedBy.Get(notes)
+	return ids
+}
+
+func setNotesBlockers(notes string, add, remove []string) string {
+	drop := map[string]bool{}
+	for _, id := range remove {
+		drop[id] = true
+	}
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, id := range append(notesBlockers(notes), add...) {
+		if drop[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return taskBlockedBy.Remove(notes)
+	}
+	return taskBlockedBy.Set(notes, ids)
+}
+
+func loadTaskGraph(provider TaskProvider, lists map[string]string) (taskGraph, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("task client is not initialized")
+	}
+	graph := taskGraph{}
+	for _, listName := range sortedListNames(lists) {
+		listID := lists[listName]
+		items, err := provider.ListTasksWithOptions(listID, true, true, false, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item == nil || item.Id == "" || isSectionTask(item) {
+				continue
+			}
+			graph[item.Id] = graphNode{ListName: listName, ListID: listID, Task: item}
+		}
+	}
+	return graph, nil
+}
+
+// isOpen reports whether id refers to a known task that is still pending.
+// Unknown (deleted or unmapped) tasks never block.
+func (g taskGraph) isOpen(id string) bool {
+	node, ok := g[id]
+	return ok && !strings.EqualFold(node.Task.Status, "completed") && !node.Task.Deleted
+}
+
+func (g taskGraph) isBlocked(task *tasks.Task) bool {
+	if task == nil {
+		return false
+	}
+	for _, id := range notesBlockers(task.Notes) {
+		if g.isOpen(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// wouldCycle reports whether making taskID depend on blockerID closes a loop.
+func (g taskGraph) wouldCycle(taskID, blockerID string) bool {
+	if taskID == blockerID {
+		return true
+	}
+	visited := map[string]bool{}
+	stack := []string{blockerID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == taskID {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		if node, ok := g[id]; ok {
+			stack = append(stack, notesBlockers(node.Task.Notes)...)
+		}
+	}
+	return false
+}
+
+func (g taskGraph) dependents(id string) []graphNode {
+	result := []graphNode{}
+	for _, node := range g {
+		for _, blocker := range notesBlockers(node.Task.Notes) {
+			if blocker == id {
+				result = append(result, node)
+				break
+			}
+		}
+	}
+	sortGraphNodes(result)
+	return result
+}
+
+// unblockedBy returns the pending tasks that were waiting on id and have no
+// other open blockers left.
+func (g taskGraph) unblockedBy(id string) []graphNode {
+	result := []graphNode{}
+	for _, node := range g.dependents(id) {
+		if strings.EqualFold(node.Task.Status, "completed") {
+			continue
+		}
+		if !g.isBlocked(node.Task) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// unblockTracker reports which tasks a completion unblocked. It loads the
+// dependency graph once, on first use, so a bulk run pays for it once; bulk
+// workers share it, so it is safe for concurrent use. The report is
+// best-effort: the completion already happened when it runs.
+type unblockTracker struct {
+	load  func() (taskGraph, error)
+	once  stdsync.Once
+	mu    stdsync.Mutex
+	graph taskGraph
+}
+
+func newUnblockTracker(app *App) *unblockTracker {
+	return &unblockTracker{load: func() (taskGraph, error) {
+		return loadTaskGraph(app.Tasks, app.Config.Lists)
+	}}
+}
+
+func (u *unblockTracker) completed(taskID string) []graphNode {
+	u.once.Do(func() {
+		graph, err := u.load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not check for unblocked tasks: %v\n", err)
+			return
+		}
+		u.graph = graph
+	})
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.graph == nil {
+		return nil
+	}
+	if node, ok := u.graph[taskID]; ok {
+		done := *node.Task
+		done.Status = "completed"
+		node.Task = &done
+		u.graph[taskID] = node
+	}
+	return u.graph.unblockedBy(taskID)
+}
+
+func (g taskGraph) printBlockers(id string, depth int, seen map[string]bool) {
+	node, ok := g[id]
+	if !ok {
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	for _, blockerID := range notesBlockers(node.Task.Notes) {
+		blocker, ok := g[blockerID]
+		if !ok {
+			fmt.Printf("%s- (missing task) [id: %s]\n", indent, blockerID)
+			continue
+		}
+		mark := "⏳"
+		if !g.isOpen(blockerID) {
+			mark = "✅"
+		}
+		fmt.Printf("%s- %s %s (%s) [id: %s]\n", indent, mark, blocker.Task.Title, blocker.ListName, blockerID)
+		if seen[blockerID] {
+			continue
+		}
+		seen[blockerID] = true
+		g.printBlockers(blockerID, depth+1, seen)
+	}
+}
+
+func sortGraphNodes(nodes []graphNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].ListName != nodes[j].ListName {
+			return nodes[i].ListName < nodes[j].ListName
+		}
+		return nodes[i].Task.Title < nodes[j].Task.Title
+	})
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestTaskGraphCyclesAndUnblocking(t *testing.T) {
+	provider := fakeTaskProvider{lists: map[string][]*tasks.Task{
+		"work": {
+			{Id: "a", Title: "Design", Status: "completed"},
+			{Id: "b", Title: "Build", Status: "needsAction", Notes: setNotesBlockers("", []string{"a"}, nil)},
+		},
+		"home": {
+			{Id: "c", Title: "Ship", Status: "needsAction", Notes: setNotesBlockers("", []string{"a", "b"}, nil)},
+		},
+	}}
+	graph, err := loadTaskGraph(provider, map[string]string{"Work": "work", "Home": "home"})
+	if err != nil {
+		t.Fatalf("loadTaskGraph error: %v", err)
+	}
+	if !graph.wouldCycle("a", "c") {
+		t.Fatalf("expected a -> c to close a cycle through b")
+	}
+	if graph.wouldCycle("c", "a") {
+		t.Fatalf("did not expect a cycle for c -> a")
+	}
+	if !graph.wouldCycle("a", "a") {
+		t.Fatalf("expected self dependency to be a cycle")
+	}
+
+	unblocked := graph.unblockedBy("a")
+	if len(unblocked) != 1 || unblocked[0].Task.Id != "b" {
+		t.Fatalf("expected only b to be unblocked, got %#v", unblocked)
+	}
+	if !graph.isBlocked(graph["c"].Task) {
+		t.Fatalf("expected c to still be blocked by b")
+	}
+}
+
+func TestBuildNextItemsFlagsBlockedTasks(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, loc)
+	due := time.Date(2026, 1, 3, 18, 0, 0, 0, loc).Format(time.RFC3339)
+	items := []*tasks.Task{
+		{Id: "1", Title: "Blocked", Status: "needsAction", Due: due, Notes: setNotesBlockers("", []string{"2"}, nil)},
+		{Id: "2", Title: "Blocker", Status: "needsAction", Due: due},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Work": "list-1"},
+		Location: loc,
+		Now:      func() time.Time { return now },
+	}
+
+	result, err := buildNextItems(ctx, false)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	var order []taskItem
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && !task.IsHeader {
+			order = append(order, task)
+		}
+	}
+	if len(order) != 2 || order[0].ID != "2" || !order[1].Blocked {
+		t.Fatalf("expected blocked task last and flagged, got %#v", order)
+	}
+
+	ctx.HideBlocked = true
+	result, err = buildNextItems(ctx, false)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && task.ID == "1" {
+			t.Fatalf("expected blocked task to be hidden")
+		}
+	}
+}
+
+func TestUnblockTrackerLoadsOnceAndIsBestEffort(t *testing.T) {
+	provider := fakeTaskProvider{lists: map[string][]*tasks.Task{
+		"work": {
+			{Id: "a", Title: "Design", Status: "needsAction"},
+			{Id: "b", Title: "Build", Status: "needsAction"},
+			{Id: "c", Title: "Ship", Status: "needsAction", Notes: setNotesBlockers("", []string{"a", "b"}, nil)},
+		},
+	}}
+	loads := 0
+	tracker := &unblockTracker{load: func() (taskGraph, error) {
+		loads++
+		return loadTaskGraph(provider, map[string]string{"Work": "work"})
+	}}
+	if unblocked := tracker.completed("a"); len(unblocked) != 0 {
+		t.Fatalf("c is still blocked by b, got %#v", unblocked)
+	}
+	if unblocked := tracker.completed("b"); len(unblocked) != 1 || unblocked[0].Task.Id != "c" {
+		t.Fatalf("expected c to be unblocked, got %#v", unblocked)
+	}
+	if loads != 1 {
+		t.Fatalf("graph loaded %d times, want once", loads)
+	}
+
+	failing := &unblockTracker{load: func() (taskGraph, error) {
+		return nil, errors.New("quota exceeded")
+	}}
+	if unblocked := failing.completed("a"); unblocked != nil {
+		t.Fatalf("expected no report when the graph cannot be loaded, got %#v", unblocked)
+	}
+}
+
+func TestUnblockTrackerIsSafeForBulkWorkers(t *testing.T) {
+	items := []*tasks.Task{}
+	blockers := []string{}
+	targets := []bulkTarget{}
+	for i := 0; i < 16; i++ {
+		id := fmt.Sprintf("t%d", i)
+		items = append(items, &tasks.Task{Id: id, Title: id, Status: "needsAction"})
+		blockers = append(blockers, id)
+		targets = append(targets, bulkTarget{ListID: "work", ID: id})
+	}
+	items = append(items, &tasks.Task{Id: "last", Title: "Ship", Status: "needsAction", Notes: setNotesBlockers("", blockers, nil)})
+	provider := fakeTaskProvider{lists: map[string][]*tasks.Task{"work": items}}
+	var loads atomic.Int32
+	tracker := &unblockTracker{load: func() (taskGraph, error) {
+		loads.Add(1)
+		return loadTaskGraph(provider, map[string]string{"Work": "work"})
+	}}
+
+	notes, _ := applyBulk(targets, 4, func(target bulkTarget) ([]string, error) {
+		lines := []string{}
+		for _, node := range tracker.completed(target.ID) {
+			lines = append(lines, node.Task.Id)
+		}
+		return lines, nil
+	}, nil)
+	reports := 0
+	for _, lines := range notes {
+		reports += len(lines)
+	}
+	if reports != 1 {
+		t.Fatalf("expected the last completion alone to unblock the task, got %v", notes)
+	}
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("graph loaded %d times, want once", n)
+	}
+}
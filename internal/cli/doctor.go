@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/auth"
+	"justdoit/internal/config"
+	"justdoit/internal/sync"
+)
+
+func newDoctorCmd() *cobra.Command {
+	var fix bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check config, sign-in, lists, calendars and task/event links",
+		Long: "Check config, sign-in, lists, calendars and task/event links.\n" +
+			"With --fix, unmap deleted lists, drop deleted view calendars and unlink tasks from deleted events.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := &doctorReport{fix: fix}
+			runDoctor(cmd, report)
+			return report.result()
+		},
+	}
+	cmd.Flags().BoolVar(&fix, "fix", false, "Repair the problems that can be repaired")
+	return cmd
+}
+
+// doctorReport prints check results and counts what is still wrong.
+type doctorReport struct {
+	fix      bool
+	failures int
+	fixable  int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Printf("✓ %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(format string, args ...any) {
+	fmt.Printf("! %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+	r.failures++
+	fmt.Printf("✗ %s\n", fmt.Sprintf(format, args...))
+}
+
+// problem reports something --fix can repair; it returns whether to repair it now.
+func (r *doctorReport) problem(format string, args ...any) bool {
+	if r.fix {
+		fmt.Printf("✗ %s (fixing)\n", fmt.Sprintf(format, args...))
+		return true
+	}
+	r.fixable++
+	r.fail(format, args...)
+	return false
+}
+
+func (r *doctorReport) result() error {
+	if r.failures == 0 {
+		fmt.Println("\nNo problems found.")
+		return nil
+	}
+	if r.fixable > 0 {
+		fmt.Printf("\n%d of these can be repaired with: justdoit doctor --fix\n", r.fixable)
+	}
+	return fmt.Errorf("doctor found %d problem(s)", r.failures)
+}
+
+func runDoctor(cmd *cobra.Command, r *doctorReport) {
+	cfg, ok := doctorConfig(cmd, r)
+	if !ok {
+		return
+	}
+
+	credPath, store, err := openTokenStore(cmd, cfg)
+	if err != nil {
+		r.fail("token store: %v", err)
+		return
+	}
+	tok, granted, err := auth.Verify(context.Background(), credPath, store)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.fail("not signed in; run: justdoit auth login")
+		return
+	case err != nil:
+		r.fail("sign-in: %v", err)
+		return
+	}
+	r.ok("signed in (%s, access token %s)", store.Name(), describeExpiry(tok.Expiry, time.Now()))
+	if missing := auth.MissingScopes(granted); len(missing) > 0 {
+		r.fail("token lacks scopes %s; run: justdoit auth login", strings.Join(missing, ", "))
+		return
+	}
+	r.ok("token has the Tasks and Calendar scopes")
+
+	app, err := initApp(cmd)
+	if err != nil {
+		r.fail("connect: %v", err)
+		return
+	}
+	changed := doctorLists(app, r)
+	changed = doctorCalendars(app, r) || changed
+	if changed {
+		if err := app.SaveConfig(); err != nil {
+			r.fail("save config: %v", err)
+		} else {
+			r.ok("config updated")
+		}
+	}
+	doctorEventLinks(app, r)
+}
+
+// doctorConfig checks config.json as commands see it, with env and flag
+// overrides applied; it reports false when the checks cannot go on.
+func doctorConfig(cmd *cobra.Command, r *doctorReport) (*config.Config, bool) {
+	cfgPath, err := resolveConfigPath(cmd)
+	if err != nil {
+		r.fail("config path: %v", err)
+		return nil, false
+	}
+	cfg, err := config.Load(cfgPath)
+	if errors.Is(err, os.ErrNotExist) {
+		r.fail("config %s does not exist; run: justdoit setup", cfgPath)
+		return nil, false
+	}
+	if err != nil {
+		r.fail("config %s cannot be read: %v", cfgPath, err)
+		return nil, false
+	}
+	// Check what commands will run with: config.json plus env and flag values.
+	overrides, err := config.ApplyOverrides(cfg, configOverrides(cmd))
+	if err != nil {
+		r.fail("config override: %v", err)
+		return nil, false
+	}
+	if problems := cfg.Problems(); len(problems) > 0 {
+		for _, problem := range problems {
+			r.fail("config: %s", problem)
+		}
+	} else {
+		r.ok("config %s", cfgPath)
+	}
+	for _, override := range overrides {
+		r.ok("%s overrides %s", override.Source, override.Key)
+	}
+	return cfg, true
+}
+
+// doctorLists checks that every mapped list still exists; it reports whether
+// it changed the config.
+func doctorLists(app *App, r *doctorReport) bool {
+	remote, err := app.Tasks.ListTaskLists()
+	if err != nil {
+		r.fail("task lists: %v", err)
+		return false
+	}
+	titles := map[string]string{}
+	for _, list := range remote {
+		titles[list.Id] = list.Title
+	}
+	changed := false
+	missing := 0
+	for _, name := range sortedListNames(app.Config.Lists) {
+		id := app.Config.Lists[name]
+		title, ok := titles[id]
+		if !ok {
+			missing++
+			if name == app.Config.DefaultList {
+				r.fail("default list %q (%s) no longer exists; run: justdoit setup", name, id)
+				continue
+			}
+			if r.problem("list %q (%s) no longer exists in Google Tasks", name, id) {
+				delete(app.Config.Lists, name)
+				app.Config.BacklogExcludedLists = removeString(app.Config.BacklogExcludedLists, name)
+				changed = true
+			}
+			continue
+		}
+		if title != name {
+			r.warn("list %q is called %q in Google Tasks", name, title)
+		}
+	}
+	if missing == 0 {
+		r.ok("%d mapped list(s) exist", len(app.Config.Lists))
+	}
+	return changed
+}
+
+// doctorCalendars checks calendar_id and view_calendars against the
+// calendars the account can see; it reports whether it changed the config.
+func doctorCalendars(app *App, r *doctorReport) bool {
+	remote, err := app.Calendar.ListCalendars()
+	if err != nil {
+		r.fail("calendars: %v", err)
+		return false
+	}
+	known := map[string]bool{"primary": true}
+	for _, cal := range remote {
+		known[cal.Id] = true
+		if cal.Primary {
+			known["primary"] = true
+		}
+	}
+	healthy := true
+	if !known[app.Config.CalendarID] {
+		healthy = false
+		r.fail("calendar_id %s is not one of your calendars; run: justdoit config set-calendar", app.Config.CalendarID)
+	}
+	changed := false
+	kept := []string{}
+	for _, id := range app.Config.ViewCalendars {
+		if known[id] {
+			kept = append(kept, id)
+			continue
+		}
+		healthy = false
+		if r.problem("view calendar %s is not one of your calendars", id) {
+			changed = true
+			continue
+		}
+		kept = append(kept, id)
+	}
+	if changed {
+		app.Config.ViewCalendars = kept
+	}
+	if healthy {
+		r.ok("calendars exist")
+	}
+	return changed
+}
+
+func doctorEventLinks(app *App, r *doctorReport) {
+	broken, checked, err := findBrokenLinks(app.Tasks, app.Calendar, app.Config.Lists, app.Config.CalendarID)
+	if err != nil {
+		r.fail("task/event links: %v", err)
+		return
+	}
+	if len(broken) == 0 {
+		r.ok("%d task/event link(s) intact", checked)
+		return
+	}
+	for _, link := range broken {
+		if !r.problem("%q (%s) links to deleted event %s", link.Task.Title, link.ListName, link.EventID) {
+			continue
+		}
+		task := link.Task
+		err := recordOp(app, "doctor", taskScope(link.ListID, task.Id), func() error {
+			task.Notes = sync.TaskEventID.Remove(task.Notes)
+			_, err := app.Tasks.UpdateTask(link.ListID, task)
+			return err
+		})
+		if err != nil {
+			r.fail("unlink %q: %v", task.Title, err)
+		}
+	}
+}
+
+type eventGetter interface {
+	GetEvent(calendarID, eventID string) (*calendar.Event, error)
+}
+
+type brokenLink struct {
+	ListName string
+	ListID   string
+	Task     *tasks.Task
+	EventID  string
+}
+
+// findBrokenLinks returns the open tasks whose justdoit_event_id names an
+// event that was deleted, and how many linked tasks it checked.
+func findBrokenLinks(tp TaskProvider, events eventGetter, lists map[string]string, calendarID string) ([]brokenLink, int, error) {
+	broken := []brokenLink{}
+	checked := 0
+	for _, name := range sortedListNames(lists) {
+		listID := lists[name]
+		items, err := tp.ListTasksWithOptions(listID, false, false, false, "")
+		if err != nil {
+			return nil, 0, fmt.Errorf("list %s: %w", name, err)
+		}
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			eventID, ok := sync.TaskEventID.Get(item.Notes)
+			if !ok || eventID == "" {
+				continue
+			}
+			checked++
+			event, err := events.GetEvent(calendarID, eventID)
+			if err != nil && !isGone(err) {
+				return nil, 0, err
+			}
+			if err != nil || event == nil || strings.EqualFold(event.Status, "cancelled") {
+				broken = append(broken, brokenLink{ListName: name, ListID: listID, Task: item, EventID: eventID})
+			}
+		}
+	}
+	sort.SliceStable(broken, func(i, j int) bool { return broken[i].Task.Title < broken[j].Task.Title })
+	return broken, checked, nil
+}
+
+func isGone(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone)
+}
+
+func removeString(values []string, value string) []string {
+	kept := []string{}
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
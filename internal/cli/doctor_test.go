@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/config"
+)
+
+type fakeEventGetter map[string]*calendar.Event
+
+func (f fakeEventGetter) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
+	if event, ok := f[eventID]; ok {
+		return event, nil
+	}
+	return nil, &googleapi.Error{Code: http.StatusNotFound}
+}
+
+func TestFindBrokenLinks(t *testing.T) {
+	provider := fakeTaskProvider{lists: map[string][]*tasks.Task{
+		"inbox": {
+			{Id: "t1", Title: "Linked", Notes: "justdoit_event_id=e1"},
+			{Id: "t2", Title: "Deleted event", Notes: "justdoit_event_id=e2"},
+			{Id: "t3", Title: "Cancelled event", Notes: "justdoit_event_id=e3"},
+			{Id: "t4", Title: "No event"},
+		},
+	}}
+	events := fakeEventGetter{
+		"e1": {Id: "e1", Status: "confirmed"},
+		"e3": {Id: "e3", Status: "cancelled"},
+	}
+	broken, checked, err := findBrokenLinks(provider, events, map[string]string{"Inbox": "inbox"}, "primary")
+	if err != nil {
+		t.Fatalf("findBrokenLinks: %v", err)
+	}
+	if checked != 3 {
+		t.Fatalf("checked %d links, want 3", checked)
+	}
+	if len(broken) != 2 || broken[0].Task.Id != "t3" || broken[1].Task.Id != "t2" {
+		t.Fatalf("unexpected broken links: %+v", broken)
+	}
+}
+
+func TestConfigProblems(t *testing.T) {
+	cfg := config.Default()
+	cfg.Lists["Inbox"] = "inbox"
+	if problems := cfg.Problems(); len(problems) != 0 {
+		t.Fatalf("default config has problems: %v", problems)
+	}
+	cfg.WorkdayStart = "18:00"
+	cfg.WorkdayEnd = "9:00"
+	cfg.Timezone = "Mars/Olympus"
+	cfg.DefaultList = "Work"
+	cfg.BacklogExcludedLists = []string{"Someday"}
+	if problems := cfg.Problems(); len(problems) != 4 {
+		t.Fatalf("got %d problems, want 4: %v", len(problems), problems)
+	}
+}
+
+func TestDoctorConfigAppliesOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.Default()
+	cfg.Lists["Inbox"] = "inbox"
+	cfg.Timezone = "Mars/Olympus"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", path, "")
+
+	report := &doctorReport{}
+	if _, ok := doctorConfig(cmd, report); !ok || report.failures != 1 {
+		t.Fatalf("expected the unknown timezone reported, got %d failure(s)", report.failures)
+	}
+	t.Setenv("JUSTDOIT_TIMEZONE", "Europe/Berlin")
+	report = &doctorReport{}
+	checked, ok := doctorConfig(cmd, report)
+	if !ok || report.failures != 0 || checked.Timezone != "Europe/Berlin" {
+		t.Fatalf("expected the env override checked, got %d failure(s) and %#v", report.failures, checked)
+	}
+}
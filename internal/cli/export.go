@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/archive"
+	"justdoit/internal/sync"
+)
+
+func newExportCmd() *cobra.Command {
+	var (
+		format string
+		output string
+		lists  []string
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Back up lists, sections, tasks and linked time blocks to one file",
+		Long: "Back up every mapped list (or those given with --list) with its sections, subtasks,\n" +
+			"completed and hidden tasks, and the calendar events linked to them. Restore with: justdoit import FILE\n" +
+			"For an iCalendar feed other apps can read or subscribe to, see: justdoit export ics",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" {
+				return fmt.Errorf("unsupported format %q (want json)", format)
+			}
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			a, err := exportArchive(app, lists)
+			if err != nil {
+				return err
+			}
+			if output == "" || output == "-" {
+				return archive.Write(os.Stdout, a)
+			}
+			file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			if err := archive.Write(file, a); err != nil {
+				_ = file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+			count := 0
+			for _, list := range a.Lists {
+				count += len(list.Tasks)
+			}
+			fmt.Printf("Exported %d task(s) from %d list(s) and %d time block(s) to %s\n", count, len(a.Lists), len(a.Events), output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "Archive format (json)")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "File to write, or - for stdout")
+	cmd.Flags().StringSliceVar(&lists, "list", nil, "Only export these lists (mapped via config.json)")
+	cmd.AddCommand(newExportICSCmd())
+	return cmd
+}
+
+func exportArchive(app *App, names []string) (*archive.Archive, error) {
+	if len(names) == 0 {
+		names = sortedListNames(app.Config.Lists)
+	}
+	titles := map[string]string{}
+	remote, err := app.Tasks.ListTaskLists()
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range remote {
+		titles[list.Id] = list.Title
+	}
+	a := &archive.Archive{
+		ExportedAt:  app.Now().Format(time.RFC3339),
+		Profile:     app.Profile,
+		CalendarID:  app.Config.CalendarID,
+		DefaultList: app.Config.DefaultList,
+		Lists:       []archive.List{},
+		Events:      []*calendar.Event{},
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		id, ok := app.Config.ListID(name)
+		if !ok {
+			return nil, fmt.Errorf("list %q is not mapped in config.json", name)
+		}
+		items, err := app.Tasks.ListTasksWithOptions(id, true, true, false, "")
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", name, err)
+		}
+		kept := []*tasks.Task{}
+		for _, item := range items {
+			if item == nil || item.Deleted {
+				continue
+			}
+			kept = append(kept, item)
+			eventID, ok := sync.TaskEventID.Get(item.Notes)
+			if !ok || eventID == "" || seen[eventID] {
+				continue
+			}
+			seen[eventID] = true
+			event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID)
+			if err != nil && !isGone(err) {
+				return nil, fmt.Errorf("event %s: %w", eventID, err)
+			}
+			if err == nil && event != nil && event.Status != "cancelled" {
+				a.Events = append(a.Events, event)
+			}
+		}
+		a.Lists = append(a.Lists, archive.List{Name: name, ID: id, Title: titles[id], Tasks: kept})
+	}
+	return a, nil
+}
+
+func newImportCmd() *cobra.Command {
+	var (
+		mapping  map[string]string
+		dryRun   bool
+		noEvents bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Recreate the lists, tasks and time blocks of an export archive",
+		Long: "Recreate the lists, tasks and time blocks of an archive written by `justdoit export`.\n" +
+			"Each archived list goes to the list with the same local name (or the one given with --map),\n" +
+			"which is created in Google Tasks and mapped when it does not exist. Time blocks are created\n" +
+			"in calendar_id, and task/event links, sections, dependencies and recurrence chains are\n" +
+			"rewritten to the new IDs. Use - to read the archive from stdin.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = os.Stdin
+			if args[0] != "-" {
+				// #nosec G304 -- the user names the archive to import
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer func() { _ = file.Close() }()
+				in = file
+			}
+			a, err := archive.Read(in)
+			if err != nil {
+				return err
+			}
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+
+			archived := map[string]bool{}
+			for _, list := range a.Lists {
+				archived[list.Name] = true
+			}
+			for from := range mapping {
+				if !archived[from] {
+					return fmt.Errorf("--map %s: the archive has no list %q", from, from)
+				}
+			}
+
+			targets := []importTarget{}
+			configChanged := false
+			for _, list := range a.Lists {
+				name := list.Name
+				if mapped, ok := mapping[name]; ok {
+					name = mapped
+				}
+				target := importTarget{List: list, Name: name}
+				if id, ok := app.Config.ListID(name); ok {
+					target.ListID = id
+				} else if dryRun {
+					fmt.Printf("Would create list %q\n", name)
+				} else {
+					created, err := createNewList(app, name)
+					if err != nil {
+						return fmt.Errorf("create list %q: %w", name, err)
+					}
+					app.Config.Lists[name] = created.ID
+					configChanged = true
+					target.ListID = created.ID
+					fmt.Printf("Created list %q\n", name)
+				}
+				targets = append(targets, target)
+			}
+			if configChanged {
+				if err := app.SaveConfig(); err != nil {
+					return err
+				}
+			}
+			if dryRun {
+				for _, target := range targets {
+					fmt.Printf("Would import %d task(s) from %q into %q\n", len(importOrder(target.List.Tasks)), target.List.Name, target.Name)
+				}
+				if !noEvents {
+					fmt.Printf("Would create %d time block(s) in %s\n", len(a.Events), app.Config.CalendarID)
+				}
+				return nil
+			}
+
+			events := a.Events
+			if noEvents {
+				events = nil
+			}
+			scope := opScope{}
+			for _, target := range targets {
+				scope.ListIDs = append(scope.ListIDs, target.ListID)
+			}
+			var result importResult
+			err = recordOp(app, "import", scope, func() error {
+				var err error
+				result, err = importTasks(app, targets, events)
+				return err
+			})
+			if err != nil {
+				writeImportFailure(os.Stderr, result)
+				return err
+			}
+			fmt.Printf("Imported %d task(s) into %d list(s) and %d time block(s)\n", result.Tasks, len(targets), result.Events)
+			return nil
+		},
+	}
+	cmd.Flags().StringToStringVar(&mapping, "map", nil, "Import an archived list into another local list: OLD=NEW (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without changing anything")
+	cmd.Flags().BoolVar(&noEvents, "no-events", false, "Do not recreate linked time blocks; tasks lose their links")
+	return cmd
+}
+
+type importTarget struct {
+	List   archive.List
+	Name   string
+	ListID string
+}
+
+type importResult struct {
+	Tasks  int
+	Events int
+	// Created describes each task and time block made so far, so a failed
+	// import can say what it left behind.
+	Created []string
+}
+
+// writeImportFailure lists what an interrupted import created; importing the
+// archive again would create those a second time.
+func writeImportFailure(w io.Writer, result importResult) {
+	if len(result.Created) == 0 {
+		_, _ = fmt.Fprintln(w, "Import failed before anything was created.")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Import stopped after creating %d task(s) and %d time block(s):\n", result.Tasks, result.Events)
+	for _, line := range result.Created {
+		_, _ = fmt.Fprintf(w, "  %s\n", line)
+	}
+	_, _ = fmt.Fprintln(w, "Remove them (`justdoit undo --last` reverts the recorded import) before importing again, or they are created twice.")
+}
+
+// importTasks creates the archived tasks, then the events linked to them,
+// then rewrites the IDs in every new task's metadata in a second pass, since
+// a task can refer to tasks and events created after it.
+func importTasks(app *App, targets []importTarget, events []*calendar.Event) (importResult, error) {
+	type createdTask struct {
+		listID string
+		task   *tasks.Task
+		notes  string
+	}
+	result := importResult{}
+	taskIDs := map[string]string{}
+	created := []createdTask{}
+	for _, target := range targets {
+		for _, item := range importOrder(target.List.Tasks) {
+			task := &tasks.Task{
+				Title:  item.Title,
+				Notes:  item.Notes,
+				Due:    item.Due,
+				Status: item.Status,
+			}
+			if item.Status == "completed" {
+				task.Completed = item.Completed
+			}
+			newTask, err := app.Tasks.CreateTaskWithParent(target.ListID, task, taskIDs[item.Parent])
+			if err != nil {
+				return result, fmt.Errorf("create %q: %w", item.Title, err)
+			}
+			taskIDs[item.Id] = newTask.Id
+			created = append(created, createdTask{listID: target.ListID, task: newTask, notes: item.Notes})
+			result.Tasks++
+			result.Created = append(result.Created, fmt.Sprintf("task %q in %s (%s)", newTask.Title, target.Name, newTask.Id))
+		}
+	}
+
+	eventIDs := map[string]string{}
+	for _, event := range events {
+		taskID, _ := sync.EventTaskID.Get(event.Description)
+		newTaskID, ok := taskIDs[taskID]
+		if !ok {
+			continue
+		}
+		copied := copyEvent(event)
+		copied.Description = sync.EventTaskID.Set(event.Description, newTaskID)
+		newEvent, err := app.Calendar.CreateEvent(app.Config.CalendarID, copied)
+		if err != nil {
+			return result, fmt.Errorf("create event %q: %w", event.Summary, err)
+		}
+		eventIDs[event.Id] = newEvent.Id
+		result.Events++
+		result.Created = append(result.Created, fmt.Sprintf("time block %q (%s)", newEvent.Summary, newEvent.Id))
+	}
+
+	for _, c := range created {
+		notes := remapNotes(c.notes, taskIDs, eventIDs)
+		if notes == c.task.Notes {
+			continue
+		}
+		c.task.Notes = notes
+		if _, err := app.Tasks.UpdateTask(c.listID, c.task); err != nil {
+			return result, fmt.Errorf("update %q: %w", c.task.Title, err)
+		}
+	}
+	return result, nil
+}
+
+// importOrder returns the tasks in creation order: parents before their
+// children, and siblings last-to-first, because Google Tasks puts each new
+// task at the top of its siblings.
+func importOrder(items []*tasks.Task) []*tasks.Task {
+	ids := map[string]bool{}
+	for _, item := range items {
+		if item != nil && !item.Deleted {
+			ids[item.Id] = true
+		}
+	}
+	children := map[string][]*tasks.Task{}
+	for _, item := range items {
+		if item == nil || item.Deleted {
+			continue
+		}
+		parent := item.Parent
+		if !ids[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], item)
+	}
+	for _, siblings := range children {
+		sort.SliceStable(siblings, func(i, j int) bool { return siblings[i].Position > siblings[j].Position })
+	}
+	order := []*tasks.Task{}
+	queue := []string{""}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, item := range children[parent] {
+			order = append(order, item)
+			queue = append(queue, item.Id)
+		}
+	}
+	return order
+}
+
+// remapNotes rewrites the task and event IDs in notes metadata to their
+// imported counterparts. Links to events that were not imported are
+// dropped, as are section IDs, which only make sense within one list.
+func remapNotes(notes string, taskIDs, eventIDs map[string]string) string {
+	if id, ok := sync.TaskEventID.Get(notes); ok {
+		if newID, found := eventIDs[id]; found {
+			notes = sync.TaskEventID.Set(notes, newID)
+		} else {
+			notes = sync.TaskEventID.Remove(notes)
+		}
+	}
+	if id, ok := taskSectionRef.Get(notes); ok {
+		notes = setNotesSection(notes, taskIDs[id])
+	}
+	if id, ok := sync.TaskSpawnedFrom.Get(notes); ok {
+		if newID, found := taskIDs[id]; found {
+			notes = sync.TaskSpawnedFrom.Set(notes, newID)
+		}
+	}
+	if blockers := notesBlockers(notes); len(blockers) > 0 {
+		remapped := make([]string, 0, len(blockers))
+		changed := false
+		for _, id := range blockers {
+			if newID, found := taskIDs[id]; found {
+				id = newID
+				changed = true
+			}
+			remapped = append(remapped, id)
+		}
+		if changed {
+			notes = taskBlockedBy.Set(notes, remapped)
+		}
+	}
+	return notes
+}
+
+// copyEvent keeps what describes a time block and leaves out the IDs and
+// bookkeeping that belong to the original calendar.
+func copyEvent(event *calendar.Event) *calendar.Event {
+	return &calendar.Event{
+		Summary:      event.Summary,
+		Description:  event.Description,
+		Location:     event.Location,
+		Start:        event.Start,
+		End:          event.End,
+		Recurrence:   event.Recurrence,
+		ColorId:      event.ColorId,
+		Transparency: event.Transparency,
+		Visibility:   event.Visibility,
+		Reminders:    event.Reminders,
+	}
+}
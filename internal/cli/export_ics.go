@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/ics"
+	"justdoit/internal/metadata"
+	"justdoit/internal/sync"
+)
+
+// icsFeedTTL is how long --serve reuses a built feed, so subscribers polling
+// every few seconds do not spend the API quota.
+const icsFeedTTL = time.Minute
+
+type icsOptions struct {
+	Date      string
+	Lists     []string
+	Section   string
+	Tags      []string
+	Completed bool
+	NoEvents  bool
+}
+
+func newExportICSCmd() *cobra.Command {
+	var (
+		opts   icsOptions
+		output string
+		serve  bool
+		addr   string
+	)
+	cmd := &cobra.Command{
+		Use:   "ics",
+		Short: "Write tasks and their time blocks as an iCalendar feed",
+		Long: "Write tasks as VTODOs (due date, status, recurrence, list/section/tags as categories) and\n" +
+			"their linked time blocks as VEVENTs. With --serve the feed is served read-only over HTTP\n" +
+			"on --addr and rebuilt at most once a minute, for calendar apps and dashboards to subscribe to.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !serve && cmd.Flags().Changed("addr") {
+				return fmt.Errorf("--addr requires --serve")
+			}
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			if _, err := parseTags(opts.Tags); err != nil {
+				return err
+			}
+			if serve {
+				return serveICS(app, opts, addr)
+			}
+			cal, err := buildICS(app, opts)
+			if err != nil {
+				return err
+			}
+			if output == "" || output == "-" {
+				return cal.Write(os.Stdout, time.Now())
+			}
+			var buf bytes.Buffer
+			if err := cal.Write(&buf, time.Now()); err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, buf.Bytes(), 0o600); err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d task(s) and %d time block(s) to %s\n", len(cal.Todos), len(cal.Events), output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Date, "date", "", "Only tasks due on this date or range (e.g. 'today', '2026-01-01..2026-01-31')")
+	cmd.Flags().StringSliceVar(&opts.Lists, "list", nil, "Only these lists (mapped via config.json)")
+	cmd.Flags().StringVar(&opts.Section, "section", "", "Only tasks in this section")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tag", nil, "Only tasks with all of these tags")
+	cmd.Flags().BoolVar(&opts.Completed, "completed", false, "Include completed tasks")
+	cmd.Flags().BoolVar(&opts.NoEvents, "no-events", false, "Leave out the linked time blocks")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "File to write, or - for stdout")
+	cmd.Flags().BoolVar(&serve, "serve", false, "Serve the feed over HTTP instead of writing it")
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8765", "Address to serve the feed on with --serve")
+	return cmd
+}
+
+func buildICS(app *App, opts icsOptions) (*ics.Calendar, error) {
+	var start, end time.Time
+	if strings.TrimSpace(opts.Date) != "" {
+		first, last, err := parseDateRange(opts.Date, app)
+		if err != nil {
+			return nil, err
+		}
+		start, end = first, last.AddDate(0, 0, 1)
+	}
+	tags, err := parseTags(opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+	names := opts.Lists
+	if len(names) == 0 {
+		names = sortedListNames(app.Config.Lists)
+	}
+	cal := &ics.Calendar{Name: "justdoit"}
+	if app.Profile != "" {
+		cal.Name = "justdoit (" + app.Profile + ")"
+	}
+	for _, name := range names {
+		listID, ok := app.Config.ListID(name)
+		if !ok {
+			return nil, fmt.Errorf("list %q is not mapped in config.json", name)
+		}
+		items, err := app.Tasks.ListTasksWithOptions(listID, opts.Completed, opts.Completed, false, "")
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", name, err)
+		}
+		sections := buildSectionIndex(items)
+		for _, item := range items {
+			if item == nil || item.Deleted || isSectionTask(item) {
+				continue
+			}
+			if !opts.Completed && item.Status == "completed" {
+				continue
+			}
+			section := resolveSectionName(item, sections)
+			if opts.Section != "" && !strings.EqualFold(section, strings.TrimSpace(opts.Section)) {
+				continue
+			}
+			if !hasAllTags(notesTags(item.Notes), tags) {
+				continue
+			}
+			todo := icsTodo(item, name, section, sections, app.Location)
+			if !start.IsZero() && !dueInRange(todo, start, end, app.Location) {
+				continue
+			}
+			cal.Todos = append(cal.Todos, todo)
+
+			eventID, ok := sync.TaskEventID.Get(item.Notes)
+			if opts.NoEvents || !ok || eventID == "" {
+				continue
+			}
+			event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID)
+			if err != nil && !isGone(err) {
+				return nil, fmt.Errorf("event %s: %w", eventID, err)
+			}
+			if err != nil || event == nil || event.Status == "cancelled" {
+				continue
+			}
+			if vevent, ok := icsEvent(event, todo.UID); ok {
+				cal.Events = append(cal.Events, vevent)
+			}
+		}
+	}
+	return cal, nil
+}
+
+func icsUID(taskID string) string {
+	return taskID + "@justdoit"
+}
+
+func icsTodo(item *tasks.Task, listName, section string, sections map[string]string, loc *time.Location) ics.Todo {
+	todo := ics.Todo{
+		UID:         icsUID(item.Id),
+		Summary:     item.Title,
+		Description: strings.TrimSpace(metadata.Strip(item.Notes)),
+		Completed:   item.Status == "completed",
+		Categories:  []string{listName},
+	}
+	if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(item.Due)); err == nil {
+		todo.DueHasTime = taskHasTime(parsed, parsed.In(loc))
+		if todo.DueHasTime {
+			todo.Due = parsed
+		} else {
+			// Google Tasks keeps dates at midnight UTC; keep that day.
+			utc := parsed.UTC()
+			todo.Due = time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, loc)
+		}
+	}
+	todo.Start, _ = notesStartDate(item.Notes, loc)
+	if item.Completed != nil {
+		if at, err := time.Parse(time.RFC3339, *item.Completed); err == nil {
+			todo.CompletedAt = at
+		}
+	}
+	if rule, ok := sync.TaskRRule.Get(item.Notes); ok {
+		todo.RRule = strings.TrimPrefix(rule, "RRULE:")
+	}
+	if section != "" && section != "General" {
+		todo.Categories = append(todo.Categories, section)
+	}
+	todo.Categories = append(todo.Categories, notesTags(item.Notes)...)
+	if p := notesPriority(item.Notes); p > 0 {
+		todo.Priority = 2*p - 1
+	}
+	if isSubtask(item, sections) {
+		todo.RelatedTo = icsUID(item.Parent)
+	}
+	if updated, err := time.Parse(time.RFC3339, item.Updated); err == nil {
+		todo.Modified = updated
+	}
+	return todo
+}
+
+func dueInRange(todo ics.Todo, start, end time.Time, loc *time.Location) bool {
+	if todo.Due.IsZero() {
+		return false
+	}
+	due := todo.Due.In(loc)
+	return !due.Before(start) && due.Before(end)
+}
+
+// icsEvent converts a time block; it reports false for events without a
+// usable start and end.
+func icsEvent(event *calendar.Event, relatedTo string) (ics.Event, bool) {
+	if event.Start == nil || event.End == nil {
+		return ics.Event{}, false
+	}
+	vevent := ics.Event{
+		UID:         event.ICalUID,
+		Summary:     event.Summary,
+		Description: strings.TrimSpace(metadata.Strip(event.Description)),
+		Recurrence:  event.Recurrence,
+		RelatedTo:   relatedTo,
+	}
+	if vevent.UID == "" {
+		vevent.UID = event.Id + "@google.com"
+	}
+	var startErr, endErr error
+	if event.Start.DateTime != "" {
+		vevent.Start, startErr = time.Parse(time.RFC3339, event.Start.DateTime)
+		vevent.End, endErr = time.Parse(time.RFC3339, event.End.DateTime)
+	} else {
+		vevent.AllDay = true
+		vevent.Start, startErr = time.Parse("2006-01-02", event.Start.Date)
+		vevent.End, endErr = time.Parse("2006-01-02", event.End.Date)
+	}
+	if startErr != nil || endErr != nil {
+		return ics.Event{}, false
+	}
+	if updated, err := time.Parse(time.RFC3339, event.Updated); err == nil {
+		vevent.Modified = updated
+	}
+	return vevent, true
+}
+
+func serveICS(app *App, opts icsOptions, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		fmt.Fprintln(os.Stderr, "warning: the feed is reachable from other machines and has no authentication")
+	}
+	fmt.Printf("Serving the feed at http://%s/justdoit.ics (Ctrl+C to stop)\n", listener.Addr())
+	server := &http.Server{
+		Handler:           &icsFeed{app: app, opts: opts},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.Serve(listener)
+}
+
+// icsFeed serves the feed read-only, rebuilding it when it is older than
+// icsFeedTTL.
+type icsFeed struct {
+	app   *App
+	opts  icsOptions
+	mu    stdsync.Mutex
+	body  []byte
+	built time.Time
+}
+
+func (f *icsFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/justdoit.ics" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "read-only feed", http.StatusMethodNotAllowed)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.body == nil || time.Since(f.built) > icsFeedTTL {
+		cal, err := buildICS(f.app, f.opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "feed: %v\n", err)
+			http.Error(w, "could not build the feed", http.StatusBadGateway)
+			return
+		}
+		var buf bytes.Buffer
+		if err := cal.Write(&buf, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f.body = buf.Bytes()
+		f.built = time.Now()
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Last-Modified", f.built.UTC().Format(http.TimeFormat))
+	_, _ = w.Write(f.body)
+}
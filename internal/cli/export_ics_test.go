@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/sync"
+)
+
+func TestICSTodo(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	notes := "Bring receipts"
+	notes = sync.TaskRRule.Set(notes, "RRULE:FREQ=WEEKLY;BYDAY=MO")
+	notes = setNotesTags(notes, []string{"errand"}, nil)
+	notes = setNotesPriority(notes, 2)
+	notes = setNotesStartDate(notes, time.Date(2026, 2, 27, 0, 0, 0, 0, loc))
+	completed := "2026-03-03T10:00:00Z"
+	item := &tasks.Task{
+		Id:        "t1",
+		Title:     "Taxes",
+		Notes:     notes,
+		Due:       "2026-03-02T00:00:00.000Z",
+		Status:    "completed",
+		Completed: &completed,
+		Parent:    "p1",
+	}
+	todo := icsTodo(item, "Home", "Admin", map[string]string{"sec": "Admin"}, loc)
+	if todo.UID != "t1@justdoit" || todo.Description != "Bring receipts" || todo.RRule != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Fatalf("todo = %+v", todo)
+	}
+	if todo.Start.Format("2006-01-02") != "2026-02-27" {
+		t.Fatalf("start = %v, want the justdoit_start day", todo.Start)
+	}
+	if todo.DueHasTime || todo.Due.Format("2006-01-02") != "2026-03-02" {
+		t.Fatalf("due = %v (time %v); date-only dues must keep their day", todo.Due, todo.DueHasTime)
+	}
+	if !reflect.DeepEqual(todo.Categories, []string{"Home", "Admin", "errand"}) {
+		t.Fatalf("categories = %v", todo.Categories)
+	}
+	if todo.Priority != 3 || !todo.Completed || todo.CompletedAt.IsZero() || todo.RelatedTo != "p1@justdoit" {
+		t.Fatalf("todo = %+v", todo)
+	}
+
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	if !dueInRange(todo, start, start.AddDate(0, 0, 1), loc) {
+		t.Fatal("due date should fall in its own day")
+	}
+	if dueInRange(todo, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), loc) {
+		t.Fatal("due date should not fall in the next day")
+	}
+}
+
+func TestICSEvent(t *testing.T) {
+	event := &calendar.Event{
+		Id:          "ev1",
+		Summary:     "Taxes",
+		Description: sync.EventTaskID.Set("", "t1"),
+		Start:       &calendar.EventDateTime{DateTime: "2026-03-02T09:00:00-05:00"},
+		End:         &calendar.EventDateTime{DateTime: "2026-03-02T10:00:00-05:00"},
+	}
+	vevent, ok := icsEvent(event, "t1@justdoit")
+	if !ok || vevent.UID != "ev1@google.com" || vevent.Description != "" || vevent.AllDay {
+		t.Fatalf("event = %+v", vevent)
+	}
+	if vevent.End.Sub(vevent.Start) != time.Hour {
+		t.Fatalf("duration = %v", vevent.End.Sub(vevent.Start))
+	}
+
+	event.Start = &calendar.EventDateTime{Date: "2026-03-02"}
+	event.End = &calendar.EventDateTime{Date: "2026-03-03"}
+	if vevent, ok := icsEvent(event, ""); !ok || !vevent.AllDay {
+		t.Fatalf("all-day event = %+v", vevent)
+	}
+	if _, ok := icsEvent(&calendar.Event{Id: "x"}, ""); ok {
+		t.Fatal("an event without times should be skipped")
+	}
+}
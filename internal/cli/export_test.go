@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/sync"
+)
+
+func TestImportOrder(t *testing.T) {
+	items := []*tasks.Task{
+		{Id: "b", Position: "00000000000000000002"},
+		{Id: "b1", Parent: "b", Position: "00000000000000000001"},
+		{Id: "a", Position: "00000000000000000001"},
+		{Id: "b2", Parent: "b", Position: "00000000000000000002"},
+		{Id: "gone", Position: "00000000000000000003", Deleted: true},
+		{Id: "orphan", Parent: "missing", Position: "00000000000000000000"},
+	}
+	got := []string{}
+	for _, item := range importOrder(items) {
+		got = append(got, item.Id)
+	}
+	want := []string{"b", "a", "orphan", "b2", "b1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+}
+
+func TestRemapNotes(t *testing.T) {
+	notes := "Call back"
+	notes = sync.TaskEventID.Set(notes, "ev1")
+	notes = setNotesSection(notes, "sec1")
+	notes = sync.TaskSpawnedFrom.Set(notes, "t0")
+	notes = taskBlockedBy.Set(notes, []string{"t1", "elsewhere"})
+	taskIDs := map[string]string{"sec1": "SEC1", "t0": "T0", "t1": "T1"}
+
+	got := remapNotes(notes, taskIDs, map[string]string{"ev1": "EV1"})
+	if id, _ := sync.TaskEventID.Get(got); id != "EV1" {
+		t.Fatalf("event id = %q", id)
+	}
+	if id, _ := taskSectionRef.Get(got); id != "SEC1" {
+		t.Fatalf("section id = %q", id)
+	}
+	if id, _ := sync.TaskSpawnedFrom.Get(got); id != "T0" {
+		t.Fatalf("spawned from = %q", id)
+	}
+	if blockers := notesBlockers(got); !reflect.DeepEqual(blockers, []string{"T1", "elsewhere"}) {
+		t.Fatalf("blockers = %v", blockers)
+	}
+
+	got = remapNotes(notes, map[string]string{}, map[string]string{})
+	if sync.TaskEventID.Has(got) || taskSectionRef.Has(got) {
+		t.Fatalf("unmapped event and section links should be dropped: %q", got)
+	}
+	if id, _ := sync.TaskSpawnedFrom.Get(got); id != "t0" {
+		t.Fatalf("spawned from = %q", id)
+	}
+
+	if plain := remapNotes("no metadata", taskIDs, nil); plain != "no metadata" {
+		t.Fatalf("plain notes changed: %q", plain)
+	}
+}
+
+func TestWriteImportFailure(t *testing.T) {
+	var buf bytes.Buffer
+	writeImportFailure(&buf, importResult{
+		Tasks:   2,
+		Events:  1,
+		Created: []string{`task "Plan" in Work (n1)`, `task "Draft" in Work (n2)`, `time block "Plan" (e1)`},
+	})
+	out := buf.String()
+	for _, want := range []string{"after creating 2 task(s) and 1 time block(s)", `  task "Draft" in Work (n2)`, `  time block "Plan" (e1)`, "undo --last"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report lacks %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	writeImportFailure(&buf, importResult{})
+	if !strings.Contains(buf.String(), "before anything was created") {
+		t.Fatalf("unexpected report for an empty result: %s", buf.String())
+	}
+}
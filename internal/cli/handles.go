@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"justdoit/internal/cache"
+)
+
+// handleBook assigns handles to the tasks a command prints; save stores them
+// in the cache. A nil book (no cache available) shows no handles.
+type handleBook struct {
+	path  string
+	cache *cache.Cache
+	now   time.Time
+}
+
+func loadHandleBook(app *App) *handleBook {
+	if app.CachePath == "" {
+		return nil
+	}
+	c, err := cache.Load(app.CachePath)
+	if err != nil {
+		return nil
+	}
+	return &handleBook{path: app.CachePath, cache: c, now: app.Now()}
+}
+
+// label returns the "#N " prefix printed before a task title. The handle also
+// records where the task lives, so later commands find it without --list.
+func (b *handleBook) label(listID, section, taskID, title string) string {
+	if b == nil || taskID == "" {
+		return ""
+	}
+	return gray(fmt.Sprintf("#%d", b.cache.Handles.Assign(taskID, listID, section, title, b.now))) + " "
+}
+
+func (b *handleBook) save() error {
+	if b == nil {
+		return nil
+	}
+	b.cache.Handles.Prune(b.now, cache.HandleTTL)
+	return cache.Save(b.path, b.cache)
+}
+
+// isHandle reports whether ref looks like a task handle (#42).
+func isHandle(ref string) bool {
+	if !strings.HasPrefix(ref, "#") {
+		return false
+	}
+	_, err := strconv.Atoi(ref[1:])
+	return err == nil
+}
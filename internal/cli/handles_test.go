@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"justdoit/internal/cache"
+)
+
+func TestHandlesAssignReuseAndPrune(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	handles := &cache.Handles{}
+	first := handles.Assign("task-a", "list-1", "", "Buy milk", now)
+	second := handles.Assign("task-b", "list-1", "", "Call mom", now)
+	if first != 1 || second != 2 {
+		t.Fatalf("expected handles 1 and 2, got %d and %d", first, second)
+	}
+	if again := handles.Assign("task-a", "list-2", "", "Buy oat milk", now.Add(time.Hour)); again != 1 {
+		t.Fatalf("expected task-a to keep #1, got #%d", again)
+	}
+	handles.Prune(now.Add(cache.HandleTTL+30*time.Minute), cache.HandleTTL)
+	if _, ok := handles.Tasks["task-b"]; ok {
+		t.Fatalf("expected stale handle to be pruned")
+	}
+	if third := handles.Assign("task-c", "list-1", "", "Water plants", now); third != 2 {
+		t.Fatalf("expected the freed #2 to be reused, got #%d", third)
+	}
+}
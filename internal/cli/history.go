@@ -0,0 +1,572 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/history"
+	"justdoit/internal/sync"
+)
+
+// historyMu serialises writes to the history log; TUI commands run
+// concurrently.
+var historyMu stdsync.Mutex
+
+// opScope names what an operation may touch: every task list it changes and
+// the tasks or sections it targets (their linked events are snapshotted too).
+// With targets, only they, their subtasks and section members, tasks whose
+// notes refer to them and tasks the operation creates are recorded; without,
+// the whole lists are.
+type opScope struct {
+	ListIDs  []string
+	TaskIDs  []string
+	Sections []string
+}
+
+func taskScope(listID string, taskIDs ...string) opScope {
+	return opScope{ListIDs: []string{listID}, TaskIDs: taskIDs}
+}
+
+func sectionScope(listID, name string, extraListIDs ...string) opScope {
+	return opScope{ListIDs: append([]string{listID}, extraListIDs...), Sections: []string{name}}
+}
+
+func targetScope(targets []bulkTarget, extraListIDs ...string) opScope {
+	scope := opScope{ListIDs: extraListIDs}
+	for _, target := range targets {
+		scope.ListIDs = append(scope.ListIDs, target.ListID)
+		scope.TaskIDs = append(scope.TaskIDs, target.ID)
+	}
+	return scope
+}
+
+// recordOp runs fn and logs what it changed so it can be undone. Changes are
+// recorded even when fn fails part way.
+func recordOp(app *App, action string, scope opScope, fn func() error) error {
+	if app == nil || app.HistoryPath == "" {
+		return fn()
+	}
+	changes, err := captureChanges(app, scope, nil, fn)
+	if len(changes) > 0 {
+		entry := history.Entry{
+			Time:    app.Now().Format(time.RFC3339),
+			Action:  action,
+			Summary: history.Describe(changes),
+			Changes: changes,
+		}
+		if saveErr := updateHistory(app, func(log *history.Log) error {
+			log.Append(entry)
+			return nil
+		}); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: history not saved: %v\n", saveErr)
+		}
+	}
+	return err
+}
+
+func updateHistory(app *App, fn func(log *history.Log) error) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	log, err := history.Load(app.HistoryPath)
+	if err != nil {
+		return err
+	}
+	if err := fn(log); err != nil {
+		return err
+	}
+	return history.Save(app.HistoryPath, log)
+}
+
+// captureChanges snapshots the scope's lists and the events linked to its
+// targets (plus eventIDs) around fn and returns the differences that concern
+// the scope. When a snapshot fails fn still runs, unrecorded.
+func captureChanges(app *App, scope opScope, eventIDs []string, fn func() error) ([]history.Change, error) {
+	listIDs := uniqueStrings(scope.ListIDs)
+	before, err := snapshotLists(app, listIDs)
+	if err != nil {
+		return nil, fn()
+	}
+	targets := map[string]bool{}
+	for _, id := range scope.TaskIDs {
+		targets[id] = true
+	}
+	eventsBefore := map[string]*calendar.Event{}
+	for _, id := range eventIDs {
+		eventsBefore[id] = fetchEvent(app, id)
+	}
+	for _, items := range before {
+		for _, name := range scope.Sections {
+			for _, section := range findSections(items, name) {
+				targets[section.Id] = true
+			}
+		}
+	}
+	for _, items := range before {
+		for _, item := range relatedTasks(items, targets) {
+			if id, ok := sync.TaskEventID.Get(item.Notes); ok {
+				if _, seen := eventsBefore[id]; !seen {
+					eventsBefore[id] = fetchEvent(app, id)
+				}
+			}
+		}
+	}
+
+	opErr := fn()
+
+	after, err := snapshotLists(app, listIDs)
+	if err != nil {
+		return nil, opErr
+	}
+	changes := []history.Change{}
+	for _, listID := range listIDs {
+		changes = append(changes, scopedDiff(listID, before[listID], after[listID], targets, scope.TaskIDs)...)
+	}
+
+	// Events are diffed when they were snapshotted up front, or when a
+	// changed task now links an event it did not have before (a new one).
+	known := map[string]bool{}
+	for _, change := range changes {
+		if change.Before != nil {
+			if id, ok := sync.TaskEventID.Get(change.Before.Notes); ok {
+				known[id] = true
+			}
+		}
+	}
+	eventIDs = eventIDs[:0:0]
+	for id := range eventsBefore {
+		eventIDs = append(eventIDs, id)
+	}
+	for _, change := range changes {
+		if change.After == nil {
+			continue
+		}
+		if id, ok := sync.TaskEventID.Get(change.After.Notes); ok && !known[id] {
+			if _, seen := eventsBefore[id]; !seen {
+				eventsBefore[id] = nil
+				eventIDs = append(eventIDs, id)
+			}
+		}
+	}
+	sort.Strings(eventIDs)
+	for _, id := range eventIDs {
+		previous := eventsBefore[id]
+		current := fetchEvent(app, id)
+		if history.EventChanged(previous, current) {
+			changes = append(changes, history.Change{
+				CalendarID:  app.Config.CalendarID,
+				EventBefore: previous,
+				EventAfter:  current,
+			})
+		}
+	}
+	return changes, opErr
+}
+
+// scopedDiff diffs one list, keeping only what concerns targets (see
+// opScope); without targets every change is kept.
+func scopedDiff(listID string, before, after []*tasks.Task, targets map[string]bool, taskIDs []string) []history.Change {
+	changes := history.DiffTasks(listID, before, after)
+	if len(targets) == 0 {
+		return changes
+	}
+	related := map[string]bool{}
+	for _, item := range append(relatedTasks(before, targets), relatedTasks(after, targets)...) {
+		related[item.Id] = true
+	}
+	kept := []history.Change{}
+	for _, change := range changes {
+		if change.Before == nil || related[change.Before.Id] || mentionsAny(change.Before.Notes, taskIDs) {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// relatedTasks returns the targets in items with their subtasks and, for
+// target sections, the tasks filed under them.
+func relatedTasks(items []*tasks.Task, targets map[string]bool) []*tasks.Task {
+	index := buildSectionIndex(items)
+	related := []*tasks.Task{}
+	for _, item := range items {
+		if item != nil && (targets[item.Id] || targets[item.Parent] || targets[sectionIDOf(item, index)]) {
+			related = append(related, item)
+		}
+	}
+	return related
+}
+
+// mentionsAny reports whether notes refer to one of ids, as dependency and
+// series links do.
+func mentionsAny(notes string, ids []string) bool {
+	for _, id := range ids {
+		if id != "" && strings.Contains(notes, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func snapshotLists(app *App, listIDs []string) (map[string][]*tasks.Task, error) {
+	snapshot := make(map[string][]*tasks.Task, len(listIDs))
+	for _, listID := range listIDs {
+		items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+		if err != nil {
+			return nil, err
+		}
+		snapshot[listID] = items
+	}
+	return snapshot, nil
+}
+
+// fetchEvent returns nil when the event is gone.
+func fetchEvent(app *App, eventID string) *calendar.Event {
+	event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID)
+	if err != nil || event == nil || event.Status == "cancelled" {
+		return nil
+	}
+	return event
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// revertChanges puts every task and event in changes back to its Before
+// state. Deleted items are recreated with new IDs; references to the old IDs
+// in notes and event descriptions are rewritten.
+func revertChanges(app *App, changes []history.Change) error {
+	remap := map[string]string{}
+	rewrite := func(text string) string {
+		for oldID, newID := range remap {
+			text = strings.ReplaceAll(text, oldID, newID)
+		}
+		return text
+	}
+	mapped := func(id string) string {
+		if newID, ok := remap[id]; ok {
+			return newID
+		}
+		return id
+	}
+
+	// Remove what the operation created.
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		switch {
+		case change.Before == nil && change.After != nil:
+			if err := app.Tasks.DeleteTask(change.ListID, change.After.Id); err != nil && !isGone(err) {
+				return err
+			}
+		case change.EventBefore == nil && change.EventAfter != nil:
+			if err := app.Calendar.DeleteEvent(change.CalendarID, change.EventAfter.Id); err != nil && !isGone(err) {
+				return err
+			}
+		}
+	}
+
+	// Recreate deleted tasks, parents first, then their events.
+	recreated := history.RecreateOrder(changes)
+	for _, change := range recreated {
+		task := restorableTask(change.Before)
+		task.Notes = rewrite(task.Notes)
+		created, err := app.Tasks.CreateTaskWithParent(change.ListID, task, mapped(change.Before.Parent))
+		if err != nil {
+			return err
+		}
+		remap[change.Before.Id] = created.Id
+	}
+	for _, change := range changes {
+		if change.EventBefore == nil || change.EventAfter != nil {
+			continue
+		}
+		event := restorableEvent(change.EventBefore)
+		event.Description = rewrite(event.Description)
+		created, err := app.Calendar.CreateEvent(change.CalendarID, event)
+		if err != nil {
+			return err
+		}
+		remap[change.EventBefore.Id] = created.Id
+	}
+	for _, change := range recreated {
+		if err := restorePosition(app, change.ListID, remap[change.Before.Id], mapped(change.Before.Parent), mapped(change.Previous)); err != nil {
+			return err
+		}
+	}
+
+	// Restore modified tasks and events.
+	for _, change := range changes {
+		if change.Before == nil || change.After == nil {
+			continue
+		}
+		current, err := app.Tasks.GetTask(change.ListID, change.After.Id)
+		if err != nil {
+			return err
+		}
+		current.Title = change.Before.Title
+		current.Notes = rewrite(change.Before.Notes)
+		current.Due = change.Before.Due
+		if current.Due == "" {
+			current.NullFields = append(current.NullFields, "Due")
+		}
+		setTaskStatus(current, change.Before)
+		if _, err := app.Tasks.UpdateTask(change.ListID, current); err != nil {
+			return err
+		}
+		if change.Before.Parent != change.After.Parent || change.Before.Position != change.After.Position {
+			if err := restorePosition(app, change.ListID, current.Id, mapped(change.Before.Parent), mapped(change.Previous)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, change := range changes {
+		if change.EventBefore == nil || change.EventAfter == nil {
+			continue
+		}
+		current, err := app.Calendar.GetEvent(change.CalendarID, change.EventAfter.Id)
+		if err != nil {
+			return err
+		}
+		current.Summary = change.EventBefore.Summary
+		current.Description = rewrite(change.EventBefore.Description)
+		current.ColorId = change.EventBefore.ColorId
+		current.Start = change.EventBefore.Start
+		current.End = change.EventBefore.End
+		current.Recurrence = change.EventBefore.Recurrence
+		if _, err := app.Calendar.UpdateEvent(change.CalendarID, current); err != nil {
+			return err
+		}
+	}
+
+	if len(remap) == 0 {
+		return nil
+	}
+	// Other tasks may still point at recreated ones (sections, blockers).
+	for _, listID := range historyListIDs(app, changes) {
+		items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			notes := rewrite(item.Notes)
+			if notes == item.Notes {
+				continue
+			}
+			item.Notes = notes
+			if _, err := app.Tasks.UpdateTask(listID, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// historyListIDs returns the lists touched by changes, or every mapped list
+// when a recreated task may be referenced from elsewhere.
+func historyListIDs(app *App, changes []history.Change) []string {
+	ids := history.ListIDs(changes)
+	for _, id := range app.Config.Lists {
+		ids = append(ids, id)
+	}
+	return uniqueStrings(ids)
+}
+
+// restorePosition moves a task back after previous, or first under parent
+// when previous no longer exists.
+func restorePosition(app *App, listID, taskID, parentID, previousID string) error {
+	if _, err := app.Tasks.MoveTask(listID, taskID, parentID, previousID); err == nil || previousID == "" {
+		return err
+	}
+	_, err := app.Tasks.MoveTask(listID, taskID, parentID, "")
+	return err
+}
+
+func restorableTask(before *tasks.Task) *tasks.Task {
+	return &tasks.Task{
+		Title:     before.Title,
+		Notes:     before.Notes,
+		Due:       before.Due,
+		Status:    before.Status,
+		Completed: before.Completed,
+	}
+}
+
+func restorableEvent(before *calendar.Event) *calendar.Event {
+	return &calendar.Event{
+		Summary:     before.Summary,
+		Description: before.Description,
+		ColorId:     before.ColorId,
+		Start:       before.Start,
+		End:         before.End,
+		Recurrence:  before.Recurrence,
+		Reminders:   before.Reminders,
+	}
+}
+
+func setTaskStatus(task, before *tasks.Task) {
+	if strings.EqualFold(before.Status, "completed") {
+		task.Status = "completed"
+		task.Completed = before.Completed
+		return
+	}
+	task.Status = "needsAction"
+	task.Completed = nil
+	task.NullFields = append(task.NullFields, "Completed")
+}
+
+// undoEntry reverts entry and marks it undone.
+func undoEntry(app *App, entry history.Entry) (history.Entry, error) {
+	if entry.Undone {
+		return entry, fmt.Errorf("#%d is already undone", entry.ID)
+	}
+	reverted, err := captureChanges(app, opScope{ListIDs: history.ListIDs(entry.Changes), TaskIDs: history.TaskIDs(entry.Changes)}, history.EventIDs(entry.Changes), func() error {
+		return revertChanges(app, entry.Changes)
+	})
+	if saveErr := updateHistory(app, func(log *history.Log) error {
+		stored := log.Find(entry.ID)
+		if stored == nil {
+			return fmt.Errorf("history entry #%d not found", entry.ID)
+		}
+		log.MarkUndone(stored, reverted)
+		entry = *stored
+		return nil
+	}); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return entry, err
+}
+
+// redoEntry applies an undone entry again by reverting its undo.
+func redoEntry(app *App, entry history.Entry) (history.Entry, error) {
+	if !entry.Undone {
+		return entry, fmt.Errorf("#%d is not undone", entry.ID)
+	}
+	changes, err := captureChanges(app, opScope{ListIDs: history.ListIDs(entry.Reverted), TaskIDs: history.TaskIDs(entry.Reverted)}, history.EventIDs(entry.Reverted), func() error {
+		return revertChanges(app, entry.Reverted)
+	})
+	if saveErr := updateHistory(app, func(log *history.Log) error {
+		stored := log.Find(entry.ID)
+		if stored == nil {
+			return fmt.Errorf("history entry #%d not found", entry.ID)
+		}
+		log.MarkRedone(stored, changes)
+		entry = *stored
+		return nil
+	}); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return entry, err
+}
+
+func loadHistoryEntry(app *App, pick func(log *history.Log) *history.Entry, empty string) (history.Entry, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	log, err := history.Load(app.HistoryPath)
+	if err != nil {
+		return history.Entry{}, err
+	}
+	entry := pick(log)
+	if entry == nil {
+		return history.Entry{}, fmt.Errorf("%s", empty)
+	}
+	return *entry, nil
+}
+
+func undoLast(app *App) (history.Entry, error) {
+	entry, err := loadHistoryEntry(app, (*history.Log).LastUndoable, "nothing to undo")
+	if err != nil {
+		return entry, err
+	}
+	return undoEntry(app, entry)
+}
+
+func redoLast(app *App) (history.Entry, error) {
+	entry, err := loadHistoryEntry(app, (*history.Log).LastRedoable, "nothing to redo")
+	if err != nil {
+		return entry, err
+	}
+	return redoEntry(app, entry)
+}
+
+func formatHistoryEntry(entry history.Entry, loc *time.Location) string {
+	when := entry.Time
+	if parsed, err := time.Parse(time.RFC3339, entry.Time); err == nil {
+		when = parsed.In(loc).Format("2006-01-02 15:04")
+	}
+	line := fmt.Sprintf("#%-4d %s  %-8s %s", entry.ID, when, entry.Action, entry.Summary)
+	if entry.Undone {
+		line += " " + gray("(undone)")
+	}
+	return line
+}
+
+func newHistoryCmd() *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent changes that can be undone",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			log, err := history.Load(app.HistoryPath)
+			if err != nil {
+				return err
+			}
+			if len(log.Entries) == 0 {
+				fmt.Println("(no history)")
+				return nil
+			}
+			entries := log.Entries
+			if limit > 0 && len(entries) > limit {
+				entries = entries[len(entries)-limit:]
+			}
+			for i := len(entries) - 1; i >= 0; i-- {
+				fmt.Println(formatHistoryEntry(entries[i], app.Location))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of entries to show (0 for all)")
+	return cmd
+}
+
+func newRedoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "redo",
+		Short: "Re-apply the most recently undone change",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			entry, err := redoLast(app)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("↪️  Redone: %s %s\n", entry.Action, entry.Summary)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestScopedDiffKeepsOnlyTheTargetedTasks(t *testing.T) {
+	section := &tasks.Task{Id: "s1", Title: "Errands", Notes: sectionMarker.Set("", true)}
+	before := []*tasks.Task{
+		section,
+		{Id: "target", Title: "Buy milk"},
+		{Id: "child", Title: "Oat milk", Parent: "target"},
+		{Id: "blocked", Title: "Bake", Notes: taskBlockedBy.Set("", []string{"target"})},
+		{Id: "member", Title: "Post office", Notes: taskSectionRef.Set("", "s1")},
+		{Id: "other", Title: "Edited elsewhere meanwhile"},
+	}
+	after := []*tasks.Task{
+		section,
+		{Id: "target", Title: "Buy milk", Status: "completed"},
+		{Id: "child", Title: "Oat milk", Parent: "target", Status: "completed"},
+		{Id: "blocked", Title: "Bake"},
+		{Id: "member", Title: "Post office", Notes: taskSectionRef.Set("", "s1"), Status: "completed"},
+		{Id: "other", Title: "Edited elsewhere meanwhile", Status: "completed"},
+		{Id: "spawned", Title: "Buy milk"},
+	}
+	touched := func(targets map[string]bool, taskIDs []string) []string {
+		ids := []string{}
+		for _, change := range scopedDiff("list-1", before, after, targets, taskIDs) {
+			if change.Before != nil {
+				ids = append(ids, change.Before.Id)
+			} else {
+				ids = append(ids, change.After.Id)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if got := touched(map[string]bool{"target": true}, []string{"target"}); !reflect.DeepEqual(got, []string{"blocked", "child", "spawned", "target"}) {
+		t.Fatalf("unexpected task scope %v", got)
+	}
+	if got := touched(map[string]bool{"s1": true}, nil); !reflect.DeepEqual(got, []string{"member", "spawned"}) {
+		t.Fatalf("unexpected section scope %v", got)
+	}
+	if got := touched(map[string]bool{}, nil); len(got) != 6 {
+		t.Fatalf("expected a scope without targets to keep the whole list, got %v", got)
+	}
+}
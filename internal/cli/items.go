@@ -14,6 +14,7 @@ type calendarEventItem struct {
 	Start        time.Time
 	End          time.Time
 	AllDay       bool
+	Profile      string
 }
 
 func (c calendarEventItem) Title() string {
@@ -40,6 +41,9 @@ func (c calendarEventItem) Description() string {
 	if c.CalendarName != "" {
 		parts = append(parts, c.CalendarName)
 	}
+	if c.Profile != "" {
+		parts = append(parts, c.Profile)
+	}
 	return strings.Join(parts, " | ")
 }
 
@@ -2,14 +2,13 @@ package cli
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/api/tasks/v1"
 
-	"justdoit/internal/metadata"
+	"justdoit/internal/sync"
 )
 
 type taskRow struct {
@@ -18,8 +17,15 @@ type taskRow struct {
 	Due        time.Time
 	HasDue     bool
 	HasTime    bool
+	Start      time.Time
 	Index      int
+	Position   string
 	Recurrence string
+	Priority   int
+	Tags       []string
+	Subtasks   []taskRow
+	Done       int
+	Total      int
 }
 
 func newListCmd() *cobra.Command {
@@ -28,6 +34,7 @@ func newListCmd() *cobra.Command {
 		section string
 		all     bool
 		ids     bool
+		manual  bool
 	)
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -41,13 +48,17 @@ func newListCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			items, err := app.Tasks.ListTasksWithOptions(listID, all, all, false, "")
+			// Completed tasks are always fetched so subtask progress counts them.
+			items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
 			if err != nil {
 				return err
 			}
 
+			handles := loadHandleBook(app)
+			now := app.Now().In(app.Location)
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, app.Location)
 			sectionFilter := strings.TrimSpace(section)
-			sections, order := groupTasksBySection(items, sectionFilter, app.Location)
+			sections, order := groupTasksBySection(items, sectionFilter, all, app.Location)
 			if len(sections) == 0 {
 				fmt.Println("(no tasks)")
 				return nil
@@ -58,39 +69,44 @@ func newListCmd() *cobra.Command {
 					continue
 				}
 				fmt.Printf("\n%s\n", name)
-				printTasks(tasks, ids)
+				printTasks(tasks, listID, name, handles, ids, manual, today)
 			}
-			return nil
+			return handles.save()
 		},
 	}
 	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
 	cmd.Flags().StringVar(&section, "section", "", "Filter by section name")
 	cmd.Flags().BoolVar(&all, "all", false, "Include completed/hidden tasks")
 	cmd.Flags().BoolVar(&ids, "ids", false, "Show task IDs")
+	cmd.Flags().BoolVar(&manual, "manual", false, "Show tasks in their manual (Google Tasks) order")
 	return cmd
 }
 
-func groupTasksBySection(items []*tasks.Task, filter string, loc *time.Location) (map[string][]taskRow, []string) {
+func groupTasksBySection(items []*tasks.Task, filter string, includeCompleted bool, loc *time.Location) (map[string][]taskRow, []string) {
 	sections := map[string][]taskRow{}
 	order := []string{}
 	sectionIDs := map[string]string{}
 	sectionNames := map[string]bool{}
 
 	for _, item := range items {
-		if _, ok := metadata.Extract(item.Notes, "justdoit_section"); ok {
+		if sectionMarker.Has(item.Notes) {
 			sectionIDs[item.Id] = item.Title
-			if !sectionNames[item.Title] {
-				order = append(order, item.Title)
-				sectionNames[item.Title] = true
-			}
 		}
 	}
+	for _, section := range sortedSections(items) {
+		order = append(order, section.Title)
+		sectionNames[section.Title] = true
+	}
+	children := indexSubtasks(items, sectionIDs)
 	for i, item := range items {
-		if _, ok := metadata.Extract(item.Notes, "justdoit_section"); ok {
+		if sectionMarker.Has(item.Notes) || isSubtask(item, sectionIDs) {
+			continue
+		}
+		if !includeCompleted && item.Status == "completed" {
 			continue
 		}
 		sectionName := "General"
-		if parent, ok := sectionIDs[item.Parent]; ok {
+		if parent, ok := sectionIDs[sectionIDOf(item, sectionIDs)]; ok {
 			sectionName = parent
 		}
 		if filter != "" && !strings.EqualFold(filter, sectionName) {
@@ -100,44 +116,54 @@ func groupTasksBySection(items []*tasks.Task, filter string, loc *time.Location)
 			order = append(order, sectionName)
 			sectionNames[sectionName] = true
 		}
-		row := taskRow{ID: item.Id, Title: item.Title, Index: i}
-		if rule, ok := metadata.Extract(item.Notes, "justdoit_rrule"); ok {
-			row.Recurrence = rule
+		row := newTaskRow(item, i, loc)
+		row.Done, row.Total = subtaskProgress(children[item.Id])
+		for j, child := range children[item.Id] {
+			if !includeCompleted && child.Status == "completed" {
+				continue
+			}
+			row.Subtasks = append(row.Subtasks, newTaskRow(child, j, loc))
 		}
-		row.Due, row.HasDue, row.HasTime = parseTaskDue(item.Due, loc)
 		sections[sectionName] = append(sections[sectionName], row)
 	}
 	return sections, order
 }
 
-func printTasks(tasks []taskRow, showIDs bool) {
-	due := make([]taskRow, 0, len(tasks))
-	noDue := make([]taskRow, 0, len(tasks))
-	for _, t := range tasks {
-		if t.HasDue {
-			due = append(due, t)
-		} else {
-			noDue = append(noDue, t)
-		}
+func newTaskRow(item *tasks.Task, index int, loc *time.Location) taskRow {
+	row := taskRow{ID: item.Id, Title: item.Title, Index: index, Position: item.Position}
+	if rule, ok := sync.TaskRRule.Get(item.Notes); ok {
+		row.Recurrence = rule
 	}
-	sort.SliceStable(due, func(i, j int) bool {
-		if due[i].Due.Equal(due[j].Due) {
-			return due[i].Index < due[j].Index
-		}
-		return due[i].Due.Before(due[j].Due)
-	})
+	row.Priority = notesPriority(item.Notes)
+	row.Tags = notesTags(item.Notes)
+	row.Due, row.HasDue, row.HasTime = parseTaskDue(item.Due, loc)
+	row.Start, _ = notesStartDate(item.Notes, loc)
+	return row
+}
+
+// printTasks prints one section; today marks which start dates are still
+// ahead, as isDeferred does.
+func printTasks(tasks []taskRow, listID, section string, handles *handleBook, showIDs, manual bool, today time.Time) {
+	printTaskRows(tasks, listID, section, handles, showIDs, manual, today, "")
+}
 
-	ordered := append(due, noDue...)
-	for _, t := range ordered {
+func printTaskRows(tasks []taskRow, listID, section string, handles *handleBook, showIDs, manual bool, today time.Time, indent string) {
+	for _, t := range sortTaskRows(tasks, manual) {
 		dueText := ""
 		if t.HasDue {
 			dueText = fmt.Sprintf(" (due %s)", t.Due.Format("2006-01-02"))
 		}
+		if t.Start.After(today) {
+			dueText += gray(fmt.Sprintf(" (starts %s)", t.Start.Format("2006-01-02")))
+		}
 		idText := ""
 		if showIDs {
 			idText = fmt.Sprintf(" [id: %s]", t.ID)
 		}
-		title := recurringTitle(t.Title, t.Recurrence)
-		fmt.Printf("- %s%s%s\n", title, dueText, idText)
+		title := priorityTitle(recurringTitle(t.Title, t.Recurrence), t.Priority)
+		fmt.Printf("%s- %s%s%s%s%s%s\n", indent, handles.label(listID, section, t.ID, t.Title), title, progressSuffix(t.Done, t.Total), tagsSuffix(t.Tags), dueText, idText)
+		if len(t.Subtasks) > 0 {
+			printTaskRows(t.Subtasks, listID, section, handles, showIDs, manual, today, indent+"  ")
+		}
 	}
 }
@@ -14,7 +14,7 @@ func TestGroupTasksBySectionBuildsSectionIndexFirst(t *testing.T) {
 		{Id: sectionID, Title: "Recurrentes", Notes: "justdoit_section=1"},
 	}
 
-	sections, order := groupTasksBySection(items, "", time.UTC)
+	sections, order := groupTasksBySection(items, "", false, time.UTC)
 	if len(sections) == 0 {
 		t.Fatalf("expected sections, got none")
 	}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/config"
+)
+
+// listSync describes how the mapped lists differ from Google Tasks.
+type listSync struct {
+	Added   []string
+	Renamed []listRename
+	Removed []string
+	// Tracked counts mapped lists whose Google title was recorded for the
+	// first time; nothing is reported for them.
+	Tracked int
+	// Applied is false when the differences were only reported.
+	Applied bool
+}
+
+// listRename is a list renamed in Google Tasks. Kept names the mapping when
+// it was left alone: the user chose that name, or the new title is taken.
+type listRename struct {
+	From string
+	To   string
+	Kept string
+}
+
+func (s listSync) empty() bool {
+	return len(s.Added) == 0 && len(s.Renamed) == 0 && len(s.Removed) == 0
+}
+
+// changed reports whether applying the sync changed the config.
+func (s listSync) changed() bool {
+	return !s.empty() || s.Tracked > 0
+}
+
+// summary is a one-line status such as "2 new, 1 renamed".
+func (s listSync) summary() string {
+	parts := []string{}
+	if n := len(s.Added); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", n))
+	}
+	if n := len(s.Renamed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d renamed", n))
+	}
+	if n := len(s.Removed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d deleted", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s listSync) lines() []string {
+	lines := []string{}
+	for _, name := range s.Added {
+		if s.Applied {
+			lines = append(lines, "+ "+name)
+		} else {
+			lines = append(lines, "+ "+name+" (not mapped)")
+		}
+	}
+	for _, rename := range s.Renamed {
+		if rename.Kept != "" {
+			lines = append(lines, fmt.Sprintf("~ %s → %s in Google Tasks (still mapped as %s)", rename.From, rename.To, rename.Kept))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("~ %s → %s", rename.From, rename.To))
+	}
+	for _, name := range s.Removed {
+		lines = append(lines, "- "+name+" (deleted in Google Tasks)")
+	}
+	return lines
+}
+
+// syncListMappings compares cfg.Lists with the remote task lists. A rename is
+// a change of the Google title recorded in cfg.ListTitles; a local name that
+// differs from the title was chosen by the user and is never replaced. With
+// apply it maps new lists by title, follows renames of lists named after
+// their title (keeping the old name as an alias), unmaps deleted lists and
+// records the current titles; the default list is never unmapped.
+func syncListMappings(cfg *config.Config, remote []*tasks.TaskList, apply bool) listSync {
+	result := listSync{Applied: apply}
+	mapped := map[string]string{}
+	for name, id := range cfg.Lists {
+		mapped[id] = name
+	}
+	remoteIDs := map[string]bool{}
+	sorted := append([]*tasks.TaskList(nil), remote...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Title < sorted[j].Title })
+	for _, list := range sorted {
+		if list == nil || list.Id == "" {
+			continue
+		}
+		remoteIDs[list.Id] = true
+		title := strings.TrimSpace(list.Title)
+		name, ok := mapped[list.Id]
+		seen, known := cfg.ListTitles[list.Id]
+		switch {
+		case !ok:
+			name = title
+			if apply {
+				name = uniqueListName(cfg, title)
+				cfg.Lists[name] = list.Id
+			}
+			result.Added = append(result.Added, name)
+		case !known:
+			result.Tracked++
+		case seen != title && title != "":
+			rename := listRename{From: seen, To: title}
+			if name != seen {
+				rename.Kept = name
+			} else if _, taken := cfg.Lists[title]; taken {
+				rename.Kept = name
+			} else if apply {
+				renameListMapping(cfg, name, title)
+			}
+			result.Renamed = append(result.Renamed, rename)
+		}
+		if apply && title != "" {
+			if cfg.ListTitles == nil {
+				cfg.ListTitles = map[string]string{}
+			}
+			cfg.ListTitles[list.Id] = title
+		}
+	}
+	for _, name := range sortedListNames(cfg.Lists) {
+		if remoteIDs[cfg.Lists[name]] {
+			continue
+		}
+		result.Removed = append(result.Removed, name)
+		if apply && name != cfg.DefaultList {
+			unmapList(cfg, name)
+		}
+	}
+	return result
+}
+
+func uniqueListName(cfg *config.Config, title string) string {
+	if title == "" {
+		title = "Untitled"
+	}
+	name := title
+	for n := 2; ; n++ {
+		if _, taken := cfg.Lists[name]; !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s (%d)", title, n)
+	}
+}
+
+// renameListMapping moves a mapping to a new name, updating every setting
+// that refers to the list by name. The old name stays usable as an alias.
+func renameListMapping(cfg *config.Config, from, to string) {
+	cfg.Lists[to] = cfg.Lists[from]
+	delete(cfg.Lists, from)
+	if cfg.DefaultList == from {
+		cfg.DefaultList = to
+	}
+	for i, name := range cfg.BacklogExcludedLists {
+		if name == from {
+			cfg.BacklogExcludedLists[i] = to
+		}
+	}
+	for alias, target := range cfg.ListAliases {
+		if target == from {
+			cfg.ListAliases[alias] = to
+		}
+	}
+	delete(cfg.ListAliases, to)
+	if cfg.ListAliases == nil {
+		cfg.ListAliases = map[string]string{}
+	}
+	cfg.ListAliases[from] = to
+}
+
+func unmapList(cfg *config.Config, name string) {
+	delete(cfg.ListTitles, cfg.Lists[name])
+	delete(cfg.Lists, name)
+	cfg.BacklogExcludedLists = removeString(cfg.BacklogExcludedLists, name)
+	for alias, target := range cfg.ListAliases {
+		if target == name {
+			delete(cfg.ListAliases, alias)
+		}
+	}
+}
+
+type listsSyncedMsg struct {
+	remote []*tasks.TaskList
+	err    error
+}
+
+// syncListsCmd fetches the remote task lists when auto_lists is on. The
+// mappings are compared and updated in Update, against the config as it is
+// then, so nothing changed in the meantime is lost.
+func (m *tuiModel) syncListsCmd() tea.Cmd {
+	if !m.app.Config.AutoLists {
+		return nil
+	}
+	return func() tea.Msg {
+		remote, err := m.app.Tasks.ListTaskLists()
+		return listsSyncedMsg{remote: remote, err: err}
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/config"
+)
+
+func TestSyncListMappings(t *testing.T) {
+	cfg := config.Default()
+	cfg.DefaultList = "Inbox"
+	cfg.Lists = map[string]string{"Inbox": "in", "Chores": "ch", "Old": "gone"}
+	cfg.BacklogExcludedLists = []string{"Chores", "Old"}
+	cfg.ListAliases = map[string]string{"c": "Chores"}
+	cfg.ListTitles = map[string]string{"in": "Inbox", "ch": "Chores", "gone": "Old"}
+	remote := []*tasks.TaskList{
+		{Id: "in", Title: "Inbox"},
+		{Id: "ch", Title: "Household"},
+		{Id: "new", Title: "Reading"},
+	}
+
+	report := syncListMappings(cfg.Clone(), remote, false)
+	if report.Applied || len(report.Added) != 1 || len(report.Renamed) != 1 || len(report.Removed) != 1 {
+		t.Fatalf("unexpected dry-run report: %+v", report)
+	}
+
+	result := syncListMappings(cfg, remote, true)
+	if result.summary() != "1 new, 1 renamed, 1 deleted" {
+		t.Fatalf("summary = %q", result.summary())
+	}
+	wantLists := map[string]string{"Inbox": "in", "Household": "ch", "Reading": "new"}
+	if !reflect.DeepEqual(cfg.Lists, wantLists) {
+		t.Fatalf("lists = %v, want %v", cfg.Lists, wantLists)
+	}
+	if !reflect.DeepEqual(cfg.BacklogExcludedLists, []string{"Household"}) {
+		t.Fatalf("backlog_excluded_lists = %v", cfg.BacklogExcludedLists)
+	}
+	for _, name := range []string{"c", "Chores"} {
+		if id, ok := cfg.ListID(name); !ok || id != "ch" {
+			t.Fatalf("alias %q resolves to %q, %v", name, id, ok)
+		}
+	}
+
+	if again := syncListMappings(cfg, remote, true); !again.empty() {
+		t.Fatalf("second sync should be a no-op: %+v", again)
+	}
+}
+
+func TestSyncListMappingsKeepsNamesTheUserChose(t *testing.T) {
+	cfg := config.Default()
+	cfg.DefaultList = "Inbox"
+	cfg.Lists = map[string]string{"Inbox": "mine"}
+	remote := []*tasks.TaskList{{Id: "mine", Title: "My Tasks"}}
+
+	first := syncListMappings(cfg, remote, true)
+	if !first.empty() || first.Tracked != 1 || !first.changed() {
+		t.Fatalf("expected the title recorded without a rename, got %+v", first)
+	}
+	if cfg.Lists["Inbox"] != "mine" || cfg.DefaultList != "Inbox" || cfg.ListTitles["mine"] != "My Tasks" {
+		t.Fatalf("unexpected config after the first sync: lists %v, default %q, titles %v", cfg.Lists, cfg.DefaultList, cfg.ListTitles)
+	}
+
+	remote[0].Title = "Personal"
+	second := syncListMappings(cfg, remote, true)
+	if len(second.Renamed) != 1 || second.Renamed[0] != (listRename{From: "My Tasks", To: "Personal", Kept: "Inbox"}) {
+		t.Fatalf("expected the Google rename reported with the local name kept, got %+v", second)
+	}
+	if cfg.Lists["Inbox"] != "mine" || cfg.DefaultList != "Inbox" || cfg.ListTitles["mine"] != "Personal" {
+		t.Fatalf("expected the user's name kept: lists %v, default %q, titles %v", cfg.Lists, cfg.DefaultList, cfg.ListTitles)
+	}
+	if again := syncListMappings(cfg, remote, true); again.changed() {
+		t.Fatalf("third sync should be a no-op: %+v", again)
+	}
+}
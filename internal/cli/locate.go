@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	stdsync "sync"
+
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/cache"
+)
+
+// minIDPrefix is the shortest task ID prefix accepted in place of a full ID.
+const minIDPrefix = 4
+
+// taskIndex maps task IDs to every list they are known in. It is built from
+// the handles shown by list/next/search and the tasks synced for the week
+// view; a stale cache can place one ID in several lists.
+type taskIndex map[string][]bulkTarget
+
+func buildTaskIndex(c *cache.Cache) taskIndex {
+	index := taskIndex{}
+	add := func(target bulkTarget) {
+		for _, existing := range index[target.ID] {
+			if existing.ListID == target.ListID {
+				return
+			}
+		}
+		index[target.ID] = append(index[target.ID], target)
+	}
+	// Handles go first: they carry the section and the most recent title.
+	for id, handle := range c.Handles.Tasks {
+		add(bulkTarget{ListID: handle.ListID, ID: id, Title: handle.Title, Section: handle.Section})
+	}
+	for listID, entries := range c.Tasks.Lists {
+		for id, entry := range entries {
+			add(bulkTarget{ListID: listID, ID: id, Title: entry.Title})
+		}
+	}
+	return index
+}
+
+// resolveTaskRef finds a task given as a handle (#42), a full task ID or a
+// unique ID prefix. listName narrows the search; without it every configured
+// list is considered, so the task's list does not have to be known.
+func resolveTaskRef(app *App, listName, ref string) (bulkTarget, error) {
+	ref = strings.TrimSpace(ref)
+	listID := ""
+	if listName != "" {
+		id, err := resolveListID(app, listName, true)
+		if err != nil {
+			return bulkTarget{}, err
+		}
+		listID = id
+	}
+	c := loadTaskCache(app)
+	matches, err := matchTaskRef(c, ref, listID)
+	if err != nil {
+		return bulkTarget{}, err
+	}
+	if len(matches) == 0 && !isHandle(ref) {
+		if listID != "" {
+			return bulkTarget{ListName: listNameForID(app, listID), ListID: listID, ID: ref}, nil
+		}
+		matches = probeTaskLists(app, ref)
+		if len(matches) == 1 && app.CachePath != "" {
+			// Remember where the task lives so the next lookup needs no probing.
+			c.Handles.Assign(matches[0].ID, matches[0].ListID, matches[0].Section, matches[0].Title, app.Now())
+			_ = cache.Save(app.CachePath, c)
+		}
+	}
+	for i := range matches {
+		matches[i].ListName = listNameForID(app, matches[i].ListID)
+	}
+	switch len(matches) {
+	case 0:
+		return bulkTarget{}, fmt.Errorf("task not found in configured lists: %s", ref)
+	case 1:
+		return matches[0], nil
+	}
+	candidates := []string{}
+	sameID := true
+	for _, match := range matches {
+		label := match.label()
+		if handle, ok := c.Handles.Tasks[match.ID]; ok && handle.ListID == match.ListID {
+			label = fmt.Sprintf("#%d %s", handle.N, label)
+		}
+		candidates = append(candidates, label)
+		sameID = sameID && match.ID == matches[0].ID
+	}
+	if sameID {
+		return bulkTarget{}, fmt.Errorf("task %s exists in several lists; pass --list to pick one:\n- %s", ref, strings.Join(candidates, "\n- "))
+	}
+	return bulkTarget{}, fmt.Errorf("task ID %q is ambiguous:\n- %s", ref, strings.Join(candidates, "\n- "))
+}
+
+// loadTaskCache returns the cache behind handles and task lookups, or an
+// empty one when there is none yet.
+func loadTaskCache(app *App) *cache.Cache {
+	if app.CachePath != "" {
+		if loaded, err := cache.Load(app.CachePath); err == nil {
+			return loaded
+		}
+	}
+	return cache.Default()
+}
+
+// isTaskRef reports whether arg names tasks rather than being a word of a
+// title or time: a handle, or a task ID (or ID prefix) the cache knows. A
+// comma-separated arg qualifies when every part does. The API is not asked,
+// so an ID never shown by list/next/search does not count.
+func isTaskRef(c *cache.Cache, arg string) bool {
+	found := false
+	for _, part := range strings.Split(arg, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !isHandle(part) {
+			matches, err := matchTaskRef(c, part, "")
+			if err != nil || len(matches) == 0 {
+				return false
+			}
+		}
+		found = true
+	}
+	return found
+}
+
+// matchTaskRef looks ref up in the cache: a handle, an exact ID or an ID
+// prefix of at least minIDPrefix characters. listID, when set, restricts
+// ID matches to that list. A nil result means ref is unknown.
+func matchTaskRef(c *cache.Cache, ref, listID string) ([]bulkTarget, error) {
+	if isHandle(ref) {
+		n, _ := strconv.Atoi(ref[1:])
+		id, handle, ok := c.Handles.Lookup(n)
+		if !ok {
+			return nil, fmt.Errorf("unknown task handle %s (handles are shown by list, next and search)", ref)
+		}
+		return []bulkTarget{{ListID: handle.ListID, ID: id, Title: handle.Title, Section: handle.Section}}, nil
+	}
+	index := buildTaskIndex(c)
+	inList := func(locations []bulkTarget) []bulkTarget {
+		if listID == "" {
+			return locations
+		}
+		kept := []bulkTarget{}
+		for _, location := range locations {
+			if location.ListID == listID {
+				kept = append(kept, location)
+			}
+		}
+		return kept
+	}
+	if locations := inList(index[ref]); len(locations) > 0 {
+		return locations, nil
+	}
+	if len(ref) < minIDPrefix {
+		return nil, nil
+	}
+	var matches []bulkTarget
+	for id, locations := range index {
+		if strings.HasPrefix(id, ref) {
+			matches = append(matches, inList(locations)...)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].ID != matches[j].ID {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].ListID < matches[j].ListID
+	})
+	return matches, nil
+}
+
+// probeTaskLists asks every configured list for taskID, for IDs the cache has
+// never seen.
+func probeTaskLists(app *App, taskID string) []bulkTarget {
+	names := listSearchOrder(app)
+	found := make([]*tasks.Task, len(names))
+	var wg stdsync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, listID string) {
+			defer wg.Done()
+			task, err := app.Tasks.GetTask(listID, taskID)
+			if err == nil && task != nil && !task.Deleted {
+				found[i] = task
+			}
+		}(i, app.Config.Lists[name])
+	}
+	wg.Wait()
+	matches := []bulkTarget{}
+	for i, task := range found {
+		if task == nil {
+			continue
+		}
+		listID := app.Config.Lists[names[i]]
+		matches = append(matches, bulkTarget{
+			ListName: names[i],
+			ListID:   listID,
+			ID:       task.Id,
+			Title:    task.Title,
+			Section:  sectionNameOf(app, listID, task),
+		})
+	}
+	return matches
+}
+
+// sectionNameOf returns the title of the section task belongs to, or "".
+func sectionNameOf(app *App, listID string, task *tasks.Task) string {
+	sectionID, ok := taskSectionRef.Get(task.Notes)
+	if !ok {
+		sectionID = task.Parent
+	}
+	if sectionID == "" {
+		return ""
+	}
+	section, err := app.Tasks.GetTask(listID, sectionID)
+	if err != nil || !isSectionTask(section) {
+		return ""
+	}
+	return section.Title
+}
+
+// listSearchOrder returns the configured list names, default list first.
+func listSearchOrder(app *App) []string {
+	names := []string{}
+	for name := range app.Config.Lists {
+		if name != app.Config.DefaultList {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := app.Config.Lists[app.Config.DefaultList]; ok {
+		names = append([]string{app.Config.DefaultList}, names...)
+	}
+	return names
+}
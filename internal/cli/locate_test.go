@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"justdoit/internal/cache"
+)
+
+func TestMatchTaskRef(t *testing.T) {
+	c := cache.Default()
+	now := time.Now()
+	c.Handles.Assign("abcd1111", "list-1", "", "Buy milk", now)
+	c.Handles.Assign("abcd2222", "list-2", "", "Call mom", now)
+	c.Tasks.Lists["list-2"] = map[string]cache.TaskEntry{"xyz98765": {ID: "xyz98765", Title: "Synced"}}
+
+	matches, err := matchTaskRef(c, "#2", "")
+	if err != nil || len(matches) != 1 || matches[0].ID != "abcd2222" || matches[0].ListID != "list-2" {
+		t.Fatalf("unexpected handle match %#v (%v)", matches, err)
+	}
+	if _, err := matchTaskRef(c, "#9", ""); err == nil || !strings.Contains(err.Error(), "unknown task handle") {
+		t.Fatalf("expected unknown handle error, got %v", err)
+	}
+	if matches, _ := matchTaskRef(c, "abcd", ""); len(matches) != 2 {
+		t.Fatalf("expected an ambiguous prefix, got %#v", matches)
+	}
+	if matches, _ := matchTaskRef(c, "abcd", "list-1"); len(matches) != 1 || matches[0].ID != "abcd1111" {
+		t.Fatalf("expected --list to narrow the prefix, got %#v", matches)
+	}
+	if matches, _ := matchTaskRef(c, "xyz98765", ""); len(matches) != 1 || matches[0].ListID != "list-2" {
+		t.Fatalf("expected synced task IDs to resolve, got %#v", matches)
+	}
+	if matches, _ := matchTaskRef(c, "abc", ""); matches != nil {
+		t.Fatalf("expected short prefixes to be ignored, got %#v", matches)
+	}
+}
+
+func TestMatchTaskRefFindsEveryListHoldingAnID(t *testing.T) {
+	c := cache.Default()
+	c.Handles.Assign("task-1", "list-1", "Errands", "Buy milk", time.Now())
+	c.Tasks.Lists["list-1"] = map[string]cache.TaskEntry{"task-1": {ID: "task-1", Title: "Buy milk"}}
+	c.Tasks.Lists["list-2"] = map[string]cache.TaskEntry{"task-1": {ID: "task-1", Title: "Buy milk"}}
+
+	matches, err := matchTaskRef(c, "task-1", "")
+	if err != nil || len(matches) != 2 {
+		t.Fatalf("expected the ID in two lists, got %#v (%v)", matches, err)
+	}
+	if matches[0].ListID != "list-1" || matches[0].Section != "Errands" {
+		t.Fatalf("expected the handle location first with its section, got %#v", matches[0])
+	}
+	if matches, _ := matchTaskRef(c, "task-1", "list-2"); len(matches) != 1 || matches[0].ListID != "list-2" {
+		t.Fatalf("expected --list to pick one location, got %#v", matches)
+	}
+}
+
+func TestSplitUpdateArgs(t *testing.T) {
+	c := cache.Default()
+	c.Handles.Assign("abcd1111", "list-1", "", "Buy milk", time.Now())
+	c.Tasks.Lists["list-1"] = map[string]cache.TaskEntry{"efgh2222": {ID: "efgh2222", Title: "Call mom"}}
+
+	ids, title, err := splitUpdateArgs(c, []string{"abcd1111", "Buy", "oat", "milk"})
+	if err != nil || len(ids) != 1 || ids[0] != "abcd1111" || title != "Buy oat milk" {
+		t.Fatalf("unexpected rename split %v %q (%v)", ids, title, err)
+	}
+	if _, title, err := splitUpdateArgs(c, []string{"#1", "Fix", "#2", "first"}); err != nil || title != "Fix #2 first" {
+		t.Fatalf("expected a handle inside the title to stay in it, got %q (%v)", title, err)
+	}
+	for _, args := range [][]string{{"abcd1111", "efgh2222"}, {"#1", "#2"}, {"#1", "efgh2222,#3"}} {
+		if _, _, err := splitUpdateArgs(c, args); err == nil || !strings.Contains(err.Error(), "--title") {
+			t.Fatalf("expected %v to be rejected as several tasks, got %v", args, err)
+		}
+	}
+	if _, title, err := splitUpdateArgs(c, []string{"abcd1111", "zzzz9999"}); err != nil || title != "zzzz9999" {
+		t.Fatalf("expected an unknown word to be the title, got %q (%v)", title, err)
+	}
+}
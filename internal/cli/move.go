@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 	"google.golang.org/api/tasks/v1"
 
-	"justdoit/internal/metadata"
 	"justdoit/internal/sync"
 )
 
@@ -16,112 +15,282 @@ func newMoveCmd() *cobra.Command {
 		fromList string
 		toList   string
 		section  string
+		after    string
+		first    bool
+		match    titleQuery
+		bulk     bulkOptions
 	)
 	cmd := &cobra.Command{
-		Use:   "move [taskID]",
-		Short: "Move a task to another list (and optional section)",
-		Args:  cobra.ExactArgs(1),
+		Use:   "move [taskID[,taskID...]]",
+		Short: "Move tasks to another list (and optional section), or reorder a task",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if !bulk.hasSource(args) && !match.isSet() {
+				return fmt.Errorf("requires [taskID], --match, --query or --stdin")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			app, err := initApp(cmd)
 			if err != nil {
 				return err
 			}
-			if strings.TrimSpace(toList) == "" {
-				return fmt.Errorf("--to is required")
+			reorder := strings.TrimSpace(after) != "" || first
+			if reorder && (toList != "" || cmd.Flags().Changed("section")) {
+				return fmt.Errorf("--after/--first cannot be combined with --to or --section")
 			}
-			fromListID, err := resolveListID(app, fromList, fromList != "")
-			if err != nil {
-				return err
+			if !reorder && strings.TrimSpace(toList) == "" {
+				return fmt.Errorf("--to, --after or --first is required")
+			}
+			var targets []bulkTarget
+			if bulk.hasSource(args) {
+				targets, err = collectTargets(app, fromList, args, bulk)
+			} else {
+				var target bulkTarget
+				target, err = resolveTaskByTitle(app, fromList, match, false)
+				targets = []bulkTarget{target}
 			}
-			toListID, err := resolveListID(app, toList, true)
 			if err != nil {
 				return err
 			}
-			taskID := args[0]
-			if fromListID == toListID {
-				params := UpdateParams{
-					Section:    section,
-					HasSection: cmd.Flags().Changed("section"),
+			if reorder {
+				if len(targets) != 1 {
+					return fmt.Errorf("--after/--first move a single task")
+				}
+				afterID := ""
+				if strings.TrimSpace(after) != "" {
+					afterRef, err := resolveTaskRef(app, "", after)
+					if err != nil {
+						return err
+					}
+					if afterRef.ListID != targets[0].ListID {
+						return fmt.Errorf("--after must name a task in the same list")
+					}
+					afterID = afterRef.ID
 				}
-				if _, err := updateTaskWithParams(app, fromListID, taskID, params); err != nil {
+				if err := recordOp(app, "reorder", targetScope(targets), func() error {
+					return moveTaskAfter(app, targets[0].ListID, targets[0].ID, afterID)
+				}); err != nil {
 					return err
 				}
 				fmt.Println("✅ Task moved")
 				return nil
 			}
-			if err := moveTaskToList(app, fromListID, toListID, taskID, section); err != nil {
+			toListID, err := resolveListID(app, toList, true)
+			if err != nil {
+				return err
+			}
+			hasSection := cmd.Flags().Changed("section")
+			move := func(target bulkTarget) ([]string, error) {
+				if target.ListID == toListID {
+					params := UpdateParams{Section: section, HasSection: hasSection}
+					_, err := updateTaskWithParams(app, target.ListID, target.ID, params)
+					return nil, err
+				}
+				return nil, moveTaskToList(app, target.ListID, toListID, target.ID, section)
+			}
+			if bulk.isBulk(targets) {
+				if !bulk.DryRun && strings.TrimSpace(section) != "" {
+					if _, err := ensureSectionTask(app, toListID, section); err != nil {
+						return err
+					}
+				}
+				return runBulk(app, targets, bulk, "move", "moved", targetScope(targets, toListID), move)
+			}
+			if err := recordOp(app, "move", targetScope(targets, toListID), func() error {
+				_, err := move(targets[0])
+				return err
+			}); err != nil {
 				return err
 			}
 			fmt.Println("✅ Task moved")
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&fromList, "list", "", "Source list name (mapped via config.json)")
+	cmd.Flags().StringVar(&fromList, "list", "", "Source list name (mapped via config.json); found from the task ID when omitted")
 	cmd.Flags().StringVar(&toList, "to", "", "Target list name (mapped via config.json)")
 	cmd.Flags().StringVar(&section, "section", "", "Target section name")
+	cmd.Flags().StringVar(&after, "after", "", "Place the task right after this task ID or #handle (same list)")
+	cmd.Flags().BoolVar(&first, "first", false, "Place the task first in its section (or among its sibling subtasks)")
+	cmd.Flags().StringVar(&match.Text, "match", "", "Pick the task by title, fuzzy matched across lists (alternative to taskID)")
+	cmd.Flags().BoolVar(&match.First, "first-match", false, "With --match, take the best match instead of asking")
+	cmd.Flags().BoolVar(&match.Strict, "strict", false, "With --match, only accept an exact title and never ask")
+	addBulkFlags(cmd, &bulk)
 	return cmd
 }
 
+// moveTaskAfter places taskID right after afterID, or first when afterID is
+// empty. Top-level tasks take the section of the task they follow; subtasks
+// can only be reordered under their own parent.
+func moveTaskAfter(app *App, listID, taskID, afterID string) error {
+	if taskID == afterID {
+		return fmt.Errorf("cannot move a task after itself")
+	}
+	items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+	if err != nil {
+		return err
+	}
+	index := buildSectionIndex(items)
+	byID := make(map[string]*tasks.Task, len(items))
+	for _, item := range items {
+		if item != nil {
+			byID[item.Id] = item
+		}
+	}
+	task, ok := byID[taskID]
+	if !ok {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if isSectionTask(task) {
+		return fmt.Errorf("use `section reorder` to move sections")
+	}
+	parentID := ""
+	if isSubtask(task, index) {
+		parentID = task.Parent
+	}
+	sectionID := sectionIDOf(task, index)
+	if afterID != "" {
+		after, ok := byID[afterID]
+		if !ok {
+			return fmt.Errorf("task not found: %s", afterID)
+		}
+		afterParent := ""
+		if isSubtask(after, index) {
+			afterParent = after.Parent
+		}
+		if isSectionTask(after) || afterParent != parentID {
+			return fmt.Errorf("%q and %q are not siblings", task.Title, after.Title)
+		}
+		if parentID == "" {
+			sectionID = sectionIDOf(after, index)
+			if err := promoteFromLegacySection(app, listID, after, index); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := app.Tasks.MoveTask(listID, taskID, parentID, afterID); err != nil {
+		return err
+	}
+	if parentID != "" {
+		return nil
+	}
+	notes := setNotesSection(task.Notes, sectionID)
+	if notes == task.Notes {
+		return nil
+	}
+	task.Notes = notes
+	task.Parent = ""
+	_, err = app.Tasks.UpdateTask(listID, task)
+	return err
+}
+
+// promoteFromLegacySection moves a task nested under a section task to the
+// top level, right after the section, recording the section in metadata.
+func promoteFromLegacySection(app *App, listID string, item *tasks.Task, index map[string]string) error {
+	if _, ok := index[item.Parent]; !ok {
+		return nil
+	}
+	sectionID := item.Parent
+	if _, err := app.Tasks.MoveTask(listID, item.Id, "", sectionID); err != nil {
+		return err
+	}
+	item.Parent = ""
+	item.Notes = setNotesSection(item.Notes, sectionID)
+	_, err := app.Tasks.UpdateTask(listID, item)
+	return err
+}
+
+// movePrevious returns the sibling an item must follow to land at index to
+// once it is taken out of from. "" means the first position.
+func movePrevious(ids []string, from, to int) string {
+	rest := make([]string, 0, len(ids))
+	for i, id := range ids {
+		if i != from {
+			rest = append(rest, id)
+		}
+	}
+	if to <= 0 || len(rest) == 0 {
+		return ""
+	}
+	if to > len(rest) {
+		to = len(rest)
+	}
+	return rest[to-1]
+}
+
 func moveTaskToList(app *App, fromListID, toListID, taskID, section string) error {
 	task, err := app.Tasks.GetTask(fromListID, taskID)
 	if err != nil {
 		return err
 	}
-	if _, ok := metadata.Extract(task.Notes, "justdoit_section"); ok {
+	if sectionMarker.Has(task.Notes) {
 		return fmt.Errorf("cannot move a section task")
 	}
-	rule, _ := metadata.Extract(task.Notes, "justdoit_rrule")
+	rule, _ := sync.TaskRRule.Get(task.Notes)
 
-	parentID := ""
+	// Section IDs are per list, so the old membership never carries over.
+	sectionID := ""
 	sectionName := strings.TrimSpace(section)
 	if sectionName != "" {
 		sectionTask, err := ensureSectionTask(app, toListID, sectionName)
 		if err != nil {
 			return err
 		}
-		parentID = sectionTask.Id
+		sectionID = sectionTask.Id
 	}
 
 	newTask := &tasks.Task{
-		Title: recurringTitle(task.Title, rule),
-		Notes: task.Notes,
-		Due:   task.Due,
-	}
-	var created *tasks.Task
-	if parentID != "" {
-		created, err = app.Tasks.CreateTaskWithParent(toListID, newTask, parentID)
-	} else {
-		created, err = app.Tasks.CreateTask(toListID, newTask)
+		Title:  recurringTitle(task.Title, rule),
+		Notes:  setNotesSection(task.Notes, sectionID),
+		Due:    task.Due,
+		Status: task.Status,
 	}
+	created, err := app.Tasks.CreateTask(toListID, newTask)
 	if err != nil {
 		return err
 	}
 
-	if eventID, ok := metadata.Extract(task.Notes, sync.TaskEventIDKey); ok && eventID != "" {
-		if event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID); err == nil && event != nil {
-			event.Description = replaceMetadata(event.Description, sync.EventTaskIDKey, created.Id)
-			if _, err := app.Calendar.UpdateEvent(app.Config.CalendarID, event); err != nil {
-				return err
-			}
+	// Deleting the source task also deletes its subtasks, so copy them first.
+	items, err := app.Tasks.ListTasksWithOptions(fromListID, true, true, false, "")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item == nil || item.Parent != taskID || item.Deleted {
+			continue
+		}
+		child := &tasks.Task{
+			Title:  item.Title,
+			Notes:  item.Notes,
+			Due:    item.Due,
+			Status: item.Status,
+		}
+		createdChild, err := app.Tasks.CreateTaskWithParent(toListID, child, created.Id)
+		if err != nil {
+			return err
+		}
+		if err := relinkEvent(app, item.Notes, createdChild.Id); err != nil {
+			return err
 		}
 	}
 
+	if err := relinkEvent(app, task.Notes, created.Id); err != nil {
+		return err
+	}
+
 	return app.Tasks.DeleteTask(fromListID, taskID)
 }
 
-func replaceMetadata(text, key, value string) string {
-	prefix := key + "="
-	lines := []string{}
-	for _, line := range strings.Split(text, "\n") {
-		trim := strings.TrimSpace(line)
-		if strings.HasPrefix(trim, prefix) {
-			continue
-		}
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		lines = append(lines, line)
+// relinkEvent points the event linked from notes at a task's new ID.
+func relinkEvent(app *App, notes, taskID string) error {
+	eventID, ok := sync.TaskEventID.Get(notes)
+	if !ok || eventID == "" {
+		return nil
+	}
+	event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID)
+	if err != nil || event == nil {
+		return nil
 	}
-	clean := strings.TrimSpace(strings.Join(lines, "\n"))
-	return metadata.Append(clean, key, value)
+	event.Description = sync.EventTaskID.Set(event.Description, taskID)
+	_, err = app.Calendar.UpdateEvent(app.Config.CalendarID, event)
+	return err
 }
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMovePrevious(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	cases := []struct {
+		from, to int
+		want     string
+	}{
+		{from: 1, to: 0, want: ""},
+		{from: 0, to: 1, want: "b"},
+		{from: 2, to: 1, want: "a"},
+		{from: 0, to: 2, want: "c"},
+	}
+	for _, tc := range cases {
+		if got := movePrevious(ids, tc.from, tc.to); got != tc.want {
+			t.Fatalf("movePrevious(%d, %d) = %q, want %q", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestSortTaskRowsManualUsesPositions(t *testing.T) {
+	rows := []taskRow{
+		{ID: "b", Priority: 1, Position: "00000000000000000002"},
+		{ID: "a", Position: "00000000000000000001"},
+		{ID: "c", Position: "00000000000000000003"},
+	}
+	ids := func(rows []taskRow) []string {
+		out := []string{}
+		for _, row := range rows {
+			out = append(out, row.ID)
+		}
+		return out
+	}
+	if got := ids(sortTaskRows(rows, true)); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected manual order %v", got)
+	}
+	if got := ids(sortTaskRows(rows, false)); got[0] != "b" {
+		t.Fatalf("expected smart order to put the prioritized task first, got %v", got)
+	}
+}
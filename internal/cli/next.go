@@ -6,22 +6,45 @@ import (
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/spf13/cobra"
+
+	"justdoit/internal/paths"
 )
 
 func newNextCmd() *cobra.Command {
 	var (
 		includeBacklog bool
 		ids            bool
+		hideBlocked    bool
+		deferred       bool
+		profiles       []string
+		allProfiles    bool
 	)
 	cmd := &cobra.Command{
 		Use:   "next",
 		Short: "Show your next tasks (overdue/today/this week/next week)",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if allProfiles {
+				all, err := paths.Profiles()
+				if err != nil {
+					return err
+				}
+				profiles = all
+			}
+			if len(profiles) > 0 {
+				return printProfilesNext(cmd, profiles, includeBacklog, hideBlocked, deferred, ids)
+			}
 			app, err := initApp(cmd)
 			if err != nil {
 				return err
 			}
-			items, err := buildNextItems(newQueryContext(app), includeBacklog)
+			ctx := newQueryContext(app)
+			ctx.HideBlocked = hideBlocked
+			var items []list.Item
+			if deferred {
+				items, err = buildDeferredItems(ctx)
+			} else {
+				items, err = buildNextItems(ctx, includeBacklog)
+			}
 			if err != nil {
 				return err
 			}
@@ -29,16 +52,21 @@ func newNextCmd() *cobra.Command {
 				fmt.Println("(no tasks)")
 				return nil
 			}
-			printNextItems(items, ids)
-			return nil
+			handles := loadHandleBook(app)
+			printNextItems(items, handles, ids)
+			return handles.save()
 		},
 	}
 	cmd.Flags().BoolVar(&includeBacklog, "backlog", true, "Include backlog tasks without due date")
 	cmd.Flags().BoolVar(&ids, "ids", false, "Show task IDs")
+	cmd.Flags().BoolVar(&hideBlocked, "hide-blocked", false, "Hide tasks that are waiting on other tasks")
+	cmd.Flags().BoolVar(&deferred, "deferred", false, "Show deferred tasks (start date still ahead) instead")
+	cmd.Flags().StringSliceVar(&profiles, "profiles", nil, "Merge the next tasks of these profiles, labeling each task with its profile")
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Merge the next tasks of every profile")
 	return cmd
 }
 
-func printNextItems(items []list.Item, showIDs bool) {
+func printNextItems(items []list.Item, handles *handleBook, showIDs bool) {
 	currentHeader := ""
 	for _, it := range items {
 		switch v := it.(type) {
@@ -51,6 +79,18 @@ func printNextItems(items []list.Item, showIDs bool) {
 				}
 				continue
 			}
+			idText := ""
+			if showIDs {
+				idText = " [id: " + v.ID + "]"
+			}
+			profile := ""
+			if v.Profile != "" {
+				profile = gray("["+v.Profile+"]") + " "
+			}
+			if v.ParentTaskID != "" {
+				fmt.Printf("  - %s%s%s%s\n", profile, handles.label(v.ListID, v.Section, v.ID, v.TitleVal), priorityTitle(v.TitleVal, v.Priority), idText)
+				continue
+			}
 			contextParts := []string{}
 			if strings.TrimSpace(v.Section) != "" && v.Section != "General" {
 				contextParts = append(contextParts, v.Section)
@@ -69,12 +109,12 @@ func printNextItems(items []list.Item, showIDs bool) {
 					due = " (due " + formatted + ")"
 				}
 			}
-			idText := ""
-			if showIDs {
-				idText = " [id: " + v.ID + "]"
-			}
 			title := recurringTitle(v.TitleVal, v.Recurrence)
-			fmt.Printf("- %s%s%s%s\n", title, context, due, idText)
+			if v.Blocked {
+				title = gray("⛔ " + title)
+			}
+			title = priorityTitle(title, v.Priority)
+			fmt.Printf("- %s%s%s%s%s%s%s%s\n", profile, handles.label(v.ListID, v.Section, v.ID, v.TitleVal), title, progressSuffix(v.SubtasksDone, v.SubtasksTotal), tagsSuffix(v.Tags), context, due, idText)
 		case calendarEventItem:
 			// If TUI included events but somehow no Today header made it through,
 			// render a Today header to keep the output readable.
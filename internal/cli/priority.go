@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"justdoit/internal/metadata"
+)
+
+const maxPriority = 4
+
+var taskPriority = metadata.Int("justdoit_priority")
+
+var priorityColors = map[int]lipgloss.Color{
+	1: lipgloss.Color("203"),
+	2: lipgloss.Color("214"),
+	3: lipgloss.Color("39"),
+	4: lipgloss.Color("245"),
+}
+
+var priorityANSI = map[int]string{
+	1: "\033[31m",
+	2: "\033[33m",
+	3: "\033[34m",
+	4: colorGray,
+}
+
+type priorityMsg struct {
+	priority int
+	err      error
+}
+
+// parsePriority accepts p1..p4, 1..4 and !1..!4. "none" and "0" clear the
+// priority.
+func parsePriority(value string) (int, error) {
+	raw := strings.ToLower(strings.TrimSpace(value))
+	switch raw {
+	case "", "none", "0", "p0":
+		return 0, nil
+	}
+	raw = strings.TrimPrefix(strings.TrimPrefix(raw, "!"), "p")
+	p, err := strconv.Atoi(raw)
+	if err != nil || p < 1 || p > maxPriority {
+		return 0, fmt.Errorf("invalid priority: %s (use p1-p4 or none)", value)
+	}
+	return p, nil
+}
+
+func notesPriority(notes string) int {
+	p, ok := taskPriority.Get(notes)
+	if !ok || p < 1 || p > maxPriority {
+		return 0
+	}
+	return p
+}
+
+func setNotesPriority(notes string, p int) string {
+	if p < 1 || p > maxPriority {
+		return taskPriority.Remove(notes)
+	}
+	return taskPriority.Set(notes, p)
+}
+
+// priorityRank orders prioritized tasks first; tasks without a priority sort
+// after p4.
+func priorityRank(p int) int {
+	if p < 1 || p > maxPriority {
+		return maxPriority + 1
+	}
+	return p
+}
+
+func nextPriority(p int) int {
+	if p >= maxPriority || p < 0 {
+		return 0
+	}
+	return p + 1
+}
+
+func priorityLabel(p int) string {
+	if p < 1 || p > maxPriority {
+		return ""
+	}
+	return fmt.Sprintf("p%d", p)
+}
+
+// priorityTitle prefixes title with a colored marker for terminal output.
+func priorityTitle(title string, p int) string {
+	label := priorityLabel(p)
+	if label == "" {
+		return title
+	}
+	if useColor() {
+		label = priorityANSI[p] + label + colorReset
+	}
+	return label + " " + title
+}
+
+// priorityStyledTitle prefixes title with a colored marker for the TUI.
+func priorityStyledTitle(title string, p int) string {
+	label := priorityLabel(p)
+	if label == "" {
+		return title
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(priorityColors[p]).Render(label) + " " + title
+}
+
+func (m tuiModel) setPriorityCmd(task taskItem, p int) tea.Cmd {
+	return func() tea.Msg {
+		err := recordOp(m.app, "priority", taskScope(task.ListID, task.ID), func() error {
+			_, err := updateTaskWithParams(m.app, task.ListID, task.ID, UpdateParams{
+				Priority:    p,
+				HasPriority: true,
+			})
+			return err
+		})
+		return priorityMsg{priority: p, err: err}
+	}
+}
+
+func (m *tuiModel) cyclePriority(task taskItem, ok bool) tea.Cmd {
+	if !ok || task.ID == "" {
+		m.status = "Select a task to prioritize"
+		return nil
+	}
+	return m.setPriorityCmd(task, nextPriority(task.Priority))
+}
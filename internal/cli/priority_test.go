@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestParsePriority(t *testing.T) {
+	cases := map[string]int{"p1": 1, "P2": 2, "3": 3, "!4": 4, "none": 0, "": 0}
+	for input, want := range cases {
+		got, err := parsePriority(input)
+		if err != nil {
+			t.Fatalf("parsePriority(%q) error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("parsePriority(%q) = %d, want %d", input, got, want)
+		}
+	}
+	if _, err := parsePriority("p5"); err == nil {
+		t.Fatalf("expected error for p5")
+	}
+}
+
+func TestBuildNextItemsSortsByPriorityWithinBucket(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, loc)
+	listID := "list-1"
+	due := time.Date(2026, 1, 2, 12, 0, 0, 0, loc)
+
+	items := []*tasks.Task{
+		{Id: "1", Title: "Earliest", Status: "needsAction", Due: due.Add(-time.Hour).Format(time.RFC3339)},
+		{Id: "2", Title: "Low", Status: "needsAction", Due: due.Format(time.RFC3339), Notes: setNotesPriority("", 4)},
+		{Id: "3", Title: "Urgent", Status: "needsAction", Due: due.Format(time.RFC3339), Notes: setNotesPriority("", 1)},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{listID: items}},
+		Lists:    map[string]string{"Work": listID},
+		Location: loc,
+		Now:      func() time.Time { return now },
+	}
+
+	result, err := buildNextItems(ctx, false)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	got := []string{}
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && !task.IsHeader {
+			got = append(got, task.ID)
+		}
+	}
+	if len(got) != 3 || got[0] != "3" || got[1] != "2" || got[2] != "1" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
+
+func TestOrderTaskRowsUsesPriority(t *testing.T) {
+	rows := []taskRow{
+		{ID: "a", Index: 0},
+		{ID: "b", Index: 1, Priority: 2},
+		{ID: "c", Index: 2, Priority: 1},
+	}
+	ordered := orderTaskRows(rows)
+	if ordered[0].ID != "c" || ordered[1].ID != "b" || ordered[2].ID != "a" {
+		t.Fatalf("unexpected order: %v", ordered)
+	}
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/spf13/cobra"
+
+	"justdoit/internal/config"
+	"justdoit/internal/paths"
+)
+
+func newProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles (one Google account each)",
+	}
+	cmd.AddCommand(newProfileListCmd())
+	cmd.AddCommand(newProfileAddCmd())
+	cmd.AddCommand(newProfileUseCmd())
+	return cmd
+}
+
+func newProfileListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List profiles; * marks the active one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := paths.Profiles()
+			if err != nil {
+				return err
+			}
+			active := paths.Profile()
+			for _, name := range profiles {
+				dir, err := paths.ProfileDir(name)
+				if err != nil {
+					return err
+				}
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s %s\n", marker, name, gray(dir))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newProfileAddCmd() *cobra.Command {
+	var use bool
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a profile with its own config, token and cache",
+		Long: "Create a profile with its own config, token and cache.\n" +
+			"Pass --credentials to give it its own OAuth client; otherwise it shares the default credentials.json.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(args[0])
+			if err := paths.ValidateProfile(name); err != nil {
+				return err
+			}
+			if name == paths.DefaultProfile {
+				return fmt.Errorf("the %s profile always exists", paths.DefaultProfile)
+			}
+			files, err := paths.ForProfile(name)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(files.Dir); err == nil {
+				return fmt.Errorf("profile %s already exists: %s", name, files.Dir)
+			}
+			if err := os.MkdirAll(files.Dir, 0o700); err != nil {
+				return err
+			}
+			if err := config.Save(files.Config, config.Default()); err != nil {
+				return err
+			}
+			if credPath, _ := cmd.Flags().GetString("credentials"); credPath != "" {
+				if err := copyCredentials(credPath, filepath.Join(files.Dir, filepath.Base(files.Credentials))); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Created profile %s: %s\n", name, files.Dir)
+			if use {
+				if err := paths.SetCurrentProfile(name); err != nil {
+					return err
+				}
+				fmt.Printf("Now using profile %s\n", name)
+			}
+			fmt.Printf("Next: justdoit --profile %s auth login, then justdoit --profile %s setup\n", name, name)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&use, "use", false, "Make the new profile the active one")
+	return cmd
+}
+
+func newProfileUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Use a profile when --profile and JUSTDOIT_PROFILE are not set",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(args[0])
+			profiles, err := paths.Profiles()
+			if err != nil {
+				return err
+			}
+			found := false
+			for _, profile := range profiles {
+				found = found || profile == name
+			}
+			if !found {
+				return fmt.Errorf("unknown profile %q (create it with: justdoit profile add %s)", name, name)
+			}
+			if err := paths.SetCurrentProfile(name); err != nil {
+				return err
+			}
+			fmt.Printf("Now using profile %s\n", name)
+			if env := os.Getenv("JUSTDOIT_PROFILE"); env != "" && env != name {
+				fmt.Printf("Note: JUSTDOIT_PROFILE=%s is set and takes precedence.\n", env)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func copyCredentials(src, dst string) error {
+	// #nosec G304 -- credentials path is user-provided
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
+
+// printProfilesNext prints one Next view merged from several profiles. Task
+// handles belong to a single profile's cache, so none are shown here.
+func printProfilesNext(cmd *cobra.Command, profiles []string, includeBacklog, hideBlocked, deferred, showIDs bool) error {
+	groups := [][]list.Item{}
+	for _, profile := range profiles {
+		profile = strings.TrimSpace(profile)
+		if profile == "" {
+			continue
+		}
+		app, err := initProfileApp(cmd, profile)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
+		}
+		ctx := newQueryContext(app)
+		ctx.HideBlocked = hideBlocked
+		var items []list.Item
+		if deferred {
+			items, err = buildDeferredItems(ctx)
+		} else {
+			items, err = buildNextItems(ctx, includeBacklog)
+		}
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
+		}
+		groups = append(groups, labelProfileItems(items, profile))
+	}
+	if len(groups) == 0 {
+		return errors.New("no profiles given")
+	}
+	if deferred {
+		printNextItems(mergeDeferredItems(groups), nil, showIDs)
+		return nil
+	}
+	printNextItems(mergeNextItems(groups), nil, showIDs)
+	return nil
+}
+
+func labelProfileItems(items []list.Item, profile string) []list.Item {
+	labeled := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case taskItem:
+			if !v.IsHeader {
+				v.Profile = profile
+			}
+			item = v
+		case calendarEventItem:
+			v.Profile = profile
+			item = v
+		}
+		labeled = append(labeled, item)
+	}
+	return labeled
+}
+
+// nextSections is the order of the Next view's headers.
+var nextSections = []string{"Overdue", "Today", "This week", "Next week", "Backlog (no date)"}
+
+// mergeNextItems joins Next views section by section, keeping each profile's
+// own order within a section. Today is ordered by time again, as
+// buildNextItems orders it.
+func mergeNextItems(groups [][]list.Item) []list.Item {
+	sections := map[string][]list.Item{}
+	for _, items := range groups {
+		header := ""
+		for _, item := range items {
+			if v, ok := item.(taskItem); ok && v.IsHeader {
+				header = v.TitleVal
+				continue
+			}
+			sections[header] = append(sections[header], item)
+		}
+	}
+	merged := []list.Item{}
+	for _, header := range nextSections {
+		if len(sections[header]) == 0 {
+			continue
+		}
+		merged = append(merged, taskItem{TitleVal: header, IsHeader: true})
+		if header == "Today" {
+			merged = append(merged, sortTodayItems(sections[header])...)
+			continue
+		}
+		merged = append(merged, sections[header]...)
+	}
+	if len(merged) == 0 {
+		merged = append(merged, taskItem{TitleVal: "(no pending tasks)", IsHeader: true})
+	}
+	return merged
+}
+
+// sortTodayItems orders a Today section with sortTodayEntries, keeping
+// subtasks under their parent.
+func sortTodayItems(items []list.Item) []list.Item {
+	entries := []todayEntry{}
+	for _, item := range items {
+		if v, ok := item.(taskItem); ok && v.ParentTaskID != "" && len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			last.children = append(last.children, item)
+			continue
+		}
+		entries = append(entries, newTodayEntry(item))
+	}
+	sortTodayEntries(entries)
+	sorted := make([]list.Item, 0, len(items))
+	for _, entry := range entries {
+		sorted = append(sorted, entry.item)
+		sorted = append(sorted, entry.children...)
+	}
+	return sorted
+}
+
+// mergeDeferredItems joins deferred views into one, grouped by start day.
+func mergeDeferredItems(groups [][]list.Item) []list.Item {
+	deferred := []taskItem{}
+	for _, items := range groups {
+		for _, item := range items {
+			if v, ok := item.(taskItem); ok && !v.IsHeader {
+				deferred = append(deferred, v)
+			}
+		}
+	}
+	return groupDeferredItems(deferred)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestMergeNextItemsGroupsSectionsAcrossProfiles(t *testing.T) {
+	work := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Today", IsHeader: true},
+		taskItem{ID: "w1", TitleVal: "Standup"},
+		taskItem{TitleVal: "Backlog (no date)", IsHeader: true},
+		taskItem{ID: "w2", TitleVal: "Refactor"},
+	}, "work")
+	home := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Overdue", IsHeader: true},
+		taskItem{ID: "h1", TitleVal: "Pay rent"},
+		taskItem{TitleVal: "Today", IsHeader: true},
+		taskItem{ID: "h2", TitleVal: "Groceries"},
+	}, "home")
+
+	merged := mergeNextItems([][]list.Item{work, home})
+	want := []string{"Overdue", "h1:home", "Today", "h2:home", "w1:work", "Backlog (no date)", "w2:work"}
+	if len(merged) != len(want) {
+		t.Fatalf("got %d items, want %d", len(merged), len(want))
+	}
+	for i, item := range merged {
+		v := item.(taskItem)
+		got := v.TitleVal
+		if !v.IsHeader {
+			got = v.ID + ":" + v.Profile
+		}
+		if got != want[i] {
+			t.Fatalf("item %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestMergeNextItemsEmpty(t *testing.T) {
+	merged := mergeNextItems([][]list.Item{{taskItem{TitleVal: "(no pending tasks)", IsHeader: true}}})
+	if len(merged) != 1 || merged[0].(taskItem).TitleVal != "(no pending tasks)" {
+		t.Fatalf("unexpected merge result: %#v", merged)
+	}
+}
+
+func TestMergeNextItemsSortsTodayByTime(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC) }
+	work := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Today", IsHeader: true},
+		taskItem{ID: "w1", TitleVal: "Standup", Due: at(10), HasDue: true, HasTime: true},
+		taskItem{ID: "w1a", TitleVal: "Notes", ParentTaskID: "w1"},
+		taskItem{ID: "w2", TitleVal: "Review", Due: at(15), HasDue: true, HasTime: true},
+	}, "work")
+	home := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Today", IsHeader: true},
+		calendarEventItem{ID: "ev", Summary: "Gym", Start: at(7)},
+		taskItem{ID: "h1", TitleVal: "Dentist", Due: at(9), HasDue: true, HasTime: true},
+		taskItem{ID: "h2", TitleVal: "Groceries"},
+	}, "home")
+
+	got := []string{}
+	for _, item := range mergeNextItems([][]list.Item{work, home}) {
+		switch v := item.(type) {
+		case taskItem:
+			got = append(got, v.TitleVal)
+		case calendarEventItem:
+			got = append(got, v.Summary)
+		}
+	}
+	want := []string{"Today", "Groceries", "Gym", "Dentist", "Standup", "Notes", "Review"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMergeDeferredItemsGroupsByStartDay(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	work := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Starts Wed 2026-03-04", IsHeader: true},
+		taskItem{ID: "w1", TitleVal: "Plan", Start: day(4)},
+	}, "work")
+	home := labelProfileItems([]list.Item{
+		taskItem{TitleVal: "Starts Mon 2026-03-02", IsHeader: true},
+		taskItem{ID: "h1", TitleVal: "Taxes", Start: day(2)},
+		taskItem{TitleVal: "Starts Wed 2026-03-04", IsHeader: true},
+		taskItem{ID: "h2", TitleVal: "Garden", Start: day(4)},
+	}, "home")
+
+	got := []string{}
+	for _, item := range mergeDeferredItems([][]list.Item{work, home}) {
+		v := item.(taskItem)
+		label := v.TitleVal
+		if !v.IsHeader {
+			label = v.ID + ":" + v.Profile
+		}
+		got = append(got, label)
+	}
+	want := []string{"Starts Mon 2026-03-02", "h1:home", "Starts Wed 2026-03-04", "h2:home", "w1:work"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
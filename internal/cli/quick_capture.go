@@ -17,12 +17,15 @@ type quickCaptureMsg struct {
 }
 
 type quickCaptureInput struct {
-	Title   string
-	List    string
-	Section string
-	Date    string
-	Time    string
-	Every   string
+	Title    string
+	List     string
+	Section  string
+	Date     string
+	Time     string
+	Every    string
+	Priority int
+	Tags     []string
+	Start    time.Time
 }
 
 func (m tuiModel) quickCaptureCmd(line string) tea.Cmd {
@@ -63,13 +66,13 @@ func createFromQuickCapture(app *App, line string, now time.Time, loc *time.Loca
 	}
 
 	sectionName := strings.TrimSpace(input.Section)
-	parentID := ""
+	sectionID := ""
 	if sectionName != "" {
 		sectionTask, err := ensureSectionTask(app, listID, sectionName)
 		if err != nil {
 			return err
 		}
-		parentID = sectionTask.Id
+		sectionID = sectionTask.Id
 	}
 
 	baseDate, err := timeparse.ParseDate(input.Date, now, loc)
@@ -101,15 +104,17 @@ func createFromQuickCapture(app *App, line string, now time.Time, loc *time.Loca
 	createInput := sync.CreateInput{
 		ListID:     listID,
 		Title:      title,
-		Notes:      "",
+		Notes:      setNotesStartDate(setNotesSection(setNotesTags(setNotesPriority("", input.Priority), input.Tags, nil), sectionID), input.Start),
 		Due:        due,
 		Recurrence: recurrences,
 		TimeStart:  start,
 		TimeEnd:    end,
-		ParentID:   parentID,
+		ColorID:    tagEventColor(app.Config, input.Tags),
 	}
-	_, _, err = app.Sync.Create(createInput)
-	return err
+	return recordOp(app, "add", taskScope(listID), func() error {
+		_, _, err := app.Sync.Create(createInput)
+		return err
+	})
 }
 
 func parseQuickCapture(line string, now time.Time, loc *time.Location) (quickCaptureInput, error) {
@@ -131,9 +136,23 @@ func parseQuickCapture(line string, now time.Time, loc *time.Location) (quickCap
 		case strings.HasPrefix(token, "every:"):
 			input.Every = strings.TrimSpace(strings.TrimPrefix(token, "every:"))
 			continue
+		case isPriorityToken(token):
+			input.Priority, _ = parsePriority(token)
+			continue
+		case isTagToken(token):
+			tag, _ := normalizeTag(token)
+			input.Tags = append(input.Tags, tag)
+			continue
 		case strings.HasPrefix(token, "#") && len(token) > 1:
 			input.List = strings.TrimSpace(strings.TrimPrefix(token, "#"))
 			continue
+		case strings.HasPrefix(token, "^") && len(token) > 1:
+			if day, err := parseDeferInput(token[1:], now, loc); err == nil {
+				input.Start = day
+				continue
+			}
+			titleParts = append(titleParts, token)
+			continue
 		case strings.HasPrefix(token, "::") && len(token) > 2:
 			input.Section = strings.TrimSpace(strings.TrimPrefix(token, "::"))
 			continue
@@ -167,6 +186,10 @@ func parseQuickCapture(line string, now time.Time, loc *time.Location) (quickCap
 	return input, nil
 }
 
+func isPriorityToken(token string) bool {
+	return len(token) == 2 && token[0] == '!' && token[1] >= '1' && token[1] <= '4'
+}
+
 func splitQuickCapture(input string) []string {
 	tokens := []string{}
 	var buf strings.Builder
@@ -33,5 +33,40 @@ func TestParseQuickCapture(t *testing.T) {
 	}
 }
 
+func TestParseQuickCapturePriority(t *testing.T) {
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC)
+	parsed, err := parseQuickCapture("Pay rent !1 #Home", now, time.UTC)
+	if err != nil {
+		t.Fatalf("parseQuickCapture error: %v", err)
+	}
+	if parsed.Title != "Pay rent" || parsed.Priority != 1 {
+		t.Fatalf("unexpected parse: %#v", parsed)
+	}
+	parsed, err = parseQuickCapture("Shout !9", now, time.UTC)
+	if err != nil {
+		t.Fatalf("parseQuickCapture error: %v", err)
+	}
+	if parsed.Title != "Shout !9" || parsed.Priority != 0 {
+		t.Fatalf("expected !9 to stay in the title, got %#v", parsed)
+	}
+}
+
+func TestParseQuickCaptureStartDate(t *testing.T) {
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC) // Saturday
+	parsed, err := parseQuickCapture(`Plan trip ^monday @friday`, now, time.UTC)
+	if err != nil {
+		t.Fatalf("parseQuickCapture error: %v", err)
+	}
+	if parsed.Title != "Plan trip" || parsed.Date != "friday" {
+		t.Fatalf("unexpected parse %#v", parsed)
+	}
+	if !parsed.Start.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected start on monday, got %v", parsed.Start)
+	}
+	if parsed, _ := parseQuickCapture(`Remap the ^ key`, now, time.UTC); parsed.Title != "Remap the ^ key" || !parsed.Start.IsZero() {
+		t.Fatalf("expected a bare ^ to stay in the title, got %#v", parsed)
+	}
+}
+
 // Note: some "@token" values may be parsed as dates by naturaldate, so we keep
 // tests focused on the explicit token formats.
@@ -2,7 +2,12 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -17,13 +22,19 @@ import (
 )
 
 type App struct {
+	Profile    string
 	Config     *config.Config
 	ConfigPath string
 	CachePath  string
-	Tasks      *tasks.Client
-	Calendar   *calendar.Client
-	Sync       *sync.Wrapper
-	Location   *time.Location
+	// HistoryPath is where undoable operations are logged; empty disables it.
+	HistoryPath string
+	Tasks       *tasks.Client
+	Calendar    *calendar.Client
+	Sync        *sync.Wrapper
+	Location    *time.Location
+	// overrides are the env and flag values layered over config.json; they
+	// are never saved.
+	overrides []config.Override
 }
 
 // Now returns the current time in the app's configured location.
@@ -36,6 +47,12 @@ func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "justdoit",
 		Short: "CLI for time-blocking with Google Tasks + Calendar",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+				return paths.SetProfile(profile)
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			app, err := initApp(cmd)
 			if err != nil {
@@ -44,60 +61,104 @@ func NewRootCmd() *cobra.Command {
 			return startTUI(app)
 		},
 	}
+	cmd.PersistentFlags().String("profile", "", "Profile (account) to use; defaults to $JUSTDOIT_PROFILE or the one chosen with \"profile use\"")
 	cmd.PersistentFlags().String("config", "", "Path to config.json (defaults to ~/.config/justdoit/config.json)")
 	cmd.PersistentFlags().String("credentials", "", "Path to OAuth credentials.json (defaults to ~/.config/justdoit/credentials.json)")
+	for _, field := range config.Fields {
+		if field.Layered {
+			cmd.PersistentFlags().String(field.FlagName(), "", fmt.Sprintf("%s for this run (env %s)", field.Help, field.EnvName()))
+		}
+	}
 
 	cmd.AddCommand(newAddCmd())
 	cmd.AddCommand(newConfigCmd())
 	cmd.AddCommand(newDeleteCmd())
 	cmd.AddCommand(newDoneCmd())
 	cmd.AddCommand(newUndoCmd())
+	cmd.AddCommand(newRedoCmd())
+	cmd.AddCommand(newHistoryCmd())
 	cmd.AddCommand(newMoveCmd())
+	cmd.AddCommand(newSnoozeCmd())
 	cmd.AddCommand(newNextCmd())
 	cmd.AddCommand(newUpdateCmd())
 	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newSearchCmd())
 	cmd.AddCommand(newSectionCmd())
+	cmd.AddCommand(newTagsCmd())
+	cmd.AddCommand(newBlockCmd())
+	cmd.AddCommand(newDepsCmd())
 	cmd.AddCommand(newViewCmd())
 	cmd.AddCommand(newSetupCmd())
+	cmd.AddCommand(newAuthCmd())
+	cmd.AddCommand(newProfileCmd())
+	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newImportCmd())
 
 	return cmd
 }
 
 func initApp(cmd *cobra.Command) (*App, error) {
-	cfgPath, _ := cmd.Flags().GetString("config")
-	if cfgPath == "" {
-		var err error
-		cfgPath, err = paths.ConfigPath()
-		if err != nil {
-			return nil, err
-		}
-	}
-	cfg, err := config.LoadOrCreate(cfgPath)
+	return initProfileApp(cmd, paths.Profile())
+}
+
+// initSavedApp is initApp for unattended commands: it uses the saved token
+// and fails instead of starting a login.
+func initSavedApp(cmd *cobra.Command) (*App, error) {
+	return openProfileApp(cmd, paths.Profile(), false)
+}
+
+// initProfileApp signs in to profile's account. --config and --credentials
+// only apply to the active profile.
+func initProfileApp(cmd *cobra.Command, profile string) (*App, error) {
+	return openProfileApp(cmd, profile, true)
+}
+
+func openProfileApp(cmd *cobra.Command, profile string, signIn bool) (*App, error) {
+	files, err := paths.ForProfile(profile)
 	if err != nil {
 		return nil, err
 	}
-	loc, err := timeparse.LoadLocation(cfg.Timezone)
+	if profile == paths.Profile() {
+		if cfgPath, _ := cmd.Flags().GetString("config"); cfgPath != "" {
+			files.Config = cfgPath
+		}
+		if credPath, _ := cmd.Flags().GetString("credentials"); credPath != "" {
+			files.Credentials = credPath
+		}
+	}
+	cfg, err := config.LoadOrCreate(files.Config)
 	if err != nil {
 		return nil, err
 	}
-	credPath, _ := cmd.Flags().GetString("credentials")
-	if credPath == "" {
-		credPath, err = paths.CredentialsPath()
+	var overrides []config.Override
+	if profile == paths.Profile() {
+		overrides, err = config.ApplyOverrides(cfg, configOverrides(cmd))
 		if err != nil {
 			return nil, err
 		}
 	}
-	tokenPath, err := paths.TokenPath()
+	if cfg.CachePath != "" {
+		files.Cache = expandHome(cfg.CachePath)
+	}
+	loc, err := timeparse.LoadLocation(cfg.Timezone)
 	if err != nil {
 		return nil, err
 	}
-	cachePath, err := paths.CachePath()
+	store, err := auth.OpenTokenStore(cfg.TokenStore, files.Token)
 	if err != nil {
 		return nil, err
 	}
 	ctx := context.Background()
-	httpClient, err := auth.Client(ctx, credPath, tokenPath)
+	var httpClient *http.Client
+	if signIn {
+		httpClient, err = auth.Client(ctx, files.Credentials, store)
+	} else {
+		httpClient, err = auth.SavedClient(ctx, files.Credentials, store)
+		if errors.Is(err, auth.ErrNotSignedIn) || errors.Is(err, auth.ErrReauthRequired) {
+			return nil, err
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("auth failed: %w", err)
 	}
@@ -115,19 +176,46 @@ func initApp(cmd *cobra.Command) (*App, error) {
 		CalendarID: cfg.CalendarID,
 	}
 	return &App{
-		Config:     cfg,
-		ConfigPath: cfgPath,
-		CachePath:  cachePath,
-		Tasks:      tasksClient,
-		Calendar:   calendarClient,
-		Sync:       syncer,
-		Location:   loc,
+		Profile:     profile,
+		Config:      cfg,
+		ConfigPath:  files.Config,
+		CachePath:   files.Cache,
+		HistoryPath: files.History,
+		Tasks:       tasksClient,
+		Calendar:    calendarClient,
+		Sync:        syncer,
+		Location:    loc,
+		overrides:   overrides,
 	}, nil
 }
 
+// configOverrides returns the layers above config.json: JUSTDOIT_* variables,
+// then global flags.
+func configOverrides(cmd *cobra.Command) []config.Override {
+	overrides := config.EnvOverrides(os.Getenv)
+	for _, field := range config.Fields {
+		if !field.Layered {
+			continue
+		}
+		if flag := cmd.Flags().Lookup(field.FlagName()); flag != nil && flag.Changed {
+			overrides = append(overrides, config.Override{Key: field.Key, Value: flag.Value.String(), Source: "flag --" + field.FlagName()})
+		}
+	}
+	return overrides
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
 func (a *App) SaveConfig() error {
 	if a == nil || a.Config == nil || a.ConfigPath == "" {
 		return fmt.Errorf("config is not initialized")
 	}
-	return config.Save(a.ConfigPath, a.Config)
+	return config.Save(a.ConfigPath, config.WithoutOverrides(a.Config, a.overrides))
 }
@@ -31,8 +31,9 @@ func newSearchCmd() *cobra.Command {
 				fmt.Println("(no results)")
 				return nil
 			}
-			printSearchResults(results, list == "", ids)
-			return nil
+			handles := loadHandleBook(app)
+			printSearchResults(results, handles, list == "", ids)
+			return handles.save()
 		},
 	}
 	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
@@ -41,9 +42,9 @@ func newSearchCmd() *cobra.Command {
 	return cmd
 }
 
-func printSearchResults(results []taskItem, showList bool, showIDs bool) {
+func printSearchResults(results []taskItem, handles *handleBook, showList bool, showIDs bool) {
 	for _, item := range results {
-		title := recurringTitle(item.TitleVal, item.Recurrence)
+		title := priorityTitle(recurringTitle(item.TitleVal, item.Recurrence), item.Priority)
 		contextParts := []string{}
 		if showList && item.ListName != "" {
 			contextParts = append(contextParts, item.ListName)
@@ -63,6 +64,6 @@ func printSearchResults(results []taskItem, showList bool, showIDs bool) {
 		if item.HasDue {
 			dueText = fmt.Sprintf(" (due %s)", item.Due.Format("2006-01-02"))
 		}
-		fmt.Printf("- %s%s%s%s\n", title, context, dueText, idText)
+		fmt.Printf("- %s%s%s%s%s%s\n", handles.label(item.ListID, item.Section, item.ID, item.TitleVal), title, tagsSuffix(item.Tags), context, dueText, idText)
 	}
 }
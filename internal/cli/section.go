@@ -11,6 +11,13 @@ import (
 	"justdoit/internal/metadata"
 )
 
+var sectionMarker = metadata.Bool("justdoit_section")
+
+// taskSectionRef stores the ID of the section a task belongs to. Tasks created
+// by older versions are nested under the section task instead, which keeps
+// working but leaves no room for real subtasks.
+var taskSectionRef = metadata.String("justdoit_section_id")
+
 func newSectionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "section",
@@ -19,6 +26,10 @@ func newSectionCmd() *cobra.Command {
 	cmd.AddCommand(newSectionCreateCmd())
 	cmd.AddCommand(newSectionListCmd())
 	cmd.AddCommand(newSectionRenameCmd())
+	cmd.AddCommand(newSectionDeleteCmd())
+	cmd.AddCommand(newSectionMoveCmd())
+	cmd.AddCommand(newSectionReorderCmd())
+	cmd.AddCommand(newSectionArchiveCmd())
 	return cmd
 }
 
@@ -80,31 +91,16 @@ func newSectionListCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			sections := []string{}
-			sectionByTitle := map[string]*tasks.Task{}
-			for _, item := range items {
-				if item == nil {
-					continue
-				}
-				if _, ok := metadata.Extract(item.Notes, "justdoit_section"); !ok {
-					continue
-				}
-				if _, exists := sectionByTitle[item.Title]; exists {
-					continue
-				}
-				sectionByTitle[item.Title] = item
-				sections = append(sections, item.Title)
-			}
+			sections := sortedSections(items)
 			if len(sections) == 0 {
 				fmt.Println("(no sections)")
 				return nil
 			}
-			sort.Strings(sections)
-			for _, name := range sections {
+			for _, section := range sections {
 				if showID {
-					fmt.Printf("- %s [%s]\n", name, sectionByTitle[name].Id)
+					fmt.Printf("- %s [%s]\n", section.Title, section.Id)
 				} else {
-					fmt.Printf("- %s\n", name)
+					fmt.Printf("- %s\n", section.Title)
 				}
 			}
 			return nil
@@ -138,7 +134,11 @@ func newSectionRenameCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			renamed, err := renameSectionInList(app, listID, oldName, newName)
+			var renamed int
+			err = recordOp(app, "section", taskScope(listID), func() error {
+				renamed, err = renameSectionInList(app, listID, oldName, newName)
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -150,6 +150,152 @@ func newSectionRenameCmd() *cobra.Command {
 	return cmd
 }
 
+func newSectionDeleteCmd() *cobra.Command {
+	var (
+		list        string
+		deleteTasks bool
+	)
+	cmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a section, moving its tasks to General",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			listID, err := resolveListID(app, list, list != "")
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSpace(args[0])
+			var count int
+			err = recordOp(app, "section", sectionScope(listID, name), func() error {
+				count, err = deleteSection(app, listID, name, deleteTasks)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if deleteTasks {
+				fmt.Printf("🗑️ Section deleted with %d task(s)\n", count)
+			} else {
+				fmt.Printf("🗑️ Section deleted, %d task(s) moved to General\n", count)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	cmd.Flags().BoolVar(&deleteTasks, "delete-tasks", false, "Delete the section's tasks (and their calendar events) instead of moving them to General")
+	return cmd
+}
+
+func newSectionMoveCmd() *cobra.Command {
+	var (
+		list   string
+		toList string
+	)
+	cmd := &cobra.Command{
+		Use:   "move [name] --to-list [list]",
+		Short: "Move a section and its tasks to another list",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(toList) == "" {
+				return fmt.Errorf("--to-list is required")
+			}
+			fromListID, err := resolveListID(app, list, list != "")
+			if err != nil {
+				return err
+			}
+			toListID, err := resolveListID(app, toList, true)
+			if err != nil {
+				return err
+			}
+			if fromListID == toListID {
+				return fmt.Errorf("section is already in %s", toList)
+			}
+			name := strings.TrimSpace(args[0])
+			var count int
+			err = recordOp(app, "section", sectionScope(fromListID, name, toListID), func() error {
+				count, err = moveSectionToList(app, fromListID, toListID, name)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Section moved with %d task(s)\n", count)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "Source list name (mapped via config.json)")
+	cmd.Flags().StringVar(&toList, "to-list", "", "Target list name (mapped via config.json)")
+	return cmd
+}
+
+func newSectionReorderCmd() *cobra.Command {
+	var list string
+	cmd := &cobra.Command{
+		Use:   "reorder [name...]",
+		Short: "Put sections first, in the given order",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			listID, err := resolveListID(app, list, list != "")
+			if err != nil {
+				return err
+			}
+			if err := recordOp(app, "section", taskScope(listID), func() error {
+				return reorderSections(app, listID, args)
+			}); err != nil {
+				return err
+			}
+			fmt.Println("✅ Sections reordered")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	return cmd
+}
+
+func newSectionArchiveCmd() *cobra.Command {
+	var list string
+	cmd := &cobra.Command{
+		Use:   "archive [name]",
+		Short: "Complete a section and its pending tasks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			listID, err := resolveListID(app, list, list != "")
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSpace(args[0])
+			var count int
+			err = recordOp(app, "section", sectionScope(listID, name), func() error {
+				count, err = archiveSection(app, listID, name)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📦 Section archived, %d task(s) completed\n", count)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	return cmd
+}
+
 func ensureSectionTaskWithStatus(app *App, listID, section string) (*tasks.Task, bool, error) {
 	items, err := app.Tasks.ListTasks(listID, false)
 	if err != nil {
@@ -159,13 +305,13 @@ func ensureSectionTaskWithStatus(app *App, listID, section string) (*tasks.Task,
 		if item.Title != section {
 			continue
 		}
-		if _, ok := metadata.Extract(item.Notes, "justdoit_section"); ok {
+		if sectionMarker.Has(item.Notes) {
 			return item, false, nil
 		}
 	}
 	task := &tasks.Task{
 		Title: section,
-		Notes: metadata.Append("", "justdoit_section", "1"),
+		Notes: sectionMarker.Set("", true),
 	}
 	created, err := app.Tasks.CreateTask(listID, task)
 	if err != nil {
@@ -184,7 +330,7 @@ func renameSectionInList(app *App, listID, oldName, newName string) (int, error)
 		if !strings.EqualFold(item.Title, oldName) {
 			continue
 		}
-		if _, ok := metadata.Extract(item.Notes, "justdoit_section"); !ok {
+		if !sectionMarker.Has(item.Notes) {
 			continue
 		}
 		item.Title = newName
@@ -198,3 +344,255 @@ func renameSectionInList(app *App, listID, oldName, newName string) (int, error)
 	}
 	return count, nil
 }
+
+// sectionIDOf returns the section a task belongs to, given an index of the
+// list's section tasks by ID. It returns "" for tasks in "General".
+func sectionIDOf(item *tasks.Task, sections map[string]string) string {
+	if item == nil {
+		return ""
+	}
+	if id, ok := taskSectionRef.Get(item.Notes); ok {
+		if _, known := sections[id]; known {
+			return id
+		}
+	}
+	if _, ok := sections[item.Parent]; ok {
+		return item.Parent
+	}
+	return ""
+}
+
+// isSubtask reports whether item is nested under a regular task rather than
+// under a (legacy) section.
+func isSubtask(item *tasks.Task, sections map[string]string) bool {
+	if item == nil || item.Parent == "" {
+		return false
+	}
+	_, isSection := sections[item.Parent]
+	return !isSection
+}
+
+// sortedSections returns the pending section tasks in list position order. For
+// duplicated titles only the first one is kept.
+func sortedSections(items []*tasks.Task) []*tasks.Task {
+	sections := []*tasks.Task{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		if !isSectionTask(item) || item.Status == "completed" || item.Deleted {
+			continue
+		}
+		sections = append(sections, item)
+	}
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sections[i].Position < sections[j].Position
+	})
+	result := sections[:0]
+	for _, section := range sections {
+		if seen[section.Title] {
+			continue
+		}
+		seen[section.Title] = true
+		result = append(result, section)
+	}
+	return result
+}
+
+// findSections returns every section task titled name, archived ones included.
+func findSections(items []*tasks.Task, name string) []*tasks.Task {
+	result := []*tasks.Task{}
+	for _, item := range items {
+		if isSectionTask(item) && strings.EqualFold(item.Title, name) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// sectionMembers returns the top-level tasks filed under one of the given
+// sections. Subtasks follow their parent and are not listed.
+func sectionMembers(items []*tasks.Task, sections []*tasks.Task) []*tasks.Task {
+	index := buildSectionIndex(items)
+	wanted := map[string]bool{}
+	for _, section := range sections {
+		wanted[section.Id] = true
+	}
+	result := []*tasks.Task{}
+	for _, item := range items {
+		if item == nil || item.Deleted || isSectionTask(item) || isSubtask(item, index) {
+			continue
+		}
+		if wanted[sectionIDOf(item, index)] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func loadSections(app *App, listID, name string) ([]*tasks.Task, []*tasks.Task, error) {
+	items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	sections := findSections(items, name)
+	if len(sections) == 0 {
+		return nil, nil, fmt.Errorf("section not found: %s", name)
+	}
+	return items, sections, nil
+}
+
+// deleteSection removes a section. Its tasks go back to General, or are
+// deleted together with their calendar events when deleteTasks is set.
+func deleteSection(app *App, listID, name string, deleteTasks bool) (int, error) {
+	items, sections, err := loadSections(app, listID, name)
+	if err != nil {
+		return 0, err
+	}
+	members := sectionMembers(items, sections)
+	for _, member := range members {
+		if deleteTasks {
+			if err := deleteTask(app, listID, member.Id, true); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		// Deleting a section task also deletes tasks still nested under it.
+		if member.Parent != "" {
+			if _, err := app.Tasks.MoveTask(listID, member.Id, "", ""); err != nil {
+				return 0, err
+			}
+		}
+		if taskSectionRef.Has(member.Notes) {
+			member.Notes = setNotesSection(member.Notes, "")
+			if _, err := app.Tasks.UpdateTask(listID, member); err != nil {
+				return 0, err
+			}
+		}
+	}
+	for _, section := range sections {
+		if err := app.Tasks.DeleteTask(listID, section.Id); err != nil {
+			return 0, err
+		}
+	}
+	return len(members), nil
+}
+
+// moveSectionToList recreates a section in another list and moves its tasks
+// (with subtasks and linked events) there, merging into an existing section
+// of the same name.
+func moveSectionToList(app *App, fromListID, toListID, name string) (int, error) {
+	items, sections, err := loadSections(app, fromListID, name)
+	if err != nil {
+		return 0, err
+	}
+	target, _, err := ensureSectionTaskWithStatus(app, toListID, sections[0].Title)
+	if err != nil {
+		return 0, err
+	}
+	members := sectionMembers(items, sections)
+	for _, member := range members {
+		if err := moveTaskToList(app, fromListID, toListID, member.Id, target.Title); err != nil {
+			return 0, err
+		}
+	}
+	for _, section := range sections {
+		if err := app.Tasks.DeleteTask(fromListID, section.Id); err != nil {
+			return 0, err
+		}
+	}
+	return len(members), nil
+}
+
+// reorderSections moves the named sections to the top of the list, in order.
+// Other sections keep their relative order after them.
+func reorderSections(app *App, listID string, names []string) error {
+	items, err := app.Tasks.ListTasks(listID, false)
+	if err != nil {
+		return err
+	}
+	sections := sortedSections(items)
+	previous := ""
+	for _, name := range names {
+		var section *tasks.Task
+		for _, candidate := range sections {
+			if strings.EqualFold(candidate.Title, strings.TrimSpace(name)) {
+				section = candidate
+				break
+			}
+		}
+		if section == nil {
+			return fmt.Errorf("section not found: %s", name)
+		}
+		if _, err := app.Tasks.MoveTask(listID, section.Id, "", previous); err != nil {
+			return err
+		}
+		previous = section.Id
+	}
+	return nil
+}
+
+// shiftSection moves a section delta places up (negative) or down.
+func shiftSection(app *App, listID, name string, delta int) error {
+	items, err := app.Tasks.ListTasks(listID, false)
+	if err != nil {
+		return err
+	}
+	sections := sortedSections(items)
+	from := -1
+	for i, section := range sections {
+		if strings.EqualFold(section.Title, name) {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return fmt.Errorf("section not found: %s", name)
+	}
+	to := from + delta
+	if to < 0 || to >= len(sections) {
+		return nil
+	}
+	ids := make([]string, 0, len(sections))
+	for _, section := range sections {
+		ids = append(ids, section.Id)
+	}
+	_, err = app.Tasks.MoveTask(listID, sections[from].Id, "", movePrevious(ids, from, to))
+	return err
+}
+
+// archiveSection completes the pending tasks of a section and the section
+// itself. Completed tasks stay visible with `list --all`.
+func archiveSection(app *App, listID, name string) (int, error) {
+	items, sections, err := loadSections(app, listID, name)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, member := range sectionMembers(items, sections) {
+		if member.Status == "completed" {
+			continue
+		}
+		if err := completeSubtasks(app, listID, member.Id); err != nil {
+			return count, err
+		}
+		if _, err := app.Tasks.CompleteTask(listID, member.Id); err != nil {
+			return count, err
+		}
+		count++
+	}
+	for _, section := range sections {
+		if section.Status == "completed" {
+			continue
+		}
+		if _, err := app.Tasks.CompleteTask(listID, section.Id); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func setNotesSection(notes, sectionID string) string {
+	if strings.TrimSpace(sectionID) == "" {
+		return taskSectionRef.Remove(notes)
+	}
+	return taskSectionRef.Set(notes, sectionID)
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/config"
+	tasksapi "justdoit/internal/google/tasks"
+)
+
+func TestSortedSectionsUsesPositions(t *testing.T) {
+	items := []*tasks.Task{
+		{Id: "s-later", Title: "Later", Notes: sectionMarker.Set("", true), Position: "00000000000000000003"},
+		{Id: "task", Title: "Loose task", Position: "00000000000000000002"},
+		{Id: "s-now", Title: "Now", Notes: sectionMarker.Set("", true), Position: "00000000000000000001"},
+		{Id: "s-dup", Title: "Now", Notes: sectionMarker.Set("", true), Position: "00000000000000000004"},
+		{Id: "s-old", Title: "Old", Notes: sectionMarker.Set("", true), Status: "completed"},
+	}
+	got := []string{}
+	for _, section := range sortedSections(items) {
+		got = append(got, section.Id)
+	}
+	if !reflect.DeepEqual(got, []string{"s-now", "s-later"}) {
+		t.Fatalf("unexpected section order %v", got)
+	}
+
+	_, order := groupTasksBySection(items, "", false, time.UTC)
+	if !reflect.DeepEqual(order, []string{"Now", "Later", "General"}) {
+		t.Fatalf("unexpected grouped order %v", order)
+	}
+}
+
+func TestSectionMembersCoversLegacyAndMetadata(t *testing.T) {
+	section := &tasks.Task{Id: "s1", Title: "Errands", Notes: sectionMarker.Set("", true)}
+	items := []*tasks.Task{
+		section,
+		{Id: "legacy", Title: "Groceries", Parent: "s1"},
+		{Id: "meta", Title: "Post office", Notes: setNotesSection("", "s1")},
+		{Id: "sub", Title: "Stamps", Parent: "meta"},
+		{Id: "other", Title: "Elsewhere"},
+	}
+	got := []string{}
+	for _, member := range sectionMembers(items, findSections(items, "errands")) {
+		got = append(got, member.Id)
+	}
+	if !reflect.DeepEqual(got, []string{"legacy", "meta"}) {
+		t.Fatalf("unexpected members %v", got)
+	}
+}
+
+// fakeTasksAPI serves the Google Tasks endpoints the section commands use
+// from memory, for tests that need a real *tasks.Client.
+type fakeTasksAPI struct {
+	lists map[string][]*tasks.Task
+	next  int
+}
+
+func (f *fakeTasksAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	f.serve(rec, r)
+	return rec.Result(), nil
+}
+
+func (f *fakeTasksAPI) serve(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/tasks/v1/lists/"), "/")
+	if len(parts) < 2 || parts[1] != "tasks" {
+		http.NotFound(w, r)
+		return
+	}
+	listID := parts[0]
+	reply := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			reply(&tasks.Tasks{Items: f.lists[listID]})
+		case http.MethodPost:
+			task := &tasks.Task{}
+			if err := json.NewDecoder(r.Body).Decode(task); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.next++
+			task.Id = fmt.Sprintf("new-%d", f.next)
+			task.Parent = r.URL.Query().Get("parent")
+			f.lists[listID] = append(f.lists[listID], task)
+			reply(task)
+		}
+		return
+	}
+	id := parts[2]
+	for i, item := range f.lists[listID] {
+		if item.Id != id {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			reply(item)
+		case http.MethodPut:
+			updated := &tasks.Task{}
+			if err := json.NewDecoder(r.Body).Decode(updated); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.lists[listID][i] = updated
+			reply(updated)
+		case http.MethodDelete:
+			// Like Google Tasks, deleting a task deletes its subtasks.
+			kept := []*tasks.Task{}
+			for _, other := range f.lists[listID] {
+				if other.Id != id && other.Parent != id {
+					kept = append(kept, other)
+				}
+			}
+			f.lists[listID] = kept
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func newFakeTasksApp(t *testing.T, api *fakeTasksAPI, lists map[string]string) *App {
+	t.Helper()
+	client, err := tasksapi.New(t.Context(), &http.Client{Transport: api})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &App{Config: &config.Config{Lists: lists}, Tasks: client, Location: time.UTC}
+}
+
+func TestMoveSectionToListCreatesMissingSection(t *testing.T) {
+	api := &fakeTasksAPI{lists: map[string][]*tasks.Task{
+		"home": {
+			{Id: "sec", Title: "Errands", Notes: sectionMarker.Set("", true)},
+			{Id: "t1", Title: "Groceries", Notes: setNotesSection("", "sec")},
+			{Id: "t1a", Title: "Milk", Parent: "t1"},
+			{Id: "t2", Title: "Laundry"},
+		},
+		"work": {},
+	}}
+	app := newFakeTasksApp(t, api, map[string]string{"Home": "home", "Work": "work"})
+
+	count, err := moveSectionToList(app, "home", "work", "Errands")
+	if err != nil {
+		t.Fatalf("moveSectionToList: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("moved %d task(s), want 1", count)
+	}
+	titles := func(items []*tasks.Task) []string {
+		out := []string{}
+		for _, item := range items {
+			out = append(out, item.Title)
+		}
+		return out
+	}
+	if got := titles(api.lists["home"]); !reflect.DeepEqual(got, []string{"Laundry"}) {
+		t.Fatalf("home kept %v", got)
+	}
+	work := api.lists["work"]
+	if got := titles(work); !reflect.DeepEqual(got, []string{"Errands", "Groceries", "Milk"}) {
+		t.Fatalf("work has %v", got)
+	}
+	if !sectionMarker.Has(work[0].Notes) {
+		t.Fatalf("expected Errands to be created as a section, got %q", work[0].Notes)
+	}
+	if id, _ := taskSectionRef.Get(work[1].Notes); id != work[0].Id {
+		t.Fatalf("moved task points at section %q, want %q", id, work[0].Id)
+	}
+	if work[2].Parent != work[1].Id {
+		t.Fatalf("subtask parent = %q, want %q", work[2].Parent, work[1].Id)
+	}
+}
+
+func TestTUISectionCreate(t *testing.T) {
+	api := &fakeTasksAPI{lists: map[string][]*tasks.Task{"work": {}}}
+	m := tuiModel{app: newFakeTasksApp(t, api, map[string]string{"Work": "work"})}
+
+	msg := m.applySectionOp("work", sectionActionCreate, "", "Later", false)
+	if msg.err != nil || msg.status != "✅ Section created" {
+		t.Fatalf("first create = %+v", msg)
+	}
+	if len(api.lists["work"]) != 1 || !sectionMarker.Has(api.lists["work"][0].Notes) {
+		t.Fatalf("expected a Later section, got %+v", api.lists["work"])
+	}
+	msg = m.applySectionOp("work", sectionActionCreate, "", "Later", false)
+	if msg.err != nil || !strings.Contains(msg.status, "already exists") {
+		t.Fatalf("second create = %+v", msg)
+	}
+	if len(api.lists["work"]) != 1 {
+		t.Fatalf("expected no duplicate section, got %d task(s)", len(api.lists["work"]))
+	}
+}
@@ -28,11 +28,23 @@ type choiceItem[T any] struct {
 }
 
 func newSetupCmd() *cobra.Command {
+	var opts setupOptions
 	cmd := &cobra.Command{
 		Use:   "setup",
 		Short: "Interactive setup for calendars and task lists",
+		Long: "Interactive setup for calendars and task lists.\n" +
+			"For scripts: setup --non-interactive --calendar NAME --inbox NAME [--lists A,B] [--create-missing]",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			app, err := initApp(cmd)
+			if !opts.NonInteractive && opts.any() {
+				return fmt.Errorf("--calendar, --inbox, --lists and --create-missing require --non-interactive")
+			}
+			open := initApp
+			if opts.NonInteractive {
+				// Scripts cannot answer a browser login; fail instead.
+				open = initSavedApp
+			}
+			app, err := open(cmd)
 			if err != nil {
 				return err
 			}
@@ -45,6 +57,17 @@ func newSetupCmd() *cobra.Command {
 				return err
 			}
 
+			if opts.NonInteractive {
+				if err := setupNonInteractive(app, cfg, opts); err != nil {
+					return err
+				}
+				if err := config.Save(cfgPath, cfg); err != nil {
+					return err
+				}
+				fmt.Printf("Config saved to %s\n", cfgPath)
+				return nil
+			}
+
 			printSection("Calendar")
 			if err := setupCalendars(app, cfg); err != nil {
 				return err
@@ -62,6 +85,11 @@ func newSetupCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&opts.NonInteractive, "non-interactive", false, "Configure from flags without prompting")
+	cmd.Flags().StringVar(&opts.Calendar, "calendar", "", "Calendar name or ID for time blocks (\"primary\" for your main calendar)")
+	cmd.Flags().StringVar(&opts.Inbox, "inbox", "", "Task list name or ID to use as Inbox")
+	cmd.Flags().StringSliceVar(&opts.Lists, "lists", nil, "Additional task lists to map, comma-separated")
+	cmd.Flags().BoolVar(&opts.CreateMissing, "create-missing", false, "Create task lists that do not exist yet")
 	return cmd
 }
 
@@ -112,12 +140,18 @@ func setupCalendars(app *App, cfg *config.Config) error {
 	if !ok {
 		return fmt.Errorf("invalid calendar selection")
 	}
+	setCalendar(cfg, choice.Item.ID)
+	return nil
+}
+
+// setCalendar switches calendar_id, keeping view_calendars in step when it
+// only showed the previous calendar.
+func setCalendar(cfg *config.Config, id string) {
 	prev := cfg.CalendarID
-	cfg.CalendarID = choice.Item.ID
+	cfg.CalendarID = id
 	if len(cfg.ViewCalendars) == 0 || (len(cfg.ViewCalendars) == 1 && cfg.ViewCalendars[0] == prev) {
 		cfg.ViewCalendars = []string{cfg.CalendarID}
 	}
-	return nil
 }
 
 func setupLists(app *App, cfg *config.Config) error {
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"justdoit/internal/config"
+)
+
+type setupOptions struct {
+	NonInteractive bool
+	Calendar       string
+	Inbox          string
+	Lists          []string
+	CreateMissing  bool
+}
+
+func (o setupOptions) any() bool {
+	return o.Calendar != "" || o.Inbox != "" || len(o.Lists) > 0 || o.CreateMissing
+}
+
+// setupTarget is a list named on the command line: an existing list, or one
+// to create when Create is set.
+type setupTarget struct {
+	Name   string
+	List   simpleList
+	Create bool
+}
+
+type setupPlan struct {
+	Calendar simpleCalendar
+	Inbox    setupTarget
+	Lists    []setupTarget
+}
+
+func setupNonInteractive(app *App, cfg *config.Config, opts setupOptions) error {
+	if strings.TrimSpace(opts.Calendar) == "" {
+		return fmt.Errorf("--calendar is required with --non-interactive")
+	}
+	if strings.TrimSpace(opts.Inbox) == "" {
+		return fmt.Errorf("--inbox is required with --non-interactive")
+	}
+	calItems, err := app.Calendar.ListCalendars()
+	if err != nil {
+		return err
+	}
+	calendars := make([]simpleCalendar, 0, len(calItems))
+	for _, cal := range calItems {
+		calendars = append(calendars, simpleCalendar{Title: cal.Summary, ID: cal.Id, Primary: cal.Primary})
+	}
+	remote, err := app.Tasks.ListTaskLists()
+	if err != nil {
+		return err
+	}
+	lists := make([]simpleList, 0, len(remote))
+	for _, l := range remote {
+		lists = append(lists, simpleList{Title: l.Title, ID: l.Id})
+	}
+
+	plan, err := planSetup(calendars, lists, opts)
+	if err != nil {
+		return err
+	}
+
+	// Check every name before creating or mapping anything.
+	for _, target := range append([]setupTarget{plan.Inbox}, plan.Lists...) {
+		if err := setupConflict(cfg, target.title(), target.List.ID); err != nil {
+			return err
+		}
+	}
+
+	setCalendar(cfg, plan.Calendar.ID)
+	fmt.Printf("Calendar: %s (%s)\n", plan.Calendar.Title, plan.Calendar.ID)
+	name, err := applySetupTarget(app, cfg, plan.Inbox)
+	if err != nil {
+		return err
+	}
+	cfg.DefaultList = name
+	fmt.Printf("Inbox: %s\n", name)
+	for _, target := range plan.Lists {
+		name, err := applySetupTarget(app, cfg, target)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("List: %s\n", name)
+	}
+	return nil
+}
+
+// title is the name the list gets in config.json.
+func (t setupTarget) title() string {
+	if t.Create {
+		return t.Name
+	}
+	return t.List.Title
+}
+
+// setupConflict reports a local name already mapped to a different list. id
+// is empty for lists still to be created.
+func setupConflict(cfg *config.Config, name, id string) error {
+	for _, mapped := range cfg.Lists {
+		if mapped == id && id != "" {
+			// Already mapped under some name, which is reused.
+			return nil
+		}
+	}
+	if mapped, ok := cfg.Lists[name]; ok && mapped != id {
+		return fmt.Errorf("list name %q is already mapped to %s in config.json; rename or remove that mapping first", name, mapped)
+	}
+	return nil
+}
+
+// applySetupTarget creates the list if needed and maps it, reusing the local
+// name when the list is already mapped so that reruns change nothing. It
+// never replaces a mapping of the same name to another list.
+func applySetupTarget(app *App, cfg *config.Config, target setupTarget) (string, error) {
+	list := target.List
+	if target.Create {
+		created, err := createNewList(app, target.Name)
+		if err != nil {
+			return "", fmt.Errorf("create list %q: %w", target.Name, err)
+		}
+		fmt.Printf("Created list %q\n", created.Title)
+		list = created
+	}
+	for _, name := range sortedListNames(cfg.Lists) {
+		if cfg.Lists[name] == list.ID {
+			return name, nil
+		}
+	}
+	if err := setupConflict(cfg, list.Title, list.ID); err != nil {
+		return "", err
+	}
+	if cfg.Lists == nil {
+		cfg.Lists = map[string]string{}
+	}
+	cfg.Lists[list.Title] = list.ID
+	return list.Title, nil
+}
+
+// planSetup resolves the names given on the command line without changing
+// anything, so a single ambiguous or unknown name fails the whole run. Every
+// problem is reported, not just the first.
+func planSetup(calendars []simpleCalendar, lists []simpleList, opts setupOptions) (setupPlan, error) {
+	plan := setupPlan{}
+	problems := []string{}
+
+	switch matches := matchCalendars(calendars, opts.Calendar); len(matches) {
+	case 0:
+		problems = append(problems, fmt.Sprintf("calendar %q not found; available: %s", opts.Calendar, calendarTitles(calendars)))
+	case 1:
+		plan.Calendar = matches[0]
+	default:
+		problems = append(problems, fmt.Sprintf("calendar %q is ambiguous; use one of these IDs: %s", opts.Calendar, calendarIDs(matches)))
+	}
+
+	seen := map[string]bool{}
+	resolve := func(name string) (setupTarget, bool) {
+		name = strings.TrimSpace(name)
+		switch matches := matchLists(lists, name); len(matches) {
+		case 0:
+			if !opts.CreateMissing {
+				problems = append(problems, fmt.Sprintf("task list %q not found (pass --create-missing to create it); available: %s", name, listTitles(lists)))
+				return setupTarget{}, false
+			}
+			return setupTarget{Name: name, Create: true}, true
+		case 1:
+			return setupTarget{Name: name, List: matches[0]}, true
+		default:
+			problems = append(problems, fmt.Sprintf("task list %q is ambiguous; use one of these IDs: %s", name, listIDs(matches)))
+			return setupTarget{}, false
+		}
+	}
+	key := func(target setupTarget) string {
+		if target.Create {
+			return "new:" + strings.ToLower(target.Name)
+		}
+		return target.List.ID
+	}
+
+	if inbox, ok := resolve(opts.Inbox); ok {
+		plan.Inbox = inbox
+		seen[key(inbox)] = true
+	}
+	for _, name := range opts.Lists {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		target, ok := resolve(name)
+		if !ok || seen[key(target)] {
+			continue
+		}
+		seen[key(target)] = true
+		plan.Lists = append(plan.Lists, target)
+	}
+
+	if len(problems) > 0 {
+		return setupPlan{}, errors.New("setup failed:\n  " + strings.Join(problems, "\n  "))
+	}
+	return plan, nil
+}
+
+// matchCalendars finds calendars by ID, "primary", exact title and then
+// case-insensitive title.
+func matchCalendars(calendars []simpleCalendar, name string) []simpleCalendar {
+	name = strings.TrimSpace(name)
+	matches := []simpleCalendar{}
+	if strings.EqualFold(name, "primary") {
+		for _, cal := range calendars {
+			if cal.Primary {
+				matches = append(matches, cal)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	for _, cal := range calendars {
+		if cal.ID == name {
+			return []simpleCalendar{cal}
+		}
+	}
+	for _, cal := range calendars {
+		if cal.Title == name {
+			matches = append(matches, cal)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+	for _, cal := range calendars {
+		if strings.EqualFold(cal.Title, name) {
+			matches = append(matches, cal)
+		}
+	}
+	return matches
+}
+
+// matchLists finds task lists by ID, exact title and then case-insensitive
+// title.
+func matchLists(lists []simpleList, name string) []simpleList {
+	for _, l := range lists {
+		if l.ID == name {
+			return []simpleList{l}
+		}
+	}
+	matches := []simpleList{}
+	for _, l := range lists {
+		if l.Title == name {
+			matches = append(matches, l)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+	for _, l := range lists {
+		if strings.EqualFold(l.Title, name) {
+			matches = append(matches, l)
+		}
+	}
+	return matches
+}
+
+func calendarTitles(calendars []simpleCalendar) string {
+	titles := []string{}
+	for _, choice := range buildCalendarChoices(calendars) {
+		titles = append(titles, fmt.Sprintf("%q", choice.Item.Title))
+	}
+	return joinOrNone(titles)
+}
+
+func calendarIDs(calendars []simpleCalendar) string {
+	ids := []string{}
+	for _, cal := range calendars {
+		ids = append(ids, fmt.Sprintf("%s (%s)", cal.ID, cal.Title))
+	}
+	return joinOrNone(ids)
+}
+
+func listTitles(lists []simpleList) string {
+	titles := []string{}
+	for _, choice := range buildListChoices(lists) {
+		titles = append(titles, fmt.Sprintf("%q", choice.Item.Title))
+	}
+	return joinOrNone(titles)
+}
+
+func listIDs(lists []simpleList) string {
+	ids := []string{}
+	for _, l := range lists {
+		ids = append(ids, fmt.Sprintf("%s (%s)", l.ID, l.Title))
+	}
+	return joinOrNone(ids)
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
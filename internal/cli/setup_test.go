@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"justdoit/internal/config"
+)
+
+func TestPlanSetup(t *testing.T) {
+	calendars := []simpleCalendar{
+		{Title: "me@example.com", ID: "me@example.com", Primary: true},
+		{Title: "Focus", ID: "focus-id"},
+		{Title: "Team", ID: "team-1"},
+		{Title: "Team", ID: "team-2"},
+	}
+	lists := []simpleList{
+		{Title: "Inbox", ID: "in"},
+		{Title: "Work", ID: "wk"},
+		{Title: "Errands", ID: "er-1"},
+		{Title: "errands", ID: "er-2"},
+	}
+
+	plan, err := planSetup(calendars, lists, setupOptions{
+		Calendar:      "focus",
+		Inbox:         "inbox",
+		Lists:         []string{"Work", "Inbox", "Reading", "reading", ""},
+		CreateMissing: true,
+	})
+	if err != nil {
+		t.Fatalf("planSetup: %v", err)
+	}
+	if plan.Calendar.ID != "focus-id" {
+		t.Fatalf("calendar = %+v", plan.Calendar)
+	}
+	if plan.Inbox.List.ID != "in" || plan.Inbox.Create {
+		t.Fatalf("inbox = %+v", plan.Inbox)
+	}
+	if len(plan.Lists) != 2 || plan.Lists[0].List.ID != "wk" || !plan.Lists[1].Create || plan.Lists[1].Name != "Reading" {
+		t.Fatalf("lists = %+v", plan.Lists)
+	}
+
+	plan, err = planSetup(calendars, lists, setupOptions{Calendar: "primary", Inbox: "Errands"})
+	if err != nil {
+		t.Fatalf("exact title should win over case-insensitive: %v", err)
+	}
+	if plan.Calendar.ID != "me@example.com" || plan.Inbox.List.ID != "er-1" {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	_, err = planSetup(calendars, lists, setupOptions{Calendar: "Team", Inbox: "ERRANDS", Lists: []string{"Someday"}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		`calendar "Team" is ambiguous; use one of these IDs: team-1 (Team), team-2 (Team)`,
+		`task list "ERRANDS" is ambiguous; use one of these IDs: er-1 (Errands), er-2 (errands)`,
+		`task list "Someday" not found (pass --create-missing to create it)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q lacks %q", err, want)
+		}
+	}
+
+	if _, err := planSetup(calendars, lists, setupOptions{Calendar: "team-2", Inbox: "er-2"}); err != nil {
+		t.Fatalf("IDs should resolve directly: %v", err)
+	}
+}
+
+func TestApplySetupTargetKeepsOtherMapping(t *testing.T) {
+	cfg := &config.Config{Lists: map[string]string{"Work": "old-work", "Inbox": "in"}}
+
+	_, err := applySetupTarget(nil, cfg, setupTarget{Name: "work", List: simpleList{Title: "Work", ID: "new-work"}})
+	if err == nil || !strings.Contains(err.Error(), "already mapped to old-work") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if cfg.Lists["Work"] != "old-work" {
+		t.Fatalf("mapping overwritten: %v", cfg.Lists)
+	}
+
+	// A list mapped under another name keeps that name.
+	name, err := applySetupTarget(nil, cfg, setupTarget{Name: "inbox", List: simpleList{Title: "My Inbox", ID: "in"}})
+	if err != nil || name != "Inbox" {
+		t.Fatalf("expected the existing name Inbox, got %q, %v", name, err)
+	}
+	if err := setupConflict(cfg, "Work", ""); err == nil {
+		t.Fatal("expected a conflict for a list still to be created")
+	}
+}
@@ -9,27 +9,138 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
 
+	"justdoit/internal/cache"
 	"justdoit/internal/timeparse"
 )
 
+// defaultBlockLength is the block created when a task without one is snoozed
+// to a time of day.
+const defaultBlockLength = time.Hour
+
 type snoozeMsg struct {
-	err error
+	status string
+	err    error
 }
 
 var (
 	dateTokenRe = regexp.MustCompile(`(?i)\b(today|tomorrow|next|this|mon|monday|tue|tuesday|wed|wednesday|thu|thursday|fri|friday|sat|saturday|sun|sunday|jan|january|feb|february|mar|march|apr|april|may|jun|june|jul|july|aug|august|sep|sept|september|oct|october|nov|november|dec|december)\b`)
 	ymdTokenRe  = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
 	slashToken  = regexp.MustCompile(`\b\d{1,2}/\d{1,2}(/\d{2,4})?\b`)
+	clockToken  = regexp.MustCompile(`(?i)\b\d{1,2}(:\d{2})?\s*(am|pm)\b|\b\d{1,2}:\d{2}\b`)
 )
 
+func newSnoozeCmd() *cobra.Command {
+	var (
+		list      string
+		deferTask bool
+		match     titleQuery
+		bulk      bulkOptions
+	)
+	cmd := &cobra.Command{
+		Use:   "snooze [taskID...] <when>",
+		Short: "Push tasks to a later date or time (e.g. tomorrow, +2d, next monday 9am)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			selected := strings.TrimSpace(bulk.Query) != "" || bulk.Stdin || match.isSet()
+			ids, when := splitSnoozeArgs(loadTaskCache(app), args, selected)
+			if when == "" {
+				return fmt.Errorf("when is required (e.g. tomorrow, +2d or 15:00-16:00)")
+			}
+			var params UpdateParams
+			if deferTask {
+				day, err := parseDeferInput(when, app.Now(), app.Location)
+				if err != nil {
+					return err
+				}
+				params = UpdateParams{Start: day, HasStart: true}
+			} else {
+				params, err = parseSnoozeInput(when, app.Now(), app.Location)
+				if err != nil {
+					return err
+				}
+			}
+
+			var targets []bulkTarget
+			if len(ids) > 0 || strings.TrimSpace(bulk.Query) != "" || bulk.Stdin {
+				targets, err = collectTargets(app, list, ids, bulk)
+			} else if match.isSet() {
+				var target bulkTarget
+				target, err = resolveTaskByTitle(app, list, match, false)
+				targets = []bulkTarget{target}
+			} else {
+				err = fmt.Errorf("requires [taskID...], --title, --query or --stdin")
+			}
+			if err != nil {
+				return err
+			}
+			snooze := func(target bulkTarget) ([]string, error) {
+				_, err := updateTaskWithParams(app, target.ListID, target.ID, params)
+				return nil, err
+			}
+			if bulk.isBulk(targets) {
+				return runBulk(app, targets, bulk, "snooze", "snoozed", targetScope(targets), snooze)
+			}
+
+			var result UpdateResult
+			if err := recordOp(app, "snooze", targetScope(targets), func() error {
+				result, err = updateTaskWithParams(app, targets[0].ListID, targets[0].ID, params)
+				return err
+			}); err != nil {
+				return err
+			}
+			if params.HasStart {
+				fmt.Printf("💤 Hidden from next until %s\n", params.Start.Format("Mon 2006-01-02"))
+				return nil
+			}
+			fmt.Println("💤 Task snoozed")
+			if result.EventUpdated {
+				fmt.Println("📅 Event moved")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json); found from the task ID when omitted")
+	cmd.Flags().BoolVar(&deferTask, "defer", false, "Keep the due date and hide the task from next until the given day")
+	cmd.Flags().StringVar(&match.Text, "title", "", "Snooze a task by title, fuzzy matched across lists (alternative to taskID)")
+	cmd.Flags().StringVar(&match.Section, "section", "", "Only match tasks in this section when using --title")
+	cmd.Flags().BoolVar(&match.First, "first", false, "With --title, take the best match instead of asking")
+	cmd.Flags().BoolVar(&match.Strict, "strict", false, "With --title, only accept an exact title and never ask")
+	addBulkFlags(cmd, &bulk)
+	return cmd
+}
+
+// splitSnoozeArgs separates task references from the snooze time. Without
+// --title/--query/--stdin the first argument is a task, and so are following
+// handles and task IDs the cache knows (see isTaskRef); the rest is the time.
+func splitSnoozeArgs(c *cache.Cache, args []string, selected bool) ([]string, string) {
+	if selected {
+		return nil, strings.TrimSpace(strings.Join(args, " "))
+	}
+	n := 1
+	for n < len(args)-1 && isTaskRef(c, args[n]) {
+		n++
+	}
+	return args[:n], strings.TrimSpace(strings.Join(args[n:], " "))
+}
+
 func (m tuiModel) snoozeCmd(task taskItem, input string) tea.Cmd {
 	return func() tea.Msg {
 		params, err := parseSnoozeInput(input, m.app.Now(), m.app.Location)
 		if err != nil {
 			return snoozeMsg{err: err}
 		}
-		_, err = updateTaskWithParams(m.app, task.ListID, task.ID, params)
+		err = recordOp(m.app, "snooze", taskScope(task.ListID, task.ID), func() error {
+			_, err := updateTaskWithParams(m.app, task.ListID, task.ID, params)
+			return err
+		})
 		return snoozeMsg{err: err}
 	}
 }
@@ -40,13 +151,14 @@ func (m *tuiModel) openSnooze(task taskItem) {
 		return
 	}
 	m.snoozeTask = task
+	m.snoozeBulk = false
 	m.snoozeReturnState = m.state
 	m.snoozeReturnListCtx = m.listCtx
 	m.state = stateSnooze
 	m.status = ""
 	if m.snoozeInput.Placeholder == "" {
 		m.snoozeInput = textinput.New()
-		m.snoozeInput.Placeholder = "Snooze to (e.g. tomorrow or 15:00-16:00)"
+		m.snoozeInput.Placeholder = "Snooze to (e.g. tomorrow, +2d, friday 9am, 15:00-16:00 or ^monday to defer)"
 		m.snoozeInput.CharLimit = 200
 	}
 	m.snoozeInput.SetValue("")
@@ -81,12 +193,28 @@ func (m *tuiModel) refreshAfterSnooze() (tuiModel, tea.Cmd) {
 	}
 }
 
+// parseSnoozeInput understands offsets (+2d, +1w, +3h), time blocks
+// (15:00-16:00, 1h), a date with a time of day (next monday 9am) and plain
+// dates (tomorrow). A leading ^ (^monday) defers the task instead, keeping
+// its due date.
 func parseSnoozeInput(value string, now time.Time, loc *time.Location) (UpdateParams, error) {
 	input := strings.TrimSpace(value)
 	if input == "" {
 		return UpdateParams{}, fmt.Errorf("date or time is required")
 	}
 
+	if rest, ok := strings.CutPrefix(input, "^"); ok {
+		day, err := parseDeferInput(rest, now, loc)
+		if err != nil {
+			return UpdateParams{}, err
+		}
+		return UpdateParams{Start: day, HasStart: true}, nil
+	}
+
+	if days, shift, ok := timeparse.ParseOffset(input); ok {
+		return UpdateParams{ShiftDays: days, Shift: shift, HasShift: true}, nil
+	}
+
 	if start, _, err := timeparse.ParseTimeRange(input, time.Time{}, now, loc); err == nil {
 		params := UpdateParams{
 			Time:    input,
@@ -100,7 +228,13 @@ func parseSnoozeInput(value string, now time.Time, loc *time.Location) (UpdatePa
 		return params, nil
 	}
 
-	if parsed, err := timeparse.ParseDate(input, now, loc); err == nil && !parsed.IsZero() {
+	if clockToken.MatchString(input) {
+		if at, err := timeparse.ParseDateTime(input, now, loc); err == nil && !at.IsZero() {
+			return UpdateParams{At: at, HasAt: true}, nil
+		}
+	}
+
+	if parsed, err := timeparse.ParseFutureDate(input, now, loc); err == nil && !parsed.IsZero() {
 		return UpdateParams{
 			Date:    parsed.Format("2006-01-02"),
 			HasDate: true,
@@ -113,3 +247,70 @@ func parseSnoozeInput(value string, now time.Time, loc *time.Location) (UpdatePa
 func hasExplicitDate(input string) bool {
 	return dateTokenRe.MatchString(input) || ymdTokenRe.MatchString(input) || slashToken.MatchString(input)
 }
+
+// parseDeferInput turns snooze input into the day a deferred task starts.
+func parseDeferInput(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	params, err := parseSnoozeInput(value, now, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var day time.Time
+	switch {
+	case params.HasStart:
+		day = params.Start
+	case params.HasShift:
+		day = now.In(loc).AddDate(0, 0, params.ShiftDays).Add(params.Shift)
+	case params.HasAt:
+		day = params.At
+	case params.HasDate:
+		day, err = time.ParseInLocation("2006-01-02", params.Date, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+	default:
+		return time.Time{}, fmt.Errorf("a deferred task needs a date, not a time block")
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// snoozeTimes works out where At or a shift puts a task. A linked block keeps
+// its length; without one, At creates a block and a shift only moves the due
+// date. Shifts start from today when the task is already overdue.
+func snoozeTimes(app *App, task *tasks.Task, event *calendar.Event, params UpdateParams) (*time.Time, *time.Time, *time.Time) {
+	loc := app.Location
+	now := app.Now()
+	blockStart, blockEnd := eventTimes(event, loc)
+	hasBlock := !blockStart.IsZero() && !blockEnd.IsZero()
+	if params.HasAt {
+		length := defaultBlockLength
+		if hasBlock {
+			length = blockEnd.Sub(blockStart)
+		}
+		start := params.At.In(loc)
+		end := start.Add(length)
+		return &start, &end, &end
+	}
+	if hasBlock {
+		length := blockEnd.Sub(blockStart)
+		start := notBeforeToday(blockStart, now).AddDate(0, 0, params.ShiftDays).Add(params.Shift)
+		end := start.Add(length)
+		return &start, &end, &end
+	}
+	base := now
+	if due, ok, _ := parseTaskDue(task.Due, loc); ok {
+		base = notBeforeToday(due, now)
+	}
+	moved := base.AddDate(0, 0, params.ShiftDays).Add(params.Shift)
+	due := time.Date(moved.Year(), moved.Month(), moved.Day(), 23, 59, 0, 0, loc)
+	return nil, nil, &due
+}
+
+// notBeforeToday moves t to today, keeping its time of day, when it is in an
+// earlier day than now.
+func notBeforeToday(t, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !t.Before(today) {
+		return t
+	}
+	return time.Date(today.Year(), today.Month(), today.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+}
@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	"google.golang.org/api/tasks/v1"
+
+	"justdoit/internal/cache"
 )
 
 func TestParseSnoozeInputDateOnly(t *testing.T) {
@@ -40,3 +45,132 @@ func TestParseSnoozeInputTimeOnly(t *testing.T) {
 		t.Fatalf("expected HasDate false")
 	}
 }
+
+func TestParseSnoozeInputOffsetsAndTimes(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 9, 0, 0, 0, loc) // Saturday
+	params, err := parseSnoozeInput("+1w", now, loc)
+	if err != nil || !params.HasShift || params.ShiftDays != 7 || params.Shift != 0 {
+		t.Fatalf("unexpected +1w params %#v (%v)", params, err)
+	}
+	params, err = parseSnoozeInput("+3h", now, loc)
+	if err != nil || !params.HasShift || params.Shift != 3*time.Hour || params.HasTime {
+		t.Fatalf("unexpected +3h params %#v (%v)", params, err)
+	}
+	params, err = parseSnoozeInput("next monday 9am", now, loc)
+	if err != nil || !params.HasAt || !params.At.Equal(time.Date(2026, 1, 5, 9, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected next monday 9am params %#v (%v)", params, err)
+	}
+	day, err := parseDeferInput("+2d", now, loc)
+	if err != nil || !day.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected defer day %v (%v)", day, err)
+	}
+	if _, err := parseDeferInput("15:00-16:00", now, loc); err == nil {
+		t.Fatalf("expected time blocks to be rejected for --defer")
+	}
+}
+
+func TestSplitSnoozeArgs(t *testing.T) {
+	c := cache.Default()
+	c.Tasks.Lists["list-1"] = map[string]cache.TaskEntry{
+		"MTIzNDU2Nzg5MDEyMzQ1Ng": {ID: "MTIzNDU2Nzg5MDEyMzQ1Ng", Title: "Buy milk"},
+	}
+	ids, when := splitSnoozeArgs(c, []string{"#4", "#7", "next", "monday", "9am"}, false)
+	if !reflect.DeepEqual(ids, []string{"#4", "#7"}) || when != "next monday 9am" {
+		t.Fatalf("unexpected split %v %q", ids, when)
+	}
+	ids, when = splitSnoozeArgs(c, []string{"abcd", "tomorrow"}, false)
+	if !reflect.DeepEqual(ids, []string{"abcd"}) || when != "tomorrow" {
+		t.Fatalf("unexpected split %v %q", ids, when)
+	}
+	ids, when = splitSnoozeArgs(c, []string{"#4", "MTIzNDU2Nzg5MDEyMzQ1Ng", "+2d"}, false)
+	if !reflect.DeepEqual(ids, []string{"#4", "MTIzNDU2Nzg5MDEyMzQ1Ng"}) || when != "+2d" {
+		t.Fatalf("expected a known task ID to be a task, got %v %q", ids, when)
+	}
+	// Long words are times unless the cache knows them as task IDs.
+	ids, when = splitSnoozeArgs(c, []string{"#4", "wednesdayafternoon", "3pm"}, false)
+	if !reflect.DeepEqual(ids, []string{"#4"}) || when != "wednesdayafternoon 3pm" {
+		t.Fatalf("expected an unknown long word to be part of the time, got %v %q", ids, when)
+	}
+	if ids, when := splitSnoozeArgs(c, []string{"+2d"}, true); ids != nil || when != "+2d" {
+		t.Fatalf("expected every argument to be the time with --query, got %v %q", ids, when)
+	}
+}
+
+func TestNotBeforeTodayKeepsTimeOfDay(t *testing.T) {
+	now := time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)
+	past := time.Date(2025, 12, 30, 14, 30, 0, 0, time.UTC)
+	if got := notBeforeToday(past, now); !got.Equal(time.Date(2026, 1, 3, 14, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected overdue time moved to today, got %v", got)
+	}
+	future := time.Date(2026, 1, 8, 8, 0, 0, 0, time.UTC)
+	if got := notBeforeToday(future, now); !got.Equal(future) {
+		t.Fatalf("expected future time unchanged, got %v", got)
+	}
+}
+
+func TestBuildNextItemsHidesDeferredTasks(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, loc)
+	items := []*tasks.Task{
+		{Id: "1", Title: "Later", Status: "needsAction", Notes: setNotesStartDate("", time.Date(2026, 1, 5, 0, 0, 0, 0, loc))},
+		{Id: "2", Title: "Starts today", Status: "needsAction", Notes: setNotesStartDate("", time.Date(2026, 1, 3, 0, 0, 0, 0, loc))},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Work": "list-1"},
+		Location: loc,
+		Now:      func() time.Time { return now },
+	}
+	result, err := buildNextItems(ctx, true)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	ids := []string{}
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && !task.IsHeader {
+			ids = append(ids, task.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []string{"2"}) {
+		t.Fatalf("expected only the task starting today, got %v", ids)
+	}
+}
+
+func TestParseSnoozeInputDefersWithCaret(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 9, 0, 0, 0, loc)
+	params, err := parseSnoozeInput("^monday", now, loc)
+	if err != nil || !params.HasStart || params.HasDate || !params.Start.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected ^monday params %#v (%v)", params, err)
+	}
+}
+
+func TestBuildDeferredItemsGroupsByStartDay(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, loc)
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, loc)
+	items := []*tasks.Task{
+		{Id: "1", Title: "Later", Status: "needsAction", Notes: setNotesStartDate("", monday.AddDate(0, 0, 2))},
+		{Id: "2", Title: "Monday", Status: "needsAction", Notes: setNotesStartDate("", monday)},
+		{Id: "3", Title: "Now", Status: "needsAction"},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Work": "list-1"},
+		Location: loc,
+		Now:      func() time.Time { return now },
+	}
+	result, err := buildDeferredItems(ctx)
+	if err != nil {
+		t.Fatalf("buildDeferredItems error: %v", err)
+	}
+	got := []string{}
+	for _, item := range result {
+		got = append(got, item.(taskItem).TitleVal)
+	}
+	want := []string{"Starts Mon 2026-01-05", "Monday", "Starts Wed 2026-01-07", "Later"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected deferred view %v", got)
+	}
+}
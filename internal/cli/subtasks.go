@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"google.golang.org/api/tasks/v1"
+)
+
+// indexSubtasks groups subtasks by parent ID, in list position order.
+func indexSubtasks(items []*tasks.Task, sections map[string]string) map[string][]*tasks.Task {
+	children := map[string][]*tasks.Task{}
+	for _, item := range items {
+		if item == nil || item.Deleted || !isSubtask(item, sections) {
+			continue
+		}
+		children[item.Parent] = append(children[item.Parent], item)
+	}
+	for parentID := range children {
+		sort.SliceStable(children[parentID], func(i, j int) bool {
+			return children[parentID][i].Position < children[parentID][j].Position
+		})
+	}
+	return children
+}
+
+func subtaskProgress(children []*tasks.Task) (int, int) {
+	done := 0
+	for _, child := range children {
+		if strings.EqualFold(child.Status, "completed") {
+			done++
+		}
+	}
+	return done, len(children)
+}
+
+func progressLabel(done, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", done, total)
+}
+
+func progressSuffix(done, total int) string {
+	label := progressLabel(done, total)
+	if label == "" {
+		return ""
+	}
+	return " " + gray("["+label+"]")
+}
+
+// completeSubtasks completes the pending subtasks of parentID.
+func completeSubtasks(app *App, listID, parentID string) error {
+	items, err := app.Tasks.ListTasksWithOptions(listID, false, false, false, "")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item == nil || item.Parent != parentID || strings.EqualFold(item.Status, "completed") {
+			continue
+		}
+		if _, err := app.Tasks.CompleteTask(listID, item.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSubtaskParent checks that parentID can hold subtasks. A task still
+// nested under a legacy section is moved to the top level first, keeping its
+// section in metadata.
+func resolveSubtaskParent(app *App, listID, parentID string) (*tasks.Task, error) {
+	parent, err := app.Tasks.GetTask(listID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if isSectionTask(parent) {
+		return nil, fmt.Errorf("%q is a section; use --section instead", parent.Title)
+	}
+	if parent.Parent == "" {
+		return parent, nil
+	}
+	grandparent, err := app.Tasks.GetTask(listID, parent.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if !isSectionTask(grandparent) {
+		return nil, fmt.Errorf("%q is already a subtask; subtasks cannot be nested", parent.Title)
+	}
+	if _, err := app.Tasks.MoveTask(listID, parent.Id, "", ""); err != nil {
+		return nil, err
+	}
+	parent.Notes = setNotesSection(parent.Notes, grandparent.Id)
+	return app.Tasks.UpdateTask(listID, parent)
+}
+
+// visibleTaskItems hides the subtasks of collapsed parents.
+func visibleTaskItems(items []list.Item, expanded map[string]bool) []list.Item {
+	visible := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		task, ok := item.(taskItem)
+		if !ok {
+			visible = append(visible, item)
+			continue
+		}
+		if task.ParentTaskID != "" && !expanded[task.ParentTaskID] {
+			continue
+		}
+		if task.SubtasksTotal > 0 {
+			task.Expanded = expanded[task.ID]
+			item = task
+		}
+		visible = append(visible, item)
+	}
+	return visible
+}
+
+func (m *tuiModel) toggleSubtasks() {
+	task, ok := m.selectedTask()
+	if !ok {
+		return
+	}
+	parentID := task.ID
+	if task.ParentTaskID != "" {
+		parentID = task.ParentTaskID
+	} else if task.SubtasksTotal == 0 {
+		m.status = "No subtasks"
+		return
+	}
+	if m.expanded == nil {
+		m.expanded = map[string]bool{}
+	}
+	m.expanded[parentID] = !m.expanded[parentID]
+	m.tasksList.SetItems(visibleTaskItems(m.taskItems, m.expanded))
+	m.applySelectionMarks()
+	selectTaskItem(&m.tasksList, parentID)
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestGroupTasksBySectionAttachesSubtasks(t *testing.T) {
+	sectionID := "section-1"
+	items := []*tasks.Task{
+		{Id: sectionID, Title: "Errands", Notes: "justdoit_section=1"},
+		{Id: "parent", Title: "Pack", Notes: setNotesSection("", sectionID), Position: "1"},
+		{Id: "legacy", Title: "Groceries", Parent: sectionID, Position: "2"},
+		{Id: "sub-1", Title: "Socks", Parent: "parent", Position: "1", Status: "completed"},
+		{Id: "sub-2", Title: "Charger", Parent: "parent", Position: "2"},
+	}
+
+	sections, _ := groupTasksBySection(items, "", false, time.UTC)
+	rows := sections["Errands"]
+	if len(rows) != 2 {
+		t.Fatalf("expected parent and legacy task in section, got %#v", rows)
+	}
+	var parent taskRow
+	for _, row := range rows {
+		if row.ID == "parent" {
+			parent = row
+		}
+	}
+	if parent.Done != 1 || parent.Total != 2 {
+		t.Fatalf("expected 1/2 progress, got %d/%d", parent.Done, parent.Total)
+	}
+	if len(parent.Subtasks) != 1 || parent.Subtasks[0].Title != "Charger" {
+		t.Fatalf("expected only pending subtask, got %#v", parent.Subtasks)
+	}
+}
+
+func TestBuildNextItemsNestsSubtasksUnderParent(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, loc)
+	due := time.Date(2026, 1, 3, 18, 0, 0, 0, loc).Format(time.RFC3339)
+	items := []*tasks.Task{
+		{Id: "parent", Title: "Pack", Status: "needsAction", Due: due},
+		{Id: "sub-1", Title: "Socks", Status: "completed", Parent: "parent"},
+		{Id: "sub-2", Title: "Charger", Status: "needsAction", Parent: "parent"},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Work": "list-1"},
+		Location: loc,
+		Now:      func() time.Time { return now },
+	}
+
+	result, err := buildNextItems(ctx, false)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	tasksOnly := []taskItem{}
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && !task.IsHeader {
+			tasksOnly = append(tasksOnly, task)
+		}
+	}
+	if len(tasksOnly) != 2 {
+		t.Fatalf("expected parent and one pending subtask, got %#v", tasksOnly)
+	}
+	if tasksOnly[0].ID != "parent" || tasksOnly[0].SubtasksDone != 1 || tasksOnly[0].SubtasksTotal != 2 {
+		t.Fatalf("unexpected parent item %#v", tasksOnly[0])
+	}
+	if tasksOnly[1].ID != "sub-2" || tasksOnly[1].ParentTaskID != "parent" {
+		t.Fatalf("unexpected subtask item %#v", tasksOnly[1])
+	}
+
+	visible := visibleTaskItems(result, nil)
+	if len(visible) != len(result)-1 {
+		t.Fatalf("expected collapsed parent to hide its subtask")
+	}
+	visible = visibleTaskItems(result, map[string]bool{"parent": true})
+	if len(visible) != len(result) {
+		t.Fatalf("expected expanded parent to show its subtask")
+	}
+	if parent, ok := visible[1].(taskItem); !ok || !parent.Expanded {
+		t.Fatalf("expected parent to be marked expanded, got %#v", visible[1])
+	}
+}
+
+type recordingTaskProvider struct {
+	fakeTaskProvider
+	completedFetches *[]string
+}
+
+func (r recordingTaskProvider) ListTasksWithOptions(listID string, showCompleted, showHidden, showDeleted bool, updatedMin string) ([]*tasks.Task, error) {
+	if showCompleted {
+		*r.completedFetches = append(*r.completedFetches, listID+"@"+updatedMin)
+	}
+	return r.fakeTaskProvider.ListTasksWithOptions(listID, showCompleted, showHidden, showDeleted, updatedMin)
+}
+
+func TestBuildNextItemsFetchesCompletedOnlyForParents(t *testing.T) {
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC)
+	fetches := []string{}
+	provider := recordingTaskProvider{
+		fakeTaskProvider: fakeTaskProvider{lists: map[string][]*tasks.Task{
+			"flat": {{Id: "a", Title: "Alone", Status: "needsAction"}, {Id: "old", Title: "Old", Status: "completed"}},
+			"nested": {
+				{Id: "p", Title: "Parent", Status: "needsAction"},
+				{Id: "c1", Title: "Child", Status: "needsAction", Parent: "p"},
+				{Id: "c2", Title: "Done child", Status: "completed", Parent: "p"},
+			},
+		}},
+		completedFetches: &fetches,
+	}
+	ctx := queryContext{
+		Tasks:    provider,
+		Lists:    map[string]string{"Flat": "flat", "Nested": "nested"},
+		Location: time.UTC,
+		Now:      func() time.Time { return now },
+	}
+	result, err := buildNextItems(ctx, true)
+	if err != nil {
+		t.Fatalf("buildNextItems error: %v", err)
+	}
+	want := []string{"nested@" + now.Add(-subtaskProgressWindow).Format(time.RFC3339)}
+	if !reflect.DeepEqual(fetches, want) {
+		t.Fatalf("completed fetches = %v, want %v", fetches, want)
+	}
+	for _, item := range result {
+		if task, ok := item.(taskItem); ok && task.ID == "p" && (task.SubtasksDone != 1 || task.SubtasksTotal != 2) {
+			t.Fatalf("unexpected progress %d/%d", task.SubtasksDone, task.SubtasksTotal)
+		}
+	}
+}
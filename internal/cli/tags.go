@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"justdoit/internal/config"
+	"justdoit/internal/metadata"
+)
+
+var taskTags = metadata.List("justdoit_tags")
+
+func newTagsCmd() *cobra.Command {
+	var (
+		list string
+		all  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "List tags with task counts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := initApp(cmd)
+			if err != nil {
+				return err
+			}
+			lists := app.Config.Lists
+			if strings.TrimSpace(list) != "" {
+				listID, err := resolveListID(app, list, true)
+				if err != nil {
+					return err
+				}
+				lists = map[string]string{list: listID}
+			}
+			counts := map[string]int{}
+			for _, name := range sortedListNames(lists) {
+				items, err := app.Tasks.ListTasksWithOptions(lists[name], all, all, false, "")
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					if item == nil || isSectionTask(item) {
+						continue
+					}
+					if !all && item.Status == "completed" {
+						continue
+					}
+					for _, tag := range notesTags(item.Notes) {
+						counts[tag]++
+					}
+				}
+			}
+			if len(counts) == 0 {
+				fmt.Println("(no tags)")
+				return nil
+			}
+			tags := make([]string, 0, len(counts))
+			for tag := range counts {
+				tags = append(tags, tag)
+			}
+			sort.Strings(tags)
+			for _, tag := range tags {
+				fmt.Printf("+%s (%d)\n", tag, counts[tag])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&list, "list", "", "Only count tasks in this list")
+	cmd.Flags().BoolVar(&all, "all", false, "Include completed/hidden tasks")
+	return cmd
+}
+
+// normalizeTag lowercases a tag and drops the leading "+". Tags may contain
+// letters, digits, "-" and "_".
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "+"))
+	if tag == "" {
+		return "", false
+	}
+	for _, r := range tag {
+		if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127 {
+			continue
+		}
+		return "", false
+	}
+	return tag, true
+}
+
+func parseTags(values []string) ([]string, error) {
+	tags := []string{}
+	for _, value := range values {
+		for _, raw := range strings.Split(value, ",") {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			tag, ok := normalizeTag(raw)
+			if !ok {
+				return nil, fmt.Errorf("invalid tag: %s", raw)
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func isTagToken(token string) bool {
+	if !strings.HasPrefix(token, "+") || len(token) < 2 {
+		return false
+	}
+	_, ok := normalizeTag(token)
+	return ok
+}
+
+func notesTags(notes string) []string {
+	tags, _ := taskTags.Get(notes)
+	return tags
+}
+
+// setNotesTags adds and removes tags, keeping the existing order.
+func setNotesTags(notes string, add, remove []string) string {
+	drop := map[string]bool{}
+	for _, tag := range remove {
+		drop[tag] = true
+	}
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, tag := range append(notesTags(notes), add...) {
+		if drop[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return taskTags.Remove(notes)
+	}
+	return taskTags.Set(notes, tags)
+}
+
+func hasAllTags(tags, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, tag := range tags {
+			if tag == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func tagsSuffix(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " " + gray(formatTags(tags))
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		parts = append(parts, "+"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// tagEventColor returns the calendar color for the first mapped tag.
+func tagEventColor(cfg *config.Config, tags []string) string {
+	if cfg == nil {
+		return ""
+	}
+	for _, tag := range tags {
+		if color, ok := cfg.TagColors[tag]; ok {
+			return color
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestSetNotesTags(t *testing.T) {
+	notes := setNotesTags("Pick up keys", []string{"errand", "home"}, nil)
+	if got := notesTags(notes); !reflect.DeepEqual(got, []string{"errand", "home"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	if stripMetadataNotes(notes) != "Pick up keys" {
+		t.Fatalf("expected tags to be hidden from notes, got %q", stripMetadataNotes(notes))
+	}
+	notes = setNotesTags(notes, []string{"home", "deep-work"}, []string{"errand"})
+	if got := notesTags(notes); !reflect.DeepEqual(got, []string{"home", "deep-work"}) {
+		t.Fatalf("unexpected tags after update %v", got)
+	}
+	notes = setNotesTags(notes, nil, []string{"home", "deep-work"})
+	if taskTags.Has(notes) {
+		t.Fatalf("expected tags to be removed, got %q", notes)
+	}
+}
+
+func TestParseQuickCaptureTags(t *testing.T) {
+	now := time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC)
+	parsed, err := parseQuickCapture("Buy stamps +Errand +deep-work 2+2", now, time.UTC)
+	if err != nil {
+		t.Fatalf("parseQuickCapture error: %v", err)
+	}
+	if parsed.Title != "Buy stamps 2+2" {
+		t.Fatalf("unexpected title %q", parsed.Title)
+	}
+	if !reflect.DeepEqual(parsed.Tags, []string{"errand", "deep-work"}) {
+		t.Fatalf("unexpected tags %v", parsed.Tags)
+	}
+}
+
+func TestSearchTasksFiltersByTag(t *testing.T) {
+	loc := time.UTC
+	items := []*tasks.Task{
+		{Id: "1", Title: "Post letter", Status: "needsAction", Notes: setNotesTags("", []string{"errand"}, nil)},
+		{Id: "2", Title: "Write letter", Status: "needsAction", Notes: setNotesTags("", []string{"deep-work"}, nil)},
+		{Id: "3", Title: "Buy bread", Status: "needsAction", Notes: setNotesTags("", []string{"errand"}, nil)},
+	}
+	ctx := queryContext{
+		Tasks:    fakeTaskProvider{lists: map[string][]*tasks.Task{"list-1": items}},
+		Lists:    map[string]string{"Home": "list-1"},
+		Location: loc,
+	}
+
+	results, err := searchTasks(ctx, "+errand", "", false)
+	if err != nil {
+		t.Fatalf("searchTasks error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected two errands, got %#v", results)
+	}
+
+	results, err = searchTasks(ctx, "letter +errand", "", false)
+	if err != nil {
+		t.Fatalf("searchTasks error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Fatalf("expected text and tag to combine, got %#v", results)
+	}
+}
@@ -15,16 +15,32 @@ import (
 )
 
 type UpdateParams struct {
-	Title      string
-	HasTitle   bool
-	Notes      string
-	HasNotes   bool
-	Section    string
-	HasSection bool
-	Date       string
-	HasDate    bool
-	Time       string
-	HasTime    bool
+	Title       string
+	HasTitle    bool
+	Notes       string
+	HasNotes    bool
+	Section     string
+	HasSection  bool
+	Date        string
+	HasDate     bool
+	Time        string
+	HasTime     bool
+	Priority    int
+	HasPriority bool
+	AddTags     []string
+	RemoveTags  []string
+	// At moves the task to a point in time; a linked block keeps its length.
+	At    time.Time
+	HasAt bool
+	// ShiftDays and Shift move the due date and linked block relative to
+	// where they are now (snooze +2d, +3h).
+	ShiftDays int
+	Shift     time.Duration
+	HasShift  bool
+	// Start defers the task until a day without moving its due date; a zero
+	// Start clears it.
+	Start    time.Time
+	HasStart bool
 }
 
 type UpdateResult struct {
@@ -52,6 +68,19 @@ func updateTaskWithParams(app *App, listID, taskID string, params UpdateParams)
 		task.Notes = mergeNotes(params.Notes, task.Notes)
 	}
 
+	if params.HasPriority {
+		task.Notes = setNotesPriority(task.Notes, params.Priority)
+	}
+
+	if params.HasStart {
+		task.Notes = setNotesStartDate(task.Notes, params.Start)
+	}
+
+	tagsChanged := len(params.AddTags) > 0 || len(params.RemoveTags) > 0
+	if tagsChanged {
+		task.Notes = setNotesTags(task.Notes, params.AddTags, params.RemoveTags)
+	}
+
 	var (
 		event       *calendar.Event
 		eventExists bool
@@ -61,28 +90,41 @@ func updateTaskWithParams(app *App, listID, taskID string, params UpdateParams)
 	)
 
 	if params.HasSection {
-		sectionName := strings.TrimSpace(params.Section)
-		if sectionName == "" {
-			if _, err := app.Tasks.MoveTask(listID, taskID, ""); err != nil {
+		// Section membership lives in metadata; a task still nested under a
+		// legacy section task is moved back to the top level.
+		if task.Parent != "" {
+			parent, err := app.Tasks.GetTask(listID, task.Parent)
+			if err != nil {
 				return result, err
 			}
-			result.SectionChanged = true
-		} else {
-			sectionTask, err := ensureSectionTask(app, listID, sectionName)
-			if err != nil {
+			if !isSectionTask(parent) {
+				return result, fmt.Errorf("%q is a subtask; it follows its parent's section", task.Title)
+			}
+			if _, err := app.Tasks.MoveTask(listID, taskID, "", ""); err != nil {
 				return result, err
 			}
-			if _, err := app.Tasks.MoveTask(listID, taskID, sectionTask.Id); err != nil {
+			task.Parent = ""
+		}
+		sectionID := ""
+		if sectionName := strings.TrimSpace(params.Section); sectionName != "" {
+			sectionTask, err := ensureSectionTask(app, listID, sectionName)
+			if err != nil {
 				return result, err
 			}
-			result.SectionChanged = true
+			sectionID = sectionTask.Id
 		}
+		task.Notes = setNotesSection(task.Notes, sectionID)
+		result.SectionChanged = true
 	}
 
-	if params.HasTime || params.HasDate || params.HasTitle {
+	if params.HasTime || params.HasDate || params.HasAt || params.HasShift || params.HasTitle || tagsChanged {
 		event, eventExists, _ = findLinkedEvent(app, task)
 	}
 
+	if params.HasAt || params.HasShift {
+		newStart, newEnd, newDue = snoozeTimes(app, task, event, params)
+	}
+
 	if params.HasTime || params.HasDate {
 		baseDate := resolveBaseDate(app, task, event, params.Date)
 		if params.HasTime {
@@ -124,6 +166,14 @@ func updateTaskWithParams(app *App, listID, taskID string, params UpdateParams)
 		result.EventRenamed = true
 	}
 
+	colorChanged := false
+	if tagsChanged && eventExists && event != nil {
+		if color := tagEventColor(app.Config, notesTags(task.Notes)); color != "" && color != event.ColorId {
+			event.ColorId = color
+			colorChanged = true
+		}
+	}
+
 	if newStart != nil && newEnd != nil {
 		if eventExists && event != nil {
 			event.Start.DateTime = newStart.Format(time.RFC3339)
@@ -137,14 +187,14 @@ func updateTaskWithParams(app *App, listID, taskID string, params UpdateParams)
 			if err != nil {
 				return result, err
 			}
-			updatedNotes := metadata.Append(task.Notes, sync.TaskEventIDKey, created.Id)
+			updatedNotes := sync.TaskEventID.Set(task.Notes, created.Id)
 			task.Notes = updatedNotes
 			if _, err := app.Tasks.UpdateTask(listID, task); err != nil {
 				return result, err
 			}
 			result.EventUpdated = true
 		}
-	} else if result.EventRenamed && eventExists && event != nil {
+	} else if (result.EventRenamed || colorChanged) && eventExists && event != nil {
 		if _, err := app.Calendar.UpdateEvent(app.Config.CalendarID, event); err != nil {
 			return result, err
 		}
@@ -153,22 +203,34 @@ func updateTaskWithParams(app *App, listID, taskID string, params UpdateParams)
 	return result, nil
 }
 
-func markTaskDone(app *App, listID, taskID string, markEvent bool) error {
+// markTaskDone completes a task and, when unblocks is set, returns the tasks
+// it was the last open blocker for.
+func markTaskDone(app *App, listID, taskID string, markEvent bool, unblocks *unblockTracker) ([]graphNode, error) {
 	task, err := app.Tasks.GetTask(listID, taskID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	event, _, _ := findLinkedEvent(app, task)
+	if !isSectionTask(task) {
+		if err := completeSubtasks(app, listID, taskID); err != nil {
+			return nil, err
+		}
+	}
 	if _, err := app.Tasks.CompleteTask(listID, taskID); err != nil {
-		return err
+		return nil, err
 	}
-	if !markEvent {
-		return createNextRecurringTask(app, listID, task, event)
+	if markEvent {
+		if err := updateLinkedEventPrefix(app, task, true); err != nil {
+			return nil, err
+		}
 	}
-	if err := updateLinkedEventPrefix(app, task, true); err != nil {
-		return err
+	if err := createNextRecurringTask(app, listID, task, event); err != nil {
+		return nil, err
 	}
-	return createNextRecurringTask(app, listID, task, event)
+	if unblocks == nil {
+		return nil, nil
+	}
+	return unblocks.completed(taskID), nil
 }
 
 func markTaskUndone(app *App, listID, taskID string, markEvent bool) error {
@@ -179,6 +241,9 @@ func markTaskUndone(app *App, listID, taskID string, markEvent bool) error {
 	if _, err := app.Tasks.UncompleteTask(listID, taskID); err != nil {
 		return err
 	}
+	if err := removeSpawnedRecurringTask(app, listID, task); err != nil {
+		return err
+	}
 	if !markEvent {
 		return nil
 	}
@@ -191,7 +256,7 @@ func deleteTask(app *App, listID, taskID string, deleteEvent bool) error {
 		return err
 	}
 	if deleteEvent {
-		if eventID, ok := sync.ExtractMetadata(task.Notes, sync.TaskEventIDKey); ok {
+		if eventID, ok := sync.TaskEventID.Get(task.Notes); ok {
 			_ = app.Calendar.DeleteEvent(app.Config.CalendarID, eventID)
 		} else if event, err := app.Calendar.FindEventByTaskID(app.Config.CalendarID, taskID); err == nil {
 			_ = app.Calendar.DeleteEvent(app.Config.CalendarID, event.Id)
@@ -214,8 +279,16 @@ func toggleTaskDone(app *App, listID, taskID string, markEvent bool) (bool, erro
 		if markEvent {
 			_ = updateLinkedEventPrefix(app, task, false)
 		}
+		if err := removeSpawnedRecurringTask(app, listID, task); err != nil {
+			return false, err
+		}
 		return false, nil
 	}
+	if !isSectionTask(task) {
+		if err := completeSubtasks(app, listID, taskID); err != nil {
+			return false, err
+		}
+	}
 	if _, err := app.Tasks.CompleteTask(listID, taskID); err != nil {
 		return false, err
 	}
@@ -254,32 +327,14 @@ func updateLinkedEventPrefix(app *App, task *tasks.Task, completed bool) error {
 }
 
 func stripMetadataNotes(notes string) string {
-	lines := strings.Split(notes, "\n")
-	filtered := make([]string, 0, len(lines))
-	for _, line := range lines {
-		trim := strings.TrimSpace(line)
-		if strings.HasPrefix(trim, sync.TaskEventIDKey+"=") {
-			continue
-		}
-		if strings.HasPrefix(trim, sync.EventTaskIDKey+"=") {
-			continue
-		}
-		if strings.HasPrefix(trim, "justdoit_rrule=") {
-			continue
-		}
-		if strings.HasPrefix(trim, "justdoit_section=") {
-			continue
-		}
-		filtered = append(filtered, line)
-	}
-	return strings.TrimSpace(strings.Join(filtered, "\n"))
+	return metadata.Strip(notes)
 }
 
 func createNextRecurringTask(app *App, listID string, task *tasks.Task, event *calendar.Event) error {
 	if task == nil || app == nil {
 		return nil
 	}
-	rule, ok := metadata.Extract(task.Notes, "justdoit_rrule")
+	rule, ok := sync.TaskRRule.Get(task.Notes)
 	if !ok || strings.TrimSpace(rule) == "" {
 		return nil
 	}
@@ -289,6 +344,7 @@ func createNextRecurringTask(app *App, listID string, task *tasks.Task, event *c
 	if err != nil || !ok || nextStart.IsZero() {
 		return err
 	}
+	seriesID := recurringSeriesID(task)
 
 	var (
 		start *time.Time
@@ -306,16 +362,33 @@ func createNextRecurringTask(app *App, listID string, task *tasks.Task, event *c
 		due = &endOfDay
 	}
 
-	notes := stripMetadataNotes(task.Notes)
+	// Completing the same instance twice (toggling in the TUI, or from two
+	// devices) must not create a second copy of the next occurrence.
+	items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+	if err != nil {
+		return err
+	}
+	if findSpawnedInstance(items, task.Id, seriesID, *due, app.Location) != nil {
+		return nil
+	}
+
+	notes := setNotesPriority(stripMetadataNotes(task.Notes), notesPriority(task.Notes))
+	notes = setNotesTags(notes, notesTags(task.Notes), nil)
+	if sectionID, ok := taskSectionRef.Get(task.Notes); ok {
+		notes = setNotesSection(notes, sectionID)
+	}
 	input := sync.CreateInput{
-		ListID:     listID,
-		Title:      task.Title,
-		Notes:      notes,
-		Due:        due,
-		Recurrence: []string{rule},
-		TimeStart:  start,
-		TimeEnd:    end,
-		ParentID:   task.Parent,
+		ListID:      listID,
+		Title:       task.Title,
+		Notes:       notes,
+		Due:         due,
+		Recurrence:  []string{rule},
+		TimeStart:   start,
+		TimeEnd:     end,
+		ParentID:    task.Parent,
+		SeriesID:    seriesID,
+		SpawnedFrom: task.Id,
+		ColorID:     tagEventColor(app.Config, notesTags(task.Notes)),
 	}
 	if event != nil && len(event.Recurrence) > 0 {
 		input.TimeStart = nil
@@ -325,6 +398,64 @@ func createNextRecurringTask(app *App, listID string, task *tasks.Task, event *c
 	return err
 }
 
+// recurringSeriesID returns the series a recurring task belongs to. Tasks
+// created before series were tracked use their own ID as the series.
+func recurringSeriesID(task *tasks.Task) string {
+	if seriesID, ok := sync.TaskSeriesID.Get(task.Notes); ok && strings.TrimSpace(seriesID) != "" {
+		return strings.TrimSpace(seriesID)
+	}
+	return task.Id
+}
+
+// findSpawnedInstance looks for the occurrence that follows sourceID: either a
+// task explicitly spawned from it, or another task of the same series due on
+// the same day.
+func findSpawnedInstance(items []*tasks.Task, sourceID, seriesID string, due time.Time, loc *time.Location) *tasks.Task {
+	for _, item := range items {
+		if item == nil || item.Id == sourceID || item.Deleted {
+			continue
+		}
+		if from, ok := sync.TaskSpawnedFrom.Get(item.Notes); ok && from == sourceID {
+			return item
+		}
+		if seriesID == "" || recurringSeriesID(item) != seriesID {
+			continue
+		}
+		itemDue, hasDue, _ := parseTaskDue(item.Due, loc)
+		if hasDue && sameDay(itemDue, due.In(loc)) {
+			return item
+		}
+	}
+	return nil
+}
+
+// removeSpawnedRecurringTask deletes the pending occurrence created when task
+// was completed, so reopening it leaves a single open instance.
+func removeSpawnedRecurringTask(app *App, listID string, task *tasks.Task) error {
+	if task == nil || app == nil {
+		return nil
+	}
+	if !sync.TaskRRule.Has(task.Notes) {
+		return nil
+	}
+	items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item == nil || item.Deleted || strings.EqualFold(item.Status, "completed") {
+			continue
+		}
+		if from, ok := sync.TaskSpawnedFrom.Get(item.Notes); !ok || from != task.Id {
+			continue
+		}
+		if err := deleteTask(app, listID, item.Id, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func taskTiming(app *App, task *tasks.Task, event *calendar.Event) (time.Time, time.Duration) {
 	if event != nil {
 		start, end := eventTimes(event, app.Location)
@@ -349,7 +480,7 @@ func ensureSectionTask(app *App, listID, section string) (*tasks.Task, error) {
 		if item.Title != section {
 			continue
 		}
-		if _, ok := metadata.Extract(item.Notes, "justdoit_section"); ok {
+		if sectionMarker.Has(item.Notes) {
 			return item, nil
 		}
 	}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestFindSpawnedInstance(t *testing.T) {
+	loc := time.UTC
+	due := time.Date(2026, 1, 5, 23, 59, 0, 0, loc)
+	items := []*tasks.Task{
+		{Id: "source", Title: "Water plants", Notes: "justdoit_rrule=RRULE:FREQ=DAILY\njustdoit_series=abc", Due: time.Date(2026, 1, 4, 23, 59, 0, 0, loc).Format(time.RFC3339)},
+		{Id: "other", Title: "Other series", Notes: "justdoit_rrule=RRULE:FREQ=DAILY\njustdoit_series=xyz", Due: due.Format(time.RFC3339)},
+	}
+
+	if got := findSpawnedInstance(items, "source", "abc", due, loc); got != nil {
+		t.Fatalf("expected no instance, got %q", got.Id)
+	}
+
+	items = append(items, &tasks.Task{Id: "next", Title: "Water plants", Notes: "justdoit_series=abc", Due: due.Format(time.RFC3339)})
+	got := findSpawnedInstance(items, "source", "abc", due, loc)
+	if got == nil || got.Id != "next" {
+		t.Fatalf("expected series match on the same day, got %#v", got)
+	}
+
+	spawned := []*tasks.Task{
+		{Id: "moved", Title: "Water plants", Notes: "justdoit_spawned_from=source", Due: due.AddDate(0, 0, 3).Format(time.RFC3339)},
+	}
+	got = findSpawnedInstance(spawned, "source", "abc", due, loc)
+	if got == nil || got.Id != "moved" {
+		t.Fatalf("expected spawned_from match, got %#v", got)
+	}
+}
+
+func TestRecurringSeriesIDFallsBackToTaskID(t *testing.T) {
+	legacy := &tasks.Task{Id: "legacy", Notes: "justdoit_rrule=RRULE:FREQ=WEEKLY"}
+	if got := recurringSeriesID(legacy); got != "legacy" {
+		t.Fatalf("expected task ID as series, got %q", got)
+	}
+	tracked := &tasks.Task{Id: "t1", Notes: "justdoit_series=abc"}
+	if got := recurringSeriesID(tracked); got != "abc" {
+		t.Fatalf("expected stored series, got %q", got)
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/tasks/v1"
 
-	"justdoit/internal/metadata"
+	"justdoit/internal/sync"
 )
 
 type TaskProvider interface {
@@ -31,6 +31,7 @@ type queryContext struct {
 	BacklogExcludedLists []string
 	Location             *time.Location
 	Now                  func() time.Time
+	HideBlocked          bool
 }
 
 func newQueryContext(app *App) queryContext {
@@ -54,6 +55,10 @@ func newQueryContext(app *App) queryContext {
 	}
 }
 
+// subtaskProgressWindow is how far back completed subtasks are counted in
+// the progress shown next to their parent.
+const subtaskProgressWindow = 90 * 24 * time.Hour
+
 func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 	if ctx.Tasks == nil {
 		return nil, fmt.Errorf("task client is not initialized")
@@ -128,52 +133,89 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 		return nil, fmt.Errorf("no lists configured")
 	}
 
+	itemsByList := make(map[string][]*tasks.Task, len(listNames))
+	pending := map[string]bool{}
+	subtasks := map[string][]taskItem{}
 	for _, listName := range listNames {
-		listID := ctx.Lists[listName]
-		items, err := ctx.Tasks.ListTasks(listID, false)
+		items, err := ctx.Tasks.ListTasksWithOptions(ctx.Lists[listName], false, false, false, "")
 		if err != nil {
 			return nil, err
 		}
+		itemsByList[listName] = items
+		for _, item := range items {
+			if item != nil && item.Status != "completed" {
+				pending[item.Id] = true
+			}
+		}
+	}
+	// Subtask progress needs the completed subtasks too. They are fetched only
+	// for lists with an open parent that has open subtasks, and only for the
+	// recent past, so the cost does not grow with the account's history.
+	for _, listName := range listNames {
+		items := itemsByList[listName]
 		sections := buildSectionIndex(items)
+		parents := map[string]bool{}
+		for _, item := range items {
+			if isSubtask(item, sections) && pending[item.Parent] {
+				parents[item.Parent] = true
+			}
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		updatedMin := now.Add(-subtaskProgressWindow).Format(time.RFC3339)
+		done, err := ctx.Tasks.ListTasksWithOptions(ctx.Lists[listName], true, true, false, updatedMin)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range done {
+			if item != nil && item.Status == "completed" && parents[item.Parent] {
+				itemsByList[listName] = append(itemsByList[listName], item)
+			}
+		}
+	}
+
+	for _, listName := range listNames {
+		listID := ctx.Lists[listName]
+		items := itemsByList[listName]
+		sections := buildSectionIndex(items)
+		children := indexSubtasks(items, sections)
 		for _, item := range items {
 			if item == nil || item.Status == "completed" {
 				continue
 			}
-			if isSectionTask(item) {
+			if isSectionTask(item) || isSubtask(item, sections) {
+				continue
+			}
+			if isDeferred(item.Notes, todayStart) {
+				continue
+			}
+			blocked := hasPendingBlocker(item, pending)
+			if blocked && ctx.HideBlocked {
 				continue
 			}
 			section := resolveSectionName(item, sections)
+			row := newNextTaskItem(item, listName, listID, section, ctx.Location)
+			row.Blocked = blocked
+			row.SubtasksDone, row.SubtasksTotal = subtaskProgress(children[item.Id])
+			for _, child := range children[item.Id] {
+				if child.Status == "completed" {
+					continue
+				}
+				sub := newNextTaskItem(child, listName, listID, section, ctx.Location)
+				sub.ParentTaskID = item.Id
+				subtasks[item.Id] = append(subtasks[item.Id], sub)
+			}
 			if item.Due == "" {
 				if showBacklog && !isBacklogExcludedList(listName, ctx.BacklogExcludedLists) {
-					rule, _ := metadata.Extract(item.Notes, "justdoit_rrule")
-					backlog = append(backlog, taskItem{
-						ID:         item.Id,
-						TitleVal:   item.Title,
-						ListName:   listName,
-						ListID:     listID,
-						Section:    section,
-						HasDue:     false,
-						Recurrence: rule,
-					})
+					backlog = append(backlog, row)
 				}
 				continue
 			}
-			due, hasDue, hasTime := parseTaskDue(item.Due, ctx.Location)
-			if !hasDue {
+			if !row.HasDue {
 				continue
 			}
-			rule, _ := metadata.Extract(item.Notes, "justdoit_rrule")
-			row := taskItem{
-				ID:         item.Id,
-				TitleVal:   item.Title,
-				ListName:   listName,
-				ListID:     listID,
-				Section:    section,
-				Due:        due,
-				HasDue:     true,
-				HasTime:    hasTime,
-				Recurrence: rule,
-			}
+			due := row.Due
 
 			switch {
 			case due.Before(todayStart):
@@ -191,6 +233,12 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 	items := []list.Item{}
 	for _, b := range buckets {
 		sort.SliceStable(b.tasks, func(i, j int) bool {
+			if b.tasks[i].Blocked != b.tasks[j].Blocked {
+				return !b.tasks[i].Blocked
+			}
+			if pi, pj := priorityRank(b.tasks[i].Priority), priorityRank(b.tasks[j].Priority); pi != pj {
+				return pi < pj
+			}
 			if b.tasks[i].Due.Equal(b.tasks[j].Due) {
 				if b.tasks[i].ListName == b.tasks[j].ListName {
 					return b.tasks[i].TitleVal < b.tasks[j].TitleVal
@@ -203,49 +251,20 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 			if len(b.tasks) == 0 && len(todayEvents) == 0 {
 				continue
 			}
-			type todayEntry struct {
-				item  list.Item
-				timed bool
-				start time.Time
-				kind  int
-				label string
-			}
 			entries := make([]todayEntry, 0, len(todayEvents)+len(b.tasks))
 			for _, e := range todayEvents {
-				entries = append(entries, todayEntry{
-					item:  e,
-					timed: !e.AllDay,
-					start: e.Start,
-					kind:  0,
-					label: e.Summary,
-				})
+				entries = append(entries, newTodayEntry(e))
 			}
 			for _, t := range b.tasks {
-				entries = append(entries, todayEntry{
-					item:  t,
-					timed: t.HasTime,
-					start: t.Due,
-					kind:  1,
-					label: t.TitleVal,
-				})
+				entries = append(entries, newTodayEntry(t))
 			}
-			sort.SliceStable(entries, func(i, j int) bool {
-				if entries[i].timed != entries[j].timed {
-					return !entries[i].timed
-				}
-				if entries[i].timed {
-					if !entries[i].start.Equal(entries[j].start) {
-						return entries[i].start.Before(entries[j].start)
-					}
-				}
-				if entries[i].kind != entries[j].kind {
-					return entries[i].kind < entries[j].kind
-				}
-				return entries[i].label < entries[j].label
-			})
+			sortTodayEntries(entries)
 			items = append(items, taskItem{TitleVal: b.name, IsHeader: true})
 			for _, entry := range entries {
 				items = append(items, entry.item)
+				if t, ok := entry.item.(taskItem); ok {
+					items = appendSubtaskItems(items, subtasks[t.ID])
+				}
 			}
 			continue
 		}
@@ -255,6 +274,7 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 		items = append(items, taskItem{TitleVal: b.name, IsHeader: true})
 		for _, t := range b.tasks {
 			items = append(items, t)
+			items = appendSubtaskItems(items, subtasks[t.ID])
 		}
 	}
 	if len(items) == 0 {
@@ -262,6 +282,12 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 	}
 	if showBacklog && len(backlog) > 0 {
 		sort.SliceStable(backlog, func(i, j int) bool {
+			if backlog[i].Blocked != backlog[j].Blocked {
+				return !backlog[i].Blocked
+			}
+			if pi, pj := priorityRank(backlog[i].Priority), priorityRank(backlog[j].Priority); pi != pj {
+				return pi < pj
+			}
 			if backlog[i].ListName == backlog[j].ListName {
 				return backlog[i].TitleVal < backlog[j].TitleVal
 			}
@@ -270,11 +296,96 @@ func buildNextItems(ctx queryContext, showBacklog bool) ([]list.Item, error) {
 		items = append(items, taskItem{TitleVal: "Backlog (no date)", IsHeader: true})
 		for _, t := range backlog {
 			items = append(items, t)
+			items = appendSubtaskItems(items, subtasks[t.ID])
 		}
 	}
 	return items, nil
 }
 
+func newNextTaskItem(item *tasks.Task, listName, listID, section string, loc *time.Location) taskItem {
+	rule, _ := sync.TaskRRule.Get(item.Notes)
+	row := taskItem{
+		ID:         item.Id,
+		TitleVal:   item.Title,
+		ListName:   listName,
+		ListID:     listID,
+		Section:    section,
+		Recurrence: rule,
+		Priority:   notesPriority(item.Notes),
+		Tags:       notesTags(item.Notes),
+	}
+	if item.Due != "" {
+		row.Due, row.HasDue, row.HasTime = parseTaskDue(item.Due, loc)
+	}
+	row.Start, _ = notesStartDate(item.Notes, loc)
+	return row
+}
+
+// todayEntry is a task or calendar event in the Today section, with the
+// subtasks printed under it.
+type todayEntry struct {
+	item     list.Item
+	children []list.Item
+	timed    bool
+	start    time.Time
+	kind     int
+	blocked  bool
+	rank     int
+	label    string
+}
+
+func newTodayEntry(item list.Item) todayEntry {
+	switch v := item.(type) {
+	case calendarEventItem:
+		return todayEntry{item: v, timed: !v.AllDay, start: v.Start, kind: 0, label: v.Summary}
+	case taskItem:
+		return todayEntry{item: v, timed: v.HasTime, start: v.Due, kind: 1, blocked: v.Blocked, rank: priorityRank(v.Priority), label: v.TitleVal}
+	}
+	return todayEntry{item: item}
+}
+
+// sortTodayEntries puts untimed entries first, then timed ones by start;
+// events go before tasks, unblocked before blocked, then by priority and
+// title.
+func sortTodayEntries(entries []todayEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].timed != entries[j].timed {
+			return !entries[i].timed
+		}
+		if entries[i].timed {
+			if !entries[i].start.Equal(entries[j].start) {
+				return entries[i].start.Before(entries[j].start)
+			}
+		}
+		if entries[i].kind != entries[j].kind {
+			return entries[i].kind < entries[j].kind
+		}
+		if entries[i].blocked != entries[j].blocked {
+			return !entries[i].blocked
+		}
+		if entries[i].rank != entries[j].rank {
+			return entries[i].rank < entries[j].rank
+		}
+		return entries[i].label < entries[j].label
+	})
+}
+
+func appendSubtaskItems(items []list.Item, children []taskItem) []list.Item {
+	for _, child := range children {
+		items = append(items, child)
+	}
+	return items
+}
+
+func hasPendingBlocker(item *tasks.Task, pending map[string]bool) bool {
+	for _, id := range notesBlockers(item.Notes) {
+		if pending[id] {
+			return true
+		}
+	}
+	return false
+}
+
 func isBacklogExcludedList(listName string, excluded []string) bool {
 	for _, name := range excluded {
 		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(listName)) {
@@ -284,20 +395,41 @@ func isBacklogExcludedList(listName string, excluded []string) bool {
 	return false
 }
 
+// taskFilter selects tasks across lists. Empty fields match everything.
+type taskFilter struct {
+	Text             string
+	Tags             []string
+	List             string
+	Section          string
+	IncludeCompleted bool
+}
+
 func searchTasks(ctx queryContext, query, listFilter string, includeCompleted bool) ([]taskItem, error) {
 	if ctx.Tasks == nil {
 		return nil, fmt.Errorf("task client is not initialized")
 	}
-	if ctx.Location == nil {
-		ctx.Location = time.Local
-	}
-	needle := strings.ToLower(strings.TrimSpace(query))
-	if needle == "" {
+	needle, tagFilter := splitSearchQuery(query)
+	if needle == "" && len(tagFilter) == 0 {
 		return nil, fmt.Errorf("query is required")
 	}
+	return filterTasks(ctx, taskFilter{
+		Text:             needle,
+		Tags:             tagFilter,
+		List:             listFilter,
+		IncludeCompleted: includeCompleted,
+	})
+}
 
+func filterTasks(ctx queryContext, filter taskFilter) ([]taskItem, error) {
+	if ctx.Tasks == nil {
+		return nil, fmt.Errorf("task client is not initialized")
+	}
+	if ctx.Location == nil {
+		ctx.Location = time.Local
+	}
+	needle := filter.Text
 	listMap := map[string]string{}
-	listFilter = strings.TrimSpace(listFilter)
+	listFilter := strings.TrimSpace(filter.List)
 	if listFilter != "" {
 		if id, ok := ctx.Lists[listFilter]; ok {
 			listMap[listFilter] = id
@@ -331,16 +463,23 @@ func searchTasks(ctx queryContext, query, listFilter string, includeCompleted bo
 			if item == nil || isSectionTask(item) {
 				continue
 			}
-			if !includeCompleted && item.Status == "completed" {
+			if !filter.IncludeCompleted && item.Status == "completed" {
 				continue
 			}
 			section := resolveSectionName(item, sections)
-			if !matchesSearch(needle, item, section) {
+			if filter.Section != "" && !strings.EqualFold(section, strings.TrimSpace(filter.Section)) {
+				continue
+			}
+			tags := notesTags(item.Notes)
+			if !hasAllTags(tags, filter.Tags) {
+				continue
+			}
+			if needle != "" && !matchesSearch(needle, item, section) {
 				continue
 			}
 			due, hasDue, hasTime := parseTaskDue(item.Due, ctx.Location)
 			recurrence := ""
-			if rule, ok := metadata.Extract(item.Notes, "justdoit_rrule"); ok {
+			if rule, ok := sync.TaskRRule.Get(item.Notes); ok {
 				recurrence = rule
 			}
 			results = append(results, taskItem{
@@ -353,6 +492,8 @@ func searchTasks(ctx queryContext, query, listFilter string, includeCompleted bo
 				HasDue:     hasDue,
 				HasTime:    hasTime,
 				Recurrence: recurrence,
+				Priority:   notesPriority(item.Notes),
+				Tags:       tags,
 			})
 		}
 	}
@@ -384,7 +525,7 @@ func resolveSectionName(item *tasks.Task, sections map[string]string) string {
 	if item == nil {
 		return "General"
 	}
-	if section, ok := sections[item.Parent]; ok && section != "" {
+	if section, ok := sections[sectionIDOf(item, sections)]; ok && section != "" {
 		return section
 	}
 	return "General"
@@ -394,8 +535,22 @@ func isSectionTask(item *tasks.Task) bool {
 	if item == nil {
 		return false
 	}
-	_, ok := metadata.Extract(item.Notes, "justdoit_section")
-	return ok
+	return sectionMarker.Has(item.Notes)
+}
+
+// splitSearchQuery separates +tag filters from the free-text part of a query.
+func splitSearchQuery(query string) (string, []string) {
+	words := []string{}
+	tags := []string{}
+	for _, field := range strings.Fields(query) {
+		if isTagToken(field) {
+			tag, _ := normalizeTag(field)
+			tags = append(tags, tag)
+			continue
+		}
+		words = append(words, field)
+	}
+	return strings.ToLower(strings.Join(words, " ")), tags
 }
 
 func matchesSearch(query string, item *tasks.Task, section string) bool {
@@ -415,6 +570,9 @@ func sortSearchResults(results []taskItem) {
 		if a.HasDue != b.HasDue {
 			return a.HasDue
 		}
+		if pa, pb := priorityRank(a.Priority), priorityRank(b.Priority); pa != pb {
+			return pa < pb
+		}
 		if a.HasDue && b.HasDue {
 			if !a.Due.Equal(b.Due) {
 				return a.Due.Before(b.Due)
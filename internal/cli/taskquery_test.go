@@ -19,7 +19,16 @@ func (f fakeTaskProvider) ListTasks(listID string, showCompleted bool) ([]*tasks
 }
 
 func (f fakeTaskProvider) ListTasksWithOptions(listID string, showCompleted, showHidden, showDeleted bool, updatedMin string) ([]*tasks.Task, error) {
-	return f.lists[listID], nil
+	if showCompleted {
+		return f.lists[listID], nil
+	}
+	pending := []*tasks.Task{}
+	for _, item := range f.lists[listID] {
+		if item.Status != "completed" {
+			pending = append(pending, item)
+		}
+	}
+	return pending, nil
 }
 
 type fakeCalendarProvider struct {
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+	"golang.org/x/term"
+)
+
+// maxTitleChoices caps the candidates offered by the picker and listed in
+// ambiguity errors.
+const maxTitleChoices = 10
+
+// Title match scores, best first. Within a tier, closer matches score higher.
+const (
+	scoreExact       = 1000
+	scoreExactFold   = 900
+	scoreSubstring   = 700
+	scoreTokens      = 500
+	scoreSubsequence = 300
+	scoreTypo        = 200
+)
+
+type taskTitleMatch struct {
+	ListName string
+	ListID   string
+	ID       string
+	Title    string
+	Section  string
+	Score    int
+}
+
+// titleQuery selects a task by title instead of ID.
+type titleQuery struct {
+	Text    string
+	Section string
+	// First picks the best match without asking.
+	First bool
+	// Strict only accepts exact (case-sensitive) titles and never prompts.
+	Strict bool
+}
+
+func (q titleQuery) isSet() bool {
+	return strings.TrimSpace(q.Text) != ""
+}
+
+// resolveTaskByTitle finds the task q describes in listName, or in every
+// configured list when listName is empty. A single clear winner is used
+// directly; otherwise the user picks from the ranked matches.
+func resolveTaskByTitle(app *App, listName string, q titleQuery, includeCompleted bool) (bulkTarget, error) {
+	text := strings.TrimSpace(q.Text)
+	matches, err := findTasksByTitle(app, listName, text, strings.TrimSpace(q.Section), includeCompleted)
+	if err != nil {
+		return bulkTarget{}, err
+	}
+	if q.Strict {
+		exact := []taskTitleMatch{}
+		for _, match := range matches {
+			if match.Score == scoreExact {
+				exact = append(exact, match)
+			}
+		}
+		matches = exact
+	}
+	if len(matches) == 0 {
+		if q.Section != "" {
+			return bulkTarget{}, fmt.Errorf("no task matches %q in section %q", text, q.Section)
+		}
+		return bulkTarget{}, fmt.Errorf("no task matches %q", text)
+	}
+	choice, err := chooseTitleMatch(text, matches, q, stdinIsTerminal())
+	if err != nil {
+		return bulkTarget{}, err
+	}
+	return choice.target(), nil
+}
+
+// chooseTitleMatch takes the clear winner, or the best match with --first.
+// Anything looser, even a lone typo match, is offered in the picker when
+// interactive and is an error otherwise.
+func chooseTitleMatch(text string, matches []taskTitleMatch, q titleQuery, interactive bool) (taskTitleMatch, error) {
+	if q.First || clearWinner(matches) {
+		return matches[0], nil
+	}
+	if len(matches) > maxTitleChoices {
+		matches = matches[:maxTitleChoices]
+	}
+	if q.Strict || !interactive {
+		hint := "use a task ID, --first or a more specific title"
+		if q.Strict {
+			hint = "use a task ID instead"
+		}
+		if len(matches) == 1 {
+			return taskTitleMatch{}, fmt.Errorf("no task is titled %q; %s:\n- %s", text, hint, matches[0].target().label())
+		}
+		return taskTitleMatch{}, fmt.Errorf("multiple tasks match %q; %s:\n- %s", text, hint, strings.Join(titleMatchLabels(matches), "\n- "))
+	}
+	return pickTitleMatch(text, matches)
+}
+
+func (m taskTitleMatch) target() bulkTarget {
+	return bulkTarget{ListName: m.ListName, ListID: m.ListID, ID: m.ID, Title: m.Title, Section: m.Section}
+}
+
+// clearWinner reports whether the best match is the only one with an exact
+// (case-insensitive) title.
+func clearWinner(matches []taskTitleMatch) bool {
+	return matches[0].Score >= scoreExactFold && (len(matches) == 1 || matches[1].Score < scoreExactFold)
+}
+
+func titleMatchLabels(matches []taskTitleMatch) []string {
+	labels := make([]string, 0, len(matches))
+	for _, match := range matches {
+		labels = append(labels, match.target().label())
+	}
+	return labels
+}
+
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	if fd > math.MaxInt {
+		return false
+	}
+	return term.IsTerminal(int(fd))
+}
+
+func pickTitleMatch(text string, matches []taskTitleMatch) (taskTitleMatch, error) {
+	if len(matches) == 1 {
+		fmt.Fprintf(os.Stderr, "No task is titled %q. Did you mean:\n", text)
+	} else {
+		fmt.Fprintf(os.Stderr, "Multiple tasks match %q. Select one:\n", text)
+	}
+	for i, label := range titleMatchLabels(matches) {
+		fmt.Fprintf(os.Stderr, "%d) %s %s\n", i+1, label, gray(fmt.Sprintf("(score %d)", matches[i].Score)))
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "Select task number (1-%d) or 0 to cancel: ", len(matches))
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && line == "" {
+			return taskTitleMatch{}, readErr
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		choice, convErr := strconv.Atoi(line)
+		if convErr != nil {
+			fmt.Fprintln(os.Stderr, "Invalid input. Enter a number.")
+			continue
+		}
+		if choice == 0 {
+			return taskTitleMatch{}, errors.New("canceled")
+		}
+		if choice < 1 || choice > len(matches) {
+			fmt.Fprintln(os.Stderr, "Out of range.")
+			continue
+		}
+		return matches[choice-1], nil
+	}
+}
+
+// findTasksByTitle ranks the tasks of listName (or all configured lists)
+// against text, best match first.
+func findTasksByTitle(app *App, listName, text, section string, includeCompleted bool) ([]taskTitleMatch, error) {
+	lists := map[string]string{}
+	if listName != "" {
+		listID, err := resolveListID(app, listName, true)
+		if err != nil {
+			return nil, err
+		}
+		lists[listNameForID(app, listID)] = listID
+	} else {
+		for _, name := range listSearchOrder(app) {
+			lists[name] = app.Config.Lists[name]
+		}
+	}
+	matches := []taskTitleMatch{}
+	for name, listID := range lists {
+		items, err := app.Tasks.ListTasksWithOptions(listID, includeCompleted, false, false, "")
+		if err != nil {
+			return nil, err
+		}
+		index := buildSectionIndex(items)
+		for _, item := range items {
+			if item == nil || isSectionTask(item) {
+				continue
+			}
+			score, ok := scoreTitle(text, item.Title)
+			if !ok {
+				continue
+			}
+			sectionName := "General"
+			if title, ok := index[sectionIDOf(item, index)]; ok {
+				sectionName = title
+			}
+			if section != "" && !strings.EqualFold(sectionName, section) {
+				continue
+			}
+			matches = append(matches, taskTitleMatch{
+				ListName: name,
+				ListID:   listID,
+				ID:       item.Id,
+				Title:    item.Title,
+				Section:  sectionName,
+				Score:    score,
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Title != matches[j].Title {
+			return matches[i].Title < matches[j].Title
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches, nil
+}
+
+// scoreTitle rates how well title matches query: exact titles first, then
+// substrings, all words present in any order, in-order characters (fuzzy)
+// and finally words within one or two typos.
+func scoreTitle(query, title string) (int, bool) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return 0, false
+	}
+	if title == query {
+		return scoreExact, true
+	}
+	q := strings.ToLower(query)
+	t := strings.ToLower(strings.TrimSpace(title))
+	if t == q {
+		return scoreExactFold, true
+	}
+	// Shorter titles rank higher within a tier: the query covers more of them.
+	closeness := 99 - min(len(t)-len(q), 99)
+	if strings.Contains(t, q) {
+		if strings.HasPrefix(t, q) {
+			return scoreSubstring + 100 - 1, true
+		}
+		return scoreSubstring + closeness, true
+	}
+	queryWords := strings.Fields(q)
+	titleWords := strings.Fields(t)
+	if containsAllWords(titleWords, queryWords) {
+		return scoreTokens + closeness, true
+	}
+	if found := fuzzy.Find(q, []string{t}); len(found) > 0 {
+		return scoreSubsequence + max(min(found[0].Score, 99), 0), true
+	}
+	if distance, ok := typoDistance(titleWords, queryWords); ok {
+		return scoreTypo - distance*10, true
+	}
+	return 0, false
+}
+
+func containsAllWords(titleWords, queryWords []string) bool {
+	for _, word := range queryWords {
+		found := false
+		for _, candidate := range titleWords {
+			if strings.Contains(candidate, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// typoDistance matches every query word to a title word within one typo (two
+// for words of six letters or more) and returns the total edit distance.
+func typoDistance(titleWords, queryWords []string) (int, bool) {
+	total := 0
+	for _, word := range queryWords {
+		allowed := 1
+		if len([]rune(word)) >= 6 {
+			allowed = 2
+		}
+		best := allowed + 1
+		for _, candidate := range titleWords {
+			if d := editDistance(word, candidate); d < best {
+				best = d
+			}
+		}
+		if best > allowed {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
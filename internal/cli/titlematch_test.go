@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreTitleRanksMatchKinds(t *testing.T) {
+	ordered := []string{
+		"Buy milk",          // exact
+		"buy MILK",          // exact, ignoring case
+		"Buy milk and eggs", // substring
+		"Milk: buy oat",     // all words, any order
+		"Bought yummy milk", // characters in order
+		"Buy mik",           // typos
+	}
+	previous := scoreExact + 1
+	for _, title := range ordered {
+		score, ok := scoreTitle("Buy milk", title)
+		if !ok {
+			t.Fatalf("expected %q to match", title)
+		}
+		if score >= previous {
+			t.Fatalf("expected %q (%d) to rank below the previous title (%d)", title, score, previous)
+		}
+		previous = score
+	}
+	if _, ok := scoreTitle("Buy milk", "Call the plumber"); ok {
+		t.Fatalf("expected unrelated title not to match")
+	}
+}
+
+func TestClearWinnerNeedsOneExactTitle(t *testing.T) {
+	matches := []taskTitleMatch{{Score: scoreExactFold}, {Score: scoreSubstring + 50}}
+	if !clearWinner(matches) {
+		t.Fatalf("expected a single exact title to win")
+	}
+	matches[1].Score = scoreExactFold
+	if clearWinner(matches) {
+		t.Fatalf("expected two exact titles to need a choice")
+	}
+	if editDistance("milk", "mlik") != 2 || editDistance("plumber", "plumbr") != 1 {
+		t.Fatalf("unexpected edit distances")
+	}
+}
+
+func TestChooseTitleMatchNeedsAnExactTitleToResolveAlone(t *testing.T) {
+	typo := []taskTitleMatch{{ListName: "Inbox", ID: "t1", Title: "Buy milk", Score: scoreTypo}}
+	if _, err := chooseTitleMatch("buy mlik", typo, titleQuery{}, false); err == nil || !strings.Contains(err.Error(), "--first") {
+		t.Fatalf("expected a lone typo match to need confirmation, got %v", err)
+	}
+	if match, err := chooseTitleMatch("buy mlik", typo, titleQuery{First: true}, false); err != nil || match.ID != "t1" {
+		t.Fatalf("expected --first to take the typo match, got %#v (%v)", match, err)
+	}
+	exact := []taskTitleMatch{{ID: "t1", Title: "Buy milk", Score: scoreExactFold}}
+	if match, err := chooseTitleMatch("buy milk", exact, titleQuery{}, false); err != nil || match.ID != "t1" {
+		t.Fatalf("expected a lone exact title to resolve, got %#v (%v)", match, err)
+	}
+}
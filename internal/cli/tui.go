@@ -40,6 +40,10 @@ const (
 	stateQuickCapture
 	stateSnooze
 	stateSearch
+	stateSections
+	stateSectionInput
+	stateSectionConfirm
+	stateBulkMove
 )
 
 const (
@@ -86,23 +90,52 @@ func (l listItem) Description() string { return "" }
 func (l listItem) FilterValue() string { return string(l) }
 
 type taskItem struct {
-	ID         string
-	TitleVal   string
-	ListName   string
-	ListID     string
-	Section    string
-	Due        time.Time
-	HasDue     bool
-	HasTime    bool
-	IsHeader   bool
-	Recurrence string
+	ID            string
+	TitleVal      string
+	ListName      string
+	ListID        string
+	Section       string
+	Due           time.Time
+	HasDue        bool
+	HasTime       bool
+	Start         time.Time
+	IsHeader      bool
+	Recurrence    string
+	Priority      int
+	Tags          []string
+	Blocked       bool
+	ParentTaskID  string
+	SubtasksDone  int
+	SubtasksTotal int
+	Expanded      bool
+	Selected      bool
+	// Profile is set in views that merge several profiles.
+	Profile string
 }
 
 func (t taskItem) Title() string {
 	if t.IsHeader {
 		return lipgloss.NewStyle().Bold(true).Foreground(colorMuted).Render(t.TitleVal)
 	}
-	return recurringTitle(t.TitleVal, t.Recurrence)
+	title := recurringTitle(t.TitleVal, t.Recurrence)
+	if t.Blocked {
+		title = lipgloss.NewStyle().Foreground(colorMuted).Render("⛔ " + title)
+	}
+	title = priorityStyledTitle(title, t.Priority)
+	if t.Selected {
+		title = selectionMarker() + title
+	}
+	if t.ParentTaskID != "" {
+		return "  ↳ " + title
+	}
+	if progress := progressLabel(t.SubtasksDone, t.SubtasksTotal); progress != "" {
+		marker := "▸"
+		if t.Expanded {
+			marker = "▾"
+		}
+		return marker + " " + title + " " + lipgloss.NewStyle().Foreground(colorMuted).Render(progress)
+	}
+	return title
 }
 
 func (t taskItem) Description() string {
@@ -115,6 +148,12 @@ func (t taskItem) Description() string {
 			dueText = fmt.Sprintf("due %s", formatted)
 		}
 	}
+	if tags := formatTags(t.Tags); tags != "" {
+		if dueText == "" {
+			return tags
+		}
+		return dueText + " • " + tags
+	}
 	return dueText
 }
 
@@ -134,13 +173,21 @@ type tuiModel struct {
 	formSelectStep int
 	formSelectBusy bool
 
+	taskItems   []list.Item
+	expanded    map[string]bool
+	manualOrder bool
+	focusTaskID string
+	selected    map[string]taskItem
+
 	listName    string
 	listCtx     listContext
 	showAll     bool
 	showBacklog bool
-	nextLoading bool
-	listLoading bool
-	status      string
+	// showDeferred switches the Next screen to tasks whose start date is ahead.
+	showDeferred bool
+	nextLoading  bool
+	listLoading  bool
+	status       string
 
 	weekData        weekData
 	weekFocus       weekFocus
@@ -153,6 +200,7 @@ type tuiModel struct {
 
 	confirmMsg  string
 	confirmTask taskItem
+	confirmBulk bool
 
 	formInputs []textinput.Model
 	formStep   int
@@ -167,6 +215,7 @@ type tuiModel struct {
 	snoozeTask             taskItem
 	snoozeReturnState      tuiState
 	snoozeReturnListCtx    listContext
+	snoozeBulk             bool
 	searchInput            textinput.Model
 	searchFocus            searchFocus
 	searchQuery            string
@@ -177,6 +226,16 @@ type tuiModel struct {
 	searchLoading          bool
 	lastListMove           int
 
+	sectionsList    list.Model
+	sectionListName string
+	sectionsLoading bool
+	sectionInput    textinput.Model
+	sectionAction   sectionAction
+	sectionTarget   string
+
+	bulkMoveInput   textinput.Model
+	bulkReturnState tuiState
+
 	winW int
 	winH int
 }
@@ -184,6 +243,7 @@ type tuiModel struct {
 func startTUI(app *App) error {
 	menu := list.New([]list.Item{
 		menuItem("Next"),
+		menuItem("Deferred"),
 		menuItem("Week"),
 		menuItem("Lists"),
 		menuItem("Search"),
@@ -227,6 +287,7 @@ func (m *tuiModel) setSizes() {
 	m.viewport.Height = m.winH - 8
 	m.calendarSelect.SetSize(m.winW-4, m.winH-6)
 	m.formSelect.SetSize(m.winW-4, m.winH-6)
+	m.sectionsList.SetSize(m.winW-4, m.winH-8)
 }
 
 func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -246,7 +307,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case "ctrl+f":
-			if m.state != stateSearch && m.state != stateQuickCapture && m.state != stateSnooze && m.state != stateTaskForm && m.state != stateFormSelect && !m.isFiltering() {
+			if m.state != stateSearch && m.state != stateQuickCapture && m.state != stateSnooze && m.state != stateSectionInput && m.state != stateTaskForm && m.state != stateFormSelect && !m.isFiltering() {
 				m.openSearch()
 				return m, nil
 			}
@@ -255,6 +316,9 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		case "esc":
+			if (m.state == stateTodayTasks || m.state == stateListTasks || m.state == stateSearch) && m.clearSelection() {
+				return m, nil
+			}
 			switch m.state {
 			case stateMenu:
 				return m, tea.Quit
@@ -307,6 +371,17 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case stateSearch:
 				m.restoreFromSearch()
 				return m.refreshAfterSearch()
+			case stateSections:
+				m.state = stateListSelect
+				return m, nil
+			case stateSectionInput, stateSectionConfirm:
+				m.state = stateSections
+				m.sectionInput.Blur()
+				return m, nil
+			case stateBulkMove:
+				m.state = m.bulkReturnState
+				m.bulkMoveInput.Blur()
+				return m, nil
 			default:
 				m.state = stateMenu
 				return m, nil
@@ -315,6 +390,26 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	switch msg := msg.(type) {
+	case listsSyncedMsg:
+		if msg.err != nil {
+			m.status = "List sync: " + msg.err.Error()
+			return m, nil
+		}
+		// Views may be reading the config concurrently; change a copy.
+		cfg := m.app.Config.Clone()
+		result := syncListMappings(cfg, msg.remote, true)
+		if !result.changed() {
+			return m, nil
+		}
+		m.app.Config = cfg
+		if err := m.app.SaveConfig(); err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		if !result.empty() {
+			m.status = "Lists updated: " + result.summary()
+		}
+		return m, nil
 	case quickCaptureMsg:
 		if msg.err != nil {
 			m.status = msg.err.Error()
@@ -323,20 +418,56 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = "✅ Task created"
 		m.restoreFromQuickCapture()
 		return m.refreshAfterQuickCapture()
+	case priorityMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		if label := priorityLabel(msg.priority); label != "" {
+			m.status = "🚩 Priority set to " + label
+		} else {
+			m.status = "🚩 Priority cleared"
+		}
+		return m.refreshAfterSnooze()
 	case snoozeMsg:
 		if msg.err != nil {
 			m.status = msg.err.Error()
 			return m, nil
 		}
 		m.status = "✅ Task rescheduled"
+		if msg.status != "" {
+			m.status = msg.status
+		}
 		m.restoreFromSnooze()
 		return m.refreshAfterSnooze()
+	case sectionsMsg:
+		m.sectionsLoading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.sectionsList = newTasksListModel(msg.items, m.sectionListName+" sections")
+		selectListItem(&m.sectionsList, m.sectionTarget)
+		m.setSizes()
+		return m, nil
+	case sectionOpMsg:
+		m.sectionsLoading = false
+		m.state = stateSections
+		m.sectionInput.Blur()
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = msg.status
+		m.sectionsLoading = true
+		return m, m.loadSectionsCmd(m.sectionListName)
 	case searchMsg:
 		m.searchLoading = false
 		if msg.err != nil {
 			m.status = msg.err.Error()
 			return m, nil
 		}
+		m.selected = nil
 		m.tasksList = newTasksListModel(buildSearchItems(msg.results), "Results")
 		m.setSizes()
 		return m, nil
@@ -344,11 +475,13 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.nextLoading = false
 		if msg.err != nil {
 			m.status = msg.err.Error()
-			m.tasksList = newTasksListModel(errorItems(msg.err), "Next")
+			m.tasksList = newTasksListModel(errorItems(msg.err), m.nextTitle())
 			m.setSizes()
 			return m, nil
 		}
-		m.tasksList = newTasksListModel(msg.items, "Next")
+		m.selected = nil
+		m.taskItems = msg.items
+		m.tasksList = newTasksListModel(visibleTaskItems(msg.items, m.expanded), m.nextTitle())
 		m.setSizes()
 		return m, nil
 	case listItemsMsg:
@@ -362,7 +495,13 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.listName != "" {
 			m.listName = msg.listName
 		}
-		m.tasksList = newTasksListModel(msg.items, m.listName)
+		m.selected = nil
+		m.taskItems = msg.items
+		m.tasksList = newTasksListModel(visibleTaskItems(msg.items, m.expanded), m.listName)
+		if m.focusTaskID != "" {
+			selectTaskItem(&m.tasksList, m.focusTaskID)
+			m.focusTaskID = ""
+		}
 		m.setSizes()
 		return m, nil
 	case formSelectItemsMsg:
@@ -393,17 +532,22 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	switch m.state {
+	case stateSections, stateSectionInput, stateSectionConfirm:
+		return m.updateSections(msg)
+	case stateBulkMove:
+		return m.updateBulkMove(msg)
 	case stateMenu:
 		var cmd tea.Cmd
 		m.menu, cmd = m.menu.Update(msg)
 		if key, ok := msg.(tea.KeyMsg); ok && (key.String() == "enter" || key.String() == " ") {
 			selected := m.menu.SelectedItem().(menuItem)
 			switch string(selected) {
-			case "Next":
+			case "Next", "Deferred":
 				m.state = stateTodayTasks
 				m.listCtx = listCtxToday
 				m.showAll = false
 				m.showBacklog = true
+				m.showDeferred = string(selected) == "Deferred"
 				return m.startNextLoad()
 			case "Search":
 				m.openSearch()
@@ -415,7 +559,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.weekEventIndex = -1
 				m.weekAllDayIndex = -1
 				m.weekLoading = true
-				return m, m.loadWeekDataCmd(m.app.Now())
+				return m, tea.Batch(m.loadWeekDataCmd(m.app.Now()), m.syncListsCmd())
 			case "Lists":
 				m.state = stateListSelect
 				m.listSelect = newListSelect(m.app)
@@ -462,6 +606,11 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "d":
 				m.prepareDeleteWeekTask()
 				return m, nil
+			case "p":
+				task, ok := m.selectedWeekTask()
+				return m, m.cyclePriority(task, ok)
+			case "u":
+				return m, m.undoLastCmd()
 			case "n":
 				date := time.Time{}
 				if len(m.weekData.Days) > 0 && m.weekDayIndex >= 0 && m.weekDayIndex < len(m.weekData.Days) {
@@ -475,7 +624,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.loadCalendarListCmd()
 			case "r":
 				m.weekRefreshing = true
-				return m, m.refreshWeekDataCmd(m.weekAnchor())
+				return m, tea.Batch(m.refreshWeekDataCmd(m.weekAnchor()), m.syncListsCmd())
 			case "t":
 				m.weekDayIndex = -1
 				m.weekLoading = true
@@ -509,6 +658,9 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.status = "date or time is required"
 					return m, nil
 				}
+				if m.snoozeBulk {
+					return m, m.bulkSnoozeCmd(value)
+				}
 				return m, m.snoozeCmd(m.snoozeTask, value)
 			}
 		}
@@ -542,6 +694,14 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.tasksList, cmd = m.tasksList.Update(msg)
 			m.ensureNonHeaderSelection()
 		}
+		if key, ok := msg.(tea.KeyMsg); ok && m.searchFocus == focusSearchList {
+			if m.handleSelectionKey(key.String()) {
+				return m, nil
+			}
+			if bulkCmd, ok := m.handleBulkKey(key.String()); ok {
+				return m, bulkCmd
+			}
+		}
 		if key, ok := msg.(tea.KeyMsg); ok {
 			switch key.String() {
 			case "tab":
@@ -590,6 +750,15 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.prepareDelete()
 					return m, nil
 				}
+			case "p":
+				if m.searchFocus == focusSearchList {
+					task, ok := m.selectedTask()
+					return m, m.cyclePriority(task, ok)
+				}
+			case "u":
+				if m.searchFocus == focusSearchList {
+					return m, m.undoLastCmd()
+				}
 			case "l":
 				if m.searchFocus == focusSearchList {
 					m.cycleSearchList()
@@ -619,6 +788,12 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tasksList, cmd = m.tasksList.Update(msg)
 		m.ensureNonHeaderSelection()
 		if key, ok := msg.(tea.KeyMsg); ok {
+			if m.handleSelectionKey(key.String()) {
+				return m, nil
+			}
+			if bulkCmd, ok := m.handleBulkKey(key.String()); ok {
+				return m, bulkCmd
+			}
 			switch key.String() {
 			case "b":
 				m.showBacklog = !m.showBacklog
@@ -639,6 +814,14 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "d":
 				m.prepareDelete()
 				return m, nil
+			case "p":
+				task, ok := m.selectedTask()
+				return m, m.cyclePriority(task, ok)
+			case "o":
+				m.toggleSubtasks()
+				return m, nil
+			case "u":
+				return m, m.undoLastCmd()
 			case "n":
 				m.openTaskForm(m.app.Config.DefaultList, time.Now().In(m.app.Location))
 			case "r":
@@ -653,7 +836,15 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	case stateListSelect:
 		var cmd tea.Cmd
+		filtering := m.listSelect.FilterState() == list.Filtering
 		m.listSelect, cmd = m.listSelect.Update(msg)
+		if key, ok := msg.(tea.KeyMsg); ok && key.String() == "S" && !filtering {
+			selected := m.listSelect.SelectedItem().(listItem)
+			if _, known := m.app.Config.ListID(string(selected)); !known {
+				return m, nil
+			}
+			return m.openSections(string(selected))
+		}
 		if key, ok := msg.(tea.KeyMsg); ok && (key.String() == "enter" || key.String() == " ") {
 			selected := m.listSelect.SelectedItem().(listItem)
 			m.listName = string(selected)
@@ -671,6 +862,12 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tasksList, cmd = m.tasksList.Update(msg)
 		m.ensureNonHeaderSelection()
 		if key, ok := msg.(tea.KeyMsg); ok {
+			if m.handleSelectionKey(key.String()) {
+				return m, nil
+			}
+			if bulkCmd, ok := m.handleBulkKey(key.String()); ok {
+				return m, bulkCmd
+			}
 			switch key.String() {
 			case "a":
 				m.showAll = !m.showAll
@@ -691,6 +888,26 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "d":
 				m.prepareDelete()
 				return m, nil
+			case "p":
+				task, ok := m.selectedTask()
+				return m, m.cyclePriority(task, ok)
+			case "o":
+				m.toggleSubtasks()
+				return m, nil
+			case "u":
+				return m, m.undoLastCmd()
+			case "m":
+				m.manualOrder = !m.manualOrder
+				if task, ok := m.selectedTask(); ok {
+					m.focusTaskID = task.ID
+				}
+				return m.startListLoad(m.listName, m.showAll)
+			case "K", "J":
+				delta := 1
+				if key.String() == "K" {
+					delta = -1
+				}
+				return m, m.shiftTaskCmd(delta)
 			case "n":
 				m.openTaskForm(m.listName, time.Time{})
 			}
@@ -795,6 +1012,9 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if key, ok := msg.(tea.KeyMsg); ok {
 			switch strings.ToLower(key.String()) {
 			case "y":
+				if m.confirmBulk {
+					return m, m.bulkDeleteCmd()
+				}
 				return m, m.deleteTaskCmd()
 			case "n":
 				switch m.listCtx {
@@ -842,23 +1062,31 @@ func (m tuiModel) View() string {
 	case stateMenu:
 		return padding.Render(renderHeader("Home") + "\n\n" + m.menu.View() + status)
 	case stateWeekView:
-		hint := "←/→ day • [ ]: week • t: today • ↑/↓ item • space: done • e: edit • s: snooze • d: delete • n: new task • c: calendars • r: refresh • ctrl+n: capture • esc: back"
+		hint := "←/→ day • [ ]: week • t: today • ↑/↓ item • space: done • e: edit • s: snooze • d: delete • p: priority • u: undo • n: new task • c: calendars • r: refresh • ctrl+n: capture • esc: back"
 		if m.weekRefreshing {
 			hint += " • refreshing…"
 		}
 		return padding.Render(renderHeader("Week") + "\n\n" + m.weekView() + "\n\n" + gray(wrapText(hint, contentWidth)) + status)
 	case stateTodayTasks:
-		hint := "space: done • e: edit • s: snooze • d: delete • n: new task • b: backlog • r: refresh • ctrl+n: capture • esc: back"
+		hint := selectionHint("space: done • e: edit • s: snooze • d: delete • p: priority • o: subtasks • u: undo • n: new task • b: backlog • r: refresh • ctrl+n: capture • esc: back", m.selected)
 		if m.nextLoading {
 			hint += " • loading…"
 		}
-		return padding.Render(renderHeader("Next") + "\n\n" + m.splitPane(m.tasksList.View(), m.detailsView()) + "\n\n" + gray(wrapText(hint, contentWidth)) + status)
+		return padding.Render(renderHeader(m.nextTitle()) + "\n\n" + m.splitPane(m.tasksList.View(), m.detailsView()) + "\n\n" + gray(wrapText(hint, contentWidth)) + status)
 	case stateAgendaDetails:
 		return padding.Render(renderHeader("Schedule") + "\n\n" + m.viewport.View() + "\n\n" + gray(wrapText("esc: back", contentWidth)) + status)
 	case stateListSelect:
-		return padding.Render(renderHeader("Select a list") + "\n\n" + m.listSelect.View() + status)
+		return padding.Render(renderHeader("Select a list") + "\n\n" + m.listSelect.View() + "\n\n" + gray(wrapText("enter: open • S: sections • esc: back", contentWidth)) + status)
+	case stateSections, stateSectionInput, stateSectionConfirm:
+		return padding.Render(m.sectionsView(contentWidth, status))
+	case stateBulkMove:
+		return padding.Render(m.bulkMoveView(contentWidth, status))
 	case stateListTasks:
-		hint := "space: done • e: edit • s: snooze • d: delete • n: new task • a: all • esc: back"
+		hint := "space: done • e: edit • s: snooze • d: delete • p: priority • o: subtasks • u: undo • n: new task • a: all • m: manual order • esc: back"
+		if m.manualOrder {
+			hint = "space: done • e: edit • s: snooze • d: delete • p: priority • o: subtasks • u: undo • n: new task • a: all • K/J: move up/down • m: smart order • esc: back"
+		}
+		hint = selectionHint(hint, m.selected)
 		if m.listLoading {
 			hint += " • loading…"
 		}
@@ -916,7 +1144,7 @@ func (m tuiModel) View() string {
 		filters := fmt.Sprintf("List: %s • Completed: %s", listLabel, completeLabel)
 		hint := "enter: search • tab: results • ctrl+l: list • ctrl+a: completed • esc: back • ctrl+n: capture"
 		if m.searchFocus == focusSearchList {
-			hint = "tab: search • space: done • e/enter: edit • s: snooze • d: delete • l: list • a: completed • esc: back • ctrl+n: capture"
+			hint = selectionHint("tab: search • space: done • e/enter: edit • s: snooze • d: delete • p: priority • u: undo • l: list • a: completed • esc: back • ctrl+n: capture", m.selected)
 		}
 		return padding.Render(renderHeader("Search") + "\n\n" + input + "\n" + gray(filters) + "\n\n" + body + "\n\n" + gray(wrapText(hint, contentWidth)) + status)
 	default:
@@ -984,6 +1212,18 @@ func (m *tuiModel) detailsView() string {
 			fmt.Sprintf("%s %s", label.Render("Section:"), value.Section),
 			fmt.Sprintf("%s %s", label.Render("Due:"), dueText),
 		}
+		if !value.Start.IsZero() {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Starts:"), value.Start.Format("Mon 2006-01-02")))
+		}
+		if p := priorityLabel(value.Priority); p != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Priority:"), p))
+		}
+		if tags := formatTags(value.Tags); tags != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Tags:"), tags))
+		}
+		if value.Blocked {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Blocked:"), "waiting on other tasks"))
+		}
 		return strings.Join(lines, "\n")
 	case searchItem:
 		task := value.Task
@@ -1003,6 +1243,12 @@ func (m *tuiModel) detailsView() string {
 			fmt.Sprintf("%s %s", label.Render("Section:"), task.Section),
 			fmt.Sprintf("%s %s", label.Render("Due:"), dueText),
 		}
+		if p := priorityLabel(task.Priority); p != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Priority:"), p))
+		}
+		if tags := formatTags(task.Tags); tags != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", label.Render("Tags:"), tags))
+		}
 		return strings.Join(lines, "\n")
 	case calendarEventItem:
 		when := ""
@@ -1080,7 +1326,7 @@ func (m *tuiModel) openQuickCapture() {
 	m.status = ""
 	if m.quickInput.Placeholder == "" {
 		m.quickInput = textinput.New()
-		m.quickInput.Placeholder = "Task #List ::Section @date @time every:weekly"
+		m.quickInput.Placeholder = "Task #List ::Section @date @time !1 +tag every:weekly"
 		m.quickInput.CharLimit = 200
 	}
 	m.quickInput.SetValue("")
@@ -1117,7 +1363,12 @@ func (m *tuiModel) refreshAfterQuickCapture() (tuiModel, tea.Cmd) {
 
 func (m tuiModel) nextItemsCmd(showBacklog bool) tea.Cmd {
 	ctx := newQueryContext(m.app)
+	deferred := m.showDeferred
 	return func() tea.Msg {
+		if deferred {
+			items, err := buildDeferredItems(ctx)
+			return nextItemsMsg{items: items, err: err}
+		}
 		items, err := buildNextItems(ctx, showBacklog)
 		return nextItemsMsg{items: items, err: err}
 	}
@@ -1125,14 +1376,14 @@ func (m tuiModel) nextItemsCmd(showBacklog bool) tea.Cmd {
 
 func (m *tuiModel) startNextLoad() (tuiModel, tea.Cmd) {
 	m.nextLoading = true
-	m.tasksList = newTasksListModel(loadingItems("Loading..."), "Next")
+	m.tasksList = newTasksListModel(loadingItems("Loading..."), m.nextTitle())
 	m.setSizes()
 	return *m, m.nextItemsCmd(m.showBacklog)
 }
 
 func (m tuiModel) listItemsCmd(listName string, showAll bool) tea.Cmd {
 	return func() tea.Msg {
-		items, err := buildListItems(m.app, listName, showAll)
+		items, err := buildListItems(m.app, listName, showAll, m.manualOrder)
 		return listItemsMsg{listName: listName, items: items, err: err}
 	}
 }
@@ -1145,6 +1396,13 @@ func (m *tuiModel) startListLoad(listName string, showAll bool) (tuiModel, tea.C
 	return *m, m.listItemsCmd(listName, showAll)
 }
 
+func (m tuiModel) nextTitle() string {
+	if m.showDeferred {
+		return "Deferred"
+	}
+	return "Next"
+}
+
 func loadingItems(label string) []list.Item {
 	return []list.Item{taskItem{TitleVal: label, IsHeader: true}}
 }
@@ -1211,11 +1469,25 @@ func selectListItem(model *list.Model, value string) {
 				model.Select(i)
 				return
 			}
+		case sectionItem:
+			if strings.EqualFold(v.Name, value) {
+				model.Select(i)
+				return
+			}
 		}
 	}
 	setInitialListIndex(model, items)
 }
 
+func selectTaskItem(model *list.Model, taskID string) {
+	for i, item := range model.Items() {
+		if t, ok := item.(taskItem); ok && !t.IsHeader && t.ID == taskID {
+			model.Select(i)
+			return
+		}
+	}
+}
+
 func newTasksListModel(items []list.Item, title string) list.Model {
 	model := list.New(items, list.NewDefaultDelegate(), 0, 0)
 	model.Title = title
@@ -1353,13 +1625,13 @@ func (m tuiModel) createTaskCmd() tea.Cmd {
 			return errMsg{err: fmt.Errorf("title is required")}
 		}
 		section := strings.TrimSpace(m.formInputs[2].Value())
-		parentID := ""
+		notes := strings.TrimSpace(m.formInputs[5].Value())
 		if section != "" {
 			sectionTask, err := ensureSectionTask(m.app, listID, section)
 			if err != nil {
 				return errMsg{err: err}
 			}
-			parentID = sectionTask.Id
+			notes = setNotesSection(notes, sectionTask.Id)
 		}
 
 		baseDate, err := timeparse.ParseDate(strings.TrimSpace(m.formInputs[3].Value()), m.app.Now(), m.app.Location)
@@ -1385,13 +1657,15 @@ func (m tuiModel) createTaskCmd() tea.Cmd {
 		input := sync.CreateInput{
 			ListID:    listID,
 			Title:     title,
-			Notes:     strings.TrimSpace(m.formInputs[5].Value()),
+			Notes:     notes,
 			Due:       due,
 			TimeStart: start,
 			TimeEnd:   end,
-			ParentID:  parentID,
 		}
-		_, _, err = m.app.Sync.Create(input)
+		err = recordOp(m.app, "add", taskScope(listID), func() error {
+			_, _, err := m.app.Sync.Create(input)
+			return err
+		})
 		if err != nil {
 			return errMsg{err: err}
 		}
@@ -1427,7 +1701,10 @@ func (m tuiModel) updateTaskCmd() tea.Cmd {
 			Time:       timeStr,
 			HasTime:    timeStr != "",
 		}
-		_, err := updateTaskWithParams(m.app, listID, m.editTask.ID, params)
+		err := recordOp(m.app, "update", taskScope(listID, m.editTask.ID), func() error {
+			_, err := updateTaskWithParams(m.app, listID, m.editTask.ID, params)
+			return err
+		})
 		if err != nil {
 			return errMsg{err: err}
 		}
@@ -1530,6 +1807,10 @@ func (m tuiModel) handleMessage(msg tea.Msg) (tuiModel, tea.Cmd) {
 			m.weekLoading = true
 			m.setSizes()
 			return m, m.loadWeekDataCmd(m.weekAnchor())
+		case stateWeekView:
+			m.weekLoading = true
+			m.setSizes()
+			return m, m.loadWeekDataCmd(m.weekAnchor())
 		default:
 			m.state = stateMenu
 		}
@@ -1572,16 +1853,16 @@ func (m tuiModel) handleMessage(msg tea.Msg) (tuiModel, tea.Cmd) {
 	return m, nil
 }
 
-func buildListItems(app *App, listName string, all bool) ([]list.Item, error) {
+func buildListItems(app *App, listName string, all, manual bool) ([]list.Item, error) {
 	listID, ok := app.Config.ListID(listName)
 	if !ok {
 		return nil, fmt.Errorf("unknown list: %s", listName)
 	}
-	items, err := app.Tasks.ListTasksWithOptions(listID, all, all, false, "")
+	items, err := app.Tasks.ListTasksWithOptions(listID, true, true, false, "")
 	if err != nil {
 		return nil, err
 	}
-	sections, order := groupTasksBySection(items, "", app.Location)
+	sections, order := groupTasksBySection(items, "", all, app.Location)
 	result := []list.Item{}
 	for _, section := range order {
 		rows := sections[section]
@@ -1589,23 +1870,54 @@ func buildListItems(app *App, listName string, all bool) ([]list.Item, error) {
 			continue
 		}
 		result = append(result, taskItem{TitleVal: section, IsHeader: true})
-		for _, row := range orderTaskRows(rows) {
-			result = append(result, taskItem{
-				ID:         row.ID,
-				TitleVal:   row.Title,
-				ListName:   listName,
-				ListID:     listID,
-				Section:    section,
-				Due:        row.Due,
-				HasDue:     row.HasDue,
-				HasTime:    row.HasTime,
-				Recurrence: row.Recurrence,
-			})
+		for _, row := range sortTaskRows(rows, manual) {
+			item := rowTaskItem(row, listName, listID, section)
+			item.SubtasksDone, item.SubtasksTotal = row.Done, row.Total
+			result = append(result, item)
+			for _, child := range sortTaskRows(row.Subtasks, manual) {
+				sub := rowTaskItem(child, listName, listID, section)
+				sub.ParentTaskID = row.ID
+				result = append(result, sub)
+			}
 		}
 	}
 	return result, nil
 }
 
+func rowTaskItem(row taskRow, listName, listID, section string) taskItem {
+	return taskItem{
+		ID:         row.ID,
+		TitleVal:   row.Title,
+		ListName:   listName,
+		ListID:     listID,
+		Section:    section,
+		Due:        row.Due,
+		HasDue:     row.HasDue,
+		HasTime:    row.HasTime,
+		Start:      row.Start,
+		Recurrence: row.Recurrence,
+		Priority:   row.Priority,
+		Tags:       row.Tags,
+	}
+}
+
+func sortTaskRows(rows []taskRow, manual bool) []taskRow {
+	if manual {
+		return manualTaskRows(rows)
+	}
+	return orderTaskRows(rows)
+}
+
+// manualTaskRows keeps the order set in Google Tasks (or with K/J and
+// `move --after`).
+func manualTaskRows(rows []taskRow) []taskRow {
+	ordered := append([]taskRow(nil), rows...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Position < ordered[j].Position
+	})
+	return ordered
+}
+
 func orderTaskRows(rows []taskRow) []taskRow {
 	due := make([]taskRow, 0, len(rows))
 	noDue := make([]taskRow, 0, len(rows))
@@ -1617,14 +1929,60 @@ func orderTaskRows(rows []taskRow) []taskRow {
 		}
 	}
 	sort.SliceStable(due, func(i, j int) bool {
+		if pi, pj := priorityRank(due[i].Priority), priorityRank(due[j].Priority); pi != pj {
+			return pi < pj
+		}
 		if due[i].Due.Equal(due[j].Due) {
 			return due[i].Index < due[j].Index
 		}
 		return due[i].Due.Before(due[j].Due)
 	})
+	sort.SliceStable(noDue, func(i, j int) bool {
+		return priorityRank(noDue[i].Priority) < priorityRank(noDue[j].Priority)
+	})
 	return append(due, noDue...)
 }
 
+// shiftTaskCmd moves the selected task up (negative) or down among the tasks
+// sharing its section and parent. It only applies to the manual order view.
+func (m *tuiModel) shiftTaskCmd(delta int) tea.Cmd {
+	task, ok := m.selectedTask()
+	if !ok {
+		m.status = "Select a task to move"
+		return nil
+	}
+	if !m.manualOrder {
+		m.status = "Press m to switch to manual order first"
+		return nil
+	}
+	ids := []string{}
+	from := -1
+	for _, item := range m.taskItems {
+		sibling, ok := item.(taskItem)
+		if !ok || sibling.IsHeader || sibling.Section != task.Section || sibling.ParentTaskID != task.ParentTaskID {
+			continue
+		}
+		if sibling.ID == task.ID {
+			from = len(ids)
+		}
+		ids = append(ids, sibling.ID)
+	}
+	to := from + delta
+	if from < 0 || to < 0 || to >= len(ids) {
+		return nil
+	}
+	previous := movePrevious(ids, from, to)
+	m.focusTaskID = task.ID
+	return func() tea.Msg {
+		if err := recordOp(m.app, "reorder", taskScope(task.ListID, task.ID), func() error {
+			return moveTaskAfter(m.app, task.ListID, task.ID, previous)
+		}); err != nil {
+			return errMsg{err: err}
+		}
+		return okMsg{msg: "✅ Task moved"}
+	}
+}
+
 func (m *tuiModel) selectedTask() (taskItem, bool) {
 	item := m.tasksList.SelectedItem()
 	if item == nil {
@@ -1715,7 +2073,12 @@ func (m *tuiModel) completeSelectedTaskCmd() tea.Cmd {
 
 func (m *tuiModel) completeTaskCmd(task taskItem) tea.Cmd {
 	return func() tea.Msg {
-		completed, err := toggleTaskDone(m.app, task.ListID, task.ID, true)
+		var completed bool
+		err := recordOp(m.app, "complete", taskScope(task.ListID, task.ID), func() error {
+			var err error
+			completed, err = toggleTaskDone(m.app, task.ListID, task.ID, true)
+			return err
+		})
 		if err != nil {
 			return errMsg{err: err}
 		}
@@ -1746,6 +2109,7 @@ func (m *tuiModel) prepareDelete() {
 }
 
 func (m *tuiModel) prepareDeleteTask(task taskItem) {
+	m.confirmBulk = false
 	m.confirmTask = task
 	m.confirmMsg = fmt.Sprintf("Delete '%s'? (event will be removed)", task.TitleVal)
 	m.state = stateConfirmDelete
@@ -1866,27 +2230,34 @@ func buildFormListItems(app *App) []list.Item {
 }
 
 func buildFormSectionItems(items []*tasks.Task) []list.Item {
-	sections := buildSectionIndex(items)
-	names := make([]string, 0, len(sections))
-	for _, name := range sections {
-		name = strings.TrimSpace(name)
-		if name != "" {
-			names = append(names, name)
-		}
-	}
-	sort.Strings(names)
-	result := make([]list.Item, 0, len(names)+1)
+	sections := sortedSections(items)
+	result := make([]list.Item, 0, len(sections)+1)
 	result = append(result, listItem(noSectionLabel))
-	for _, name := range names {
-		result = append(result, listItem(name))
+	for _, section := range sections {
+		if name := strings.TrimSpace(section.Title); name != "" {
+			result = append(result, listItem(name))
+		}
 	}
 	return result
 }
 
+func (m *tuiModel) undoLastCmd() tea.Cmd {
+	m.status = "Undoing…"
+	return func() tea.Msg {
+		entry, err := undoLast(m.app)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return okMsg{msg: fmt.Sprintf("↩️ Undone: %s %s", entry.Action, entry.Summary)}
+	}
+}
+
 func (m *tuiModel) deleteTaskCmd() tea.Cmd {
 	task := m.confirmTask
 	return func() tea.Msg {
-		err := deleteTask(m.app, task.ListID, task.ID, true)
+		err := recordOp(m.app, "delete", taskScope(task.ListID, task.ID), func() error {
+			return deleteTask(m.app, task.ListID, task.ID, true)
+		})
 		if err != nil {
 			return errMsg{err: err}
 		}
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func selectionMarker() string {
+	return lipgloss.NewStyle().Foreground(colorAccent).Render("✓ ")
+}
+
+// hasSelection reports whether multi-select is active in the current view.
+func (m tuiModel) hasSelection() bool {
+	return len(m.selected) > 0
+}
+
+func (m *tuiModel) clearSelection() bool {
+	if len(m.selected) == 0 {
+		return false
+	}
+	m.selected = nil
+	m.applySelectionMarks()
+	m.status = ""
+	return true
+}
+
+// toggleSelection flips the selected row. With extend set the row is only
+// added, which lets shift+up/down grow a range.
+func (m *tuiModel) toggleSelection(extend bool) {
+	task, ok := m.selectedTask()
+	if !ok {
+		return
+	}
+	if m.selected == nil {
+		m.selected = map[string]taskItem{}
+	}
+	if _, ok := m.selected[task.ID]; ok && !extend {
+		delete(m.selected, task.ID)
+	} else {
+		m.selected[task.ID] = task
+	}
+	m.applySelectionMarks()
+	m.status = fmt.Sprintf("%d selected", len(m.selected))
+}
+
+func (m *tuiModel) moveSelectionCursor(delta int) {
+	if delta < 0 {
+		m.tasksList.CursorUp()
+	} else {
+		m.tasksList.CursorDown()
+	}
+	m.lastListMove = delta
+	m.ensureNonHeaderSelection()
+}
+
+// handleSelectionKey handles v and shift+up/down in task lists.
+func (m *tuiModel) handleSelectionKey(key string) bool {
+	switch key {
+	case "v":
+		m.toggleSelection(false)
+		m.moveSelectionCursor(1)
+	case "shift+down", "shift+up":
+		delta := 1
+		if key == "shift+up" {
+			delta = -1
+		}
+		m.toggleSelection(true)
+		m.moveSelectionCursor(delta)
+		m.toggleSelection(true)
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *tuiModel) applySelectionMarks() {
+	items := m.tasksList.Items()
+	for i, item := range items {
+		switch task := item.(type) {
+		case taskItem:
+			if task.IsHeader {
+				continue
+			}
+			_, task.Selected = m.selected[task.ID]
+			m.tasksList.SetItem(i, task)
+		case searchItem:
+			_, task.Task.Selected = m.selected[task.Task.ID]
+			m.tasksList.SetItem(i, task)
+		}
+	}
+}
+
+func (m tuiModel) selectedTargets() []bulkTarget {
+	targets := make([]bulkTarget, 0, len(m.selected))
+	for _, item := range m.tasksList.Items() {
+		var task taskItem
+		switch value := item.(type) {
+		case taskItem:
+			task = value
+		case searchItem:
+			task = value.Task
+		default:
+			continue
+		}
+		if _, ok := m.selected[task.ID]; ok {
+			targets = append(targets, bulkTarget{ListName: task.ListName, ListID: task.ListID, ID: task.ID, Title: task.TitleVal})
+		}
+	}
+	// Rows hidden under collapsed parents are still selected.
+	if len(targets) < len(m.selected) {
+		seen := map[string]bool{}
+		for _, target := range targets {
+			seen[target.ID] = true
+		}
+		for id, task := range m.selected {
+			if !seen[id] {
+				targets = append(targets, bulkTarget{ListName: task.ListName, ListID: task.ListID, ID: task.ID, Title: task.TitleVal})
+			}
+		}
+	}
+	return targets
+}
+
+// bulkStatus summarises a bulk run for the status line.
+func bulkStatus(past string, errs []error) (string, error) {
+	failed := 0
+	var first error
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if failed == len(errs) && first != nil {
+		return "", first
+	}
+	status := fmt.Sprintf("✅ %d task(s) %s", len(errs)-failed, past)
+	if failed > 0 {
+		status += fmt.Sprintf(" • %d failed: %v", failed, first)
+	}
+	return status, nil
+}
+
+func (m tuiModel) bulkCmd(action, past string, fn bulkFunc) tea.Cmd {
+	targets := m.selectedTargets()
+	return func() tea.Msg {
+		var errs []error
+		_ = recordOp(m.app, action, targetScope(targets), func() error {
+			_, errs = applyBulk(targets, defaultBulkConcurrency, fn, nil)
+			return nil
+		})
+		status, err := bulkStatus(past, errs)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return okMsg{msg: status}
+	}
+}
+
+func (m tuiModel) bulkCompleteCmd() tea.Cmd {
+	return m.bulkCmd("complete", "completed", func(target bulkTarget) ([]string, error) {
+		_, err := markTaskDone(m.app, target.ListID, target.ID, true, nil)
+		return nil, err
+	})
+}
+
+func (m tuiModel) bulkDeleteCmd() tea.Cmd {
+	return m.bulkCmd("delete", "deleted", func(target bulkTarget) ([]string, error) {
+		return nil, deleteTask(m.app, target.ListID, target.ID, true)
+	})
+}
+
+func (m tuiModel) bulkSnoozeCmd(input string) tea.Cmd {
+	targets := m.selectedTargets()
+	return func() tea.Msg {
+		params, err := parseSnoozeInput(input, m.app.Now(), m.app.Location)
+		if err != nil {
+			return snoozeMsg{err: err}
+		}
+		var errs []error
+		_ = recordOp(m.app, "snooze", targetScope(targets), func() error {
+			_, errs = applyBulk(targets, defaultBulkConcurrency, func(target bulkTarget) ([]string, error) {
+				_, err := updateTaskWithParams(m.app, target.ListID, target.ID, params)
+				return nil, err
+			}, nil)
+			return nil
+		})
+		status, err := bulkStatus("rescheduled", errs)
+		return snoozeMsg{status: status, err: err}
+	}
+}
+
+// bulkMoveCmd moves the selection to "List ::Section". Either part may be
+// omitted: "::Section" keeps each task in its list.
+func (m tuiModel) bulkMoveCmd(value string) tea.Cmd {
+	listName, section := value, ""
+	hasSection := false
+	if before, after, ok := strings.Cut(value, "::"); ok {
+		listName, section, hasSection = strings.TrimSpace(before), strings.TrimSpace(after), true
+	}
+	listName = strings.TrimSpace(strings.TrimPrefix(listName, "#"))
+	targets := m.selectedTargets()
+	return func() tea.Msg {
+		toListID := ""
+		if listName != "" {
+			id, ok := m.app.Config.ListID(listName)
+			if !ok {
+				return errMsg{err: fmt.Errorf("unknown list: %s", listName)}
+			}
+			toListID = id
+		}
+		if section != "" {
+			lists := map[string]bool{}
+			for _, target := range targets {
+				lists[target.ListID] = true
+			}
+			if toListID != "" {
+				lists = map[string]bool{toListID: true}
+			}
+			for listID := range lists {
+				if _, err := ensureSectionTask(m.app, listID, section); err != nil {
+					return errMsg{err: err}
+				}
+			}
+		}
+		var errs []error
+		_ = recordOp(m.app, "move", targetScope(targets, toListID), func() error {
+			_, errs = applyBulk(targets, defaultBulkConcurrency, func(target bulkTarget) ([]string, error) {
+				if toListID == "" || toListID == target.ListID {
+					params := UpdateParams{Section: section, HasSection: hasSection}
+					_, err := updateTaskWithParams(m.app, target.ListID, target.ID, params)
+					return nil, err
+				}
+				return nil, moveTaskToList(m.app, target.ListID, toListID, target.ID, section)
+			}, nil)
+			return nil
+		})
+		status, err := bulkStatus("moved", errs)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return okMsg{msg: status}
+	}
+}
+
+func (m *tuiModel) prepareBulkDelete() {
+	m.confirmBulk = true
+	m.confirmMsg = fmt.Sprintf("Delete %d selected task(s)? (events will be removed)", len(m.selected))
+	m.state = stateConfirmDelete
+}
+
+func (m *tuiModel) openBulkSnooze() {
+	m.openSnooze(taskItem{ID: "bulk", TitleVal: fmt.Sprintf("%d task(s)", len(m.selected))})
+	m.snoozeBulk = true
+}
+
+func (m *tuiModel) openBulkMove() {
+	m.bulkReturnState = m.state
+	m.state = stateBulkMove
+	m.status = ""
+	m.bulkMoveInput = textinput.New()
+	m.bulkMoveInput.Placeholder = "List ::Section (e.g. Work ::Later or ::Later)"
+	m.bulkMoveInput.CharLimit = 200
+	m.bulkMoveInput.Focus()
+}
+
+func (m tuiModel) updateBulkMove(msg tea.Msg) (tuiModel, tea.Cmd) {
+	var cmd tea.Cmd
+	m.bulkMoveInput, cmd = m.bulkMoveInput.Update(msg)
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "enter" {
+		value := strings.TrimSpace(m.bulkMoveInput.Value())
+		if value == "" {
+			m.status = "a list or ::section is required"
+			return m, nil
+		}
+		m.bulkMoveInput.Blur()
+		m.state = m.bulkReturnState
+		return m, m.bulkMoveCmd(value)
+	}
+	return m, cmd
+}
+
+// handleBulkKey applies an action to the selection. It returns false when
+// nothing is selected so the single-task handler runs instead.
+func (m *tuiModel) handleBulkKey(key string) (tea.Cmd, bool) {
+	if !m.hasSelection() {
+		return nil, false
+	}
+	switch key {
+	case " ":
+		return m.bulkCompleteCmd(), true
+	case "s":
+		m.openBulkSnooze()
+		return nil, true
+	case "d":
+		m.prepareBulkDelete()
+		return nil, true
+	case "M":
+		m.openBulkMove()
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m tuiModel) bulkMoveView(contentWidth int, status string) string {
+	input := m.bulkMoveInput.View()
+	if strings.TrimSpace(input) == "" {
+		input = m.bulkMoveInput.Placeholder
+	}
+	lists := "Lists: " + strings.Join(sortedListNames(m.app.Config.Lists), ", ")
+	return renderHeader(fmt.Sprintf("Move %d task(s)", len(m.selected))) + "\n\n" + input + "\n\n" + gray(lists) + "\n\n" + gray(wrapText("enter: move • esc: cancel", contentWidth)) + status
+}
+
+func selectionHint(hint string, selected map[string]taskItem) string {
+	if len(selected) == 0 {
+		return hint + " • v/shift+↑↓: select"
+	}
+	return fmt.Sprintf("%d selected • space: done • s: snooze • d: delete • M: move • v: toggle • esc: clear", len(selected))
+}
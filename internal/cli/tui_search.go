@@ -26,7 +26,11 @@ type searchItem struct {
 }
 
 func (s searchItem) Title() string {
-	return recurringTitle(s.Task.TitleVal, s.Task.Recurrence)
+	title := priorityStyledTitle(recurringTitle(s.Task.TitleVal, s.Task.Recurrence), s.Task.Priority)
+	if s.Task.Selected {
+		return selectionMarker() + title
+	}
+	return title
 }
 
 func (s searchItem) Description() string {
@@ -40,6 +44,9 @@ func (s searchItem) Description() string {
 	if s.Task.HasDue {
 		parts = append(parts, "due "+s.Task.Due.Format("2006-01-02"))
 	}
+	if tags := formatTags(s.Task.Tags); tags != "" {
+		parts = append(parts, tags)
+	}
 	return strings.Join(parts, " • ")
 }
 
@@ -73,7 +80,7 @@ func (m *tuiModel) openSearch() {
 	m.status = ""
 	if m.searchInput.Placeholder == "" {
 		m.searchInput = textinput.New()
-		m.searchInput.Placeholder = "Search tasks"
+		m.searchInput.Placeholder = "Search tasks (+tag to filter)"
 		m.searchInput.CharLimit = 200
 	}
 	m.searchInput.SetValue("")
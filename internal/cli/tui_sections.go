@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/api/tasks/v1"
+)
+
+type sectionAction int
+
+const (
+	sectionActionCreate sectionAction = iota
+	sectionActionRename
+	sectionActionMove
+	sectionActionDelete
+	sectionActionArchive
+)
+
+type sectionsMsg struct {
+	items []list.Item
+	err   error
+}
+
+type sectionOpMsg struct {
+	status string
+	err    error
+}
+
+type sectionItem struct {
+	Name  string
+	Count int
+}
+
+func (s sectionItem) Title() string { return s.Name }
+
+func (s sectionItem) Description() string {
+	return fmt.Sprintf("%d task(s)", s.Count)
+}
+
+func (s sectionItem) FilterValue() string { return s.Name }
+
+func (m *tuiModel) openSections(listName string) (tuiModel, tea.Cmd) {
+	m.sectionListName = listName
+	m.state = stateSections
+	m.status = ""
+	m.sectionsLoading = true
+	m.sectionsList = newTasksListModel([]list.Item{}, listName+" sections")
+	m.setSizes()
+	return *m, m.loadSectionsCmd(listName)
+}
+
+func (m tuiModel) loadSectionsCmd(listName string) tea.Cmd {
+	return func() tea.Msg {
+		listID, err := resolveListID(m.app, listName, true)
+		if err != nil {
+			return sectionsMsg{err: err}
+		}
+		items, err := m.app.Tasks.ListTasks(listID, false)
+		if err != nil {
+			return sectionsMsg{err: err}
+		}
+		return sectionsMsg{items: buildSectionItems(items)}
+	}
+}
+
+func buildSectionItems(items []*tasks.Task) []list.Item {
+	result := []list.Item{}
+	for _, section := range sortedSections(items) {
+		members := sectionMembers(items, findSections(items, section.Title))
+		result = append(result, sectionItem{Name: section.Title, Count: len(members)})
+	}
+	return result
+}
+
+func (m tuiModel) selectedSection() (sectionItem, bool) {
+	item, ok := m.sectionsList.SelectedItem().(sectionItem)
+	return item, ok
+}
+
+func (m *tuiModel) openSectionInput(action sectionAction) {
+	section, ok := m.selectedSection()
+	if !ok && action != sectionActionCreate {
+		m.status = "Select a section first"
+		return
+	}
+	m.sectionAction = action
+	m.sectionTarget = section.Name
+	m.state = stateSectionInput
+	m.status = ""
+	m.sectionInput = textinput.New()
+	m.sectionInput.CharLimit = 200
+	switch action {
+	case sectionActionCreate:
+		m.sectionInput.Placeholder = "New section name"
+	case sectionActionRename:
+		m.sectionInput.Placeholder = "New name for " + section.Name
+		m.sectionInput.SetValue(section.Name)
+	case sectionActionMove:
+		m.sectionInput.Placeholder = "Target list (" + strings.Join(sortedListNames(m.app.Config.Lists), ", ") + ")"
+	}
+	m.sectionInput.Focus()
+}
+
+func (m *tuiModel) openSectionConfirm(action sectionAction) {
+	section, ok := m.selectedSection()
+	if !ok {
+		m.status = "Select a section first"
+		return
+	}
+	m.sectionAction = action
+	m.sectionTarget = section.Name
+	m.state = stateSectionConfirm
+	m.status = ""
+}
+
+func (m tuiModel) sectionOpCmd(action sectionAction, name, value string, deleteTasks bool) tea.Cmd {
+	listName := m.sectionListName
+	return func() tea.Msg {
+		listID, err := resolveListID(m.app, listName, true)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		scope := sectionScope(listID, name)
+		if action == sectionActionMove {
+			if toListID, ok := m.app.Config.ListID(value); ok {
+				scope.ListIDs = append(scope.ListIDs, toListID)
+			}
+		}
+		var result sectionOpMsg
+		_ = recordOp(m.app, "section", scope, func() error {
+			result = m.applySectionOp(listID, action, name, value, deleteTasks)
+			return result.err
+		})
+		return result
+	}
+}
+
+func (m tuiModel) applySectionOp(listID string, action sectionAction, name, value string, deleteTasks bool) sectionOpMsg {
+	switch action {
+	case sectionActionCreate:
+		_, created, err := ensureSectionTaskWithStatus(m.app, listID, value)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		if !created {
+			return sectionOpMsg{status: fmt.Sprintf("Section %q already exists", value)}
+		}
+		return sectionOpMsg{status: "✅ Section created"}
+	case sectionActionRename:
+		if _, err := renameSectionInList(m.app, listID, name, value); err != nil {
+			return sectionOpMsg{err: err}
+		}
+		return sectionOpMsg{status: "✅ Section renamed"}
+	case sectionActionMove:
+		toListID, ok := m.app.Config.ListID(value)
+		if !ok {
+			return sectionOpMsg{err: fmt.Errorf("unknown list: %s", value)}
+		}
+		if toListID == listID {
+			return sectionOpMsg{err: fmt.Errorf("section is already in %s", value)}
+		}
+		count, err := moveSectionToList(m.app, listID, toListID, name)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		return sectionOpMsg{status: fmt.Sprintf("✅ Section moved to %s with %d task(s)", value, count)}
+	case sectionActionDelete:
+		count, err := deleteSection(m.app, listID, name, deleteTasks)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		if deleteTasks {
+			return sectionOpMsg{status: fmt.Sprintf("🗑️ Section deleted with %d task(s)", count)}
+		}
+		return sectionOpMsg{status: fmt.Sprintf("🗑️ Section deleted, %d task(s) moved to General", count)}
+	case sectionActionArchive:
+		count, err := archiveSection(m.app, listID, name)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		return sectionOpMsg{status: fmt.Sprintf("📦 Section archived, %d task(s) completed", count)}
+	}
+	return sectionOpMsg{}
+}
+
+func (m tuiModel) shiftSectionCmd(name string, delta int) tea.Cmd {
+	listName := m.sectionListName
+	return func() tea.Msg {
+		listID, err := resolveListID(m.app, listName, true)
+		if err != nil {
+			return sectionOpMsg{err: err}
+		}
+		if err := recordOp(m.app, "section", taskScope(listID), func() error {
+			return shiftSection(m.app, listID, name, delta)
+		}); err != nil {
+			return sectionOpMsg{err: err}
+		}
+		return sectionOpMsg{status: "✅ Section moved"}
+	}
+}
+
+func (m tuiModel) updateSections(msg tea.Msg) (tuiModel, tea.Cmd) {
+	switch m.state {
+	case stateSectionInput:
+		var cmd tea.Cmd
+		m.sectionInput, cmd = m.sectionInput.Update(msg)
+		if key, ok := msg.(tea.KeyMsg); ok && key.String() == "enter" {
+			value := strings.TrimSpace(m.sectionInput.Value())
+			if value == "" {
+				m.status = "a value is required"
+				return m, nil
+			}
+			name := m.sectionTarget
+			if m.sectionAction != sectionActionMove {
+				m.sectionTarget = value
+			}
+			m.sectionsLoading = true
+			return m, m.sectionOpCmd(m.sectionAction, name, value, false)
+		}
+		return m, cmd
+	case stateSectionConfirm:
+		key, ok := msg.(tea.KeyMsg)
+		if !ok {
+			return m, nil
+		}
+		switch m.sectionAction {
+		case sectionActionDelete:
+			switch key.String() {
+			case "m":
+				m.sectionsLoading = true
+				return m, m.sectionOpCmd(sectionActionDelete, m.sectionTarget, "", false)
+			case "x":
+				m.sectionsLoading = true
+				return m, m.sectionOpCmd(sectionActionDelete, m.sectionTarget, "", true)
+			case "n":
+				m.state = stateSections
+			}
+		case sectionActionArchive:
+			switch key.String() {
+			case "y":
+				m.sectionsLoading = true
+				return m, m.sectionOpCmd(sectionActionArchive, m.sectionTarget, "", false)
+			case "n":
+				m.state = stateSections
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.sectionsList, cmd = m.sectionsList.Update(msg)
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || m.sectionsLoading {
+		return m, cmd
+	}
+	switch key.String() {
+	case "n":
+		m.openSectionInput(sectionActionCreate)
+		return m, nil
+	case "r":
+		m.openSectionInput(sectionActionRename)
+		return m, nil
+	case "m":
+		m.openSectionInput(sectionActionMove)
+		return m, nil
+	case "d":
+		m.openSectionConfirm(sectionActionDelete)
+		return m, nil
+	case "a":
+		m.openSectionConfirm(sectionActionArchive)
+		return m, nil
+	case "K", "J":
+		section, ok := m.selectedSection()
+		if !ok {
+			return m, nil
+		}
+		delta := 1
+		if key.String() == "K" {
+			delta = -1
+		}
+		m.sectionsLoading = true
+		m.sectionTarget = section.Name
+		return m, m.shiftSectionCmd(section.Name, delta)
+	}
+	return m, cmd
+}
+
+func (m tuiModel) sectionsView(contentWidth int, status string) string {
+	switch m.state {
+	case stateSectionInput:
+		input := m.sectionInput.View()
+		if strings.TrimSpace(input) == "" {
+			input = m.sectionInput.Placeholder
+		}
+		title := "New section"
+		switch m.sectionAction {
+		case sectionActionRename:
+			title = "Rename section"
+		case sectionActionMove:
+			title = "Move section to list"
+		}
+		return renderHeader(title) + "\n\n" + input + "\n\n" + gray(wrapText("enter: save • esc: cancel", contentWidth)) + status
+	case stateSectionConfirm:
+		if m.sectionAction == sectionActionArchive {
+			return renderHeader("Archive section") + "\n\n" + fmt.Sprintf("Complete %q and all of its pending tasks?", m.sectionTarget) + "\n\n" + gray(wrapText("y: archive • n: cancel", contentWidth)) + status
+		}
+		return renderHeader("Delete section") + "\n\n" + fmt.Sprintf("Delete %q?", m.sectionTarget) + "\n\n" + gray(wrapText("m: move tasks to General • x: delete tasks too • n: cancel", contentWidth)) + status
+	}
+	body := m.sectionsList.View()
+	if len(m.sectionsList.Items()) == 0 && !m.sectionsLoading {
+		body = "(no sections)"
+	}
+	hint := "n: new • r: rename • m: move to list • d: delete • a: archive • K/J: move up/down • esc: back"
+	if m.sectionsLoading {
+		hint += " • loading…"
+	}
+	return renderHeader(m.sectionListName+" · Sections") + "\n\n" + body + "\n\n" + gray(wrapText(hint, contentWidth)) + status
+}
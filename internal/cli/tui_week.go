@@ -12,7 +12,7 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"google.golang.org/api/calendar/v3"
-	"justdoit/internal/metadata"
+	"justdoit/internal/sync"
 	"justdoit/internal/timeparse"
 )
 
@@ -33,6 +33,7 @@ type weekEvent struct {
 	EndSlot      int
 	AllDay       bool
 	Column       int
+	Priority     int
 }
 
 type weekData struct {
@@ -338,8 +339,12 @@ func (m *tuiModel) resolveTaskByID(taskID string) (taskItem, bool) {
 			continue
 		}
 		section := "General"
-		if task.Parent != "" {
-			if parent, err := m.app.Tasks.GetTask(listID, task.Parent); err == nil && parent != nil {
+		sectionID, _ := taskSectionRef.Get(task.Notes)
+		if sectionID == "" {
+			sectionID = task.Parent
+		}
+		if sectionID != "" {
+			if parent, err := m.app.Tasks.GetTask(listID, sectionID); err == nil && parent != nil && sectionMarker.Has(parent.Notes) {
 				if title := strings.TrimSpace(parent.Title); title != "" {
 					section = title
 				}
@@ -356,11 +361,13 @@ func (m *tuiModel) resolveTaskByID(taskID string) (taskItem, bool) {
 			HasDue:   hasDue,
 			HasTime:  hasTime,
 			Recurrence: func() string {
-				if rule, ok := metadata.Extract(task.Notes, "justdoit_rrule"); ok {
+				if rule, ok := sync.TaskRRule.Get(task.Notes); ok {
 					return rule
 				}
 				return ""
 			}(),
+			Priority: notesPriority(task.Notes),
+			Tags:     notesTags(task.Notes),
 		}
 		m.weekData.TaskByID[taskID] = item
 		return item, true
@@ -568,14 +575,14 @@ func (m *tuiModel) renderWeekGrid(width, height int) string {
 				}
 				text := ""
 				if has {
-					text = truncateText(ev.Summary, dayWidth)
+					text = truncateText(weekEventLabel(ev), dayWidth)
 				}
 				style := lipgloss.NewStyle().Width(dayWidth)
 				if has {
 					if selectedAllDay && dayIdx == m.weekDayIndex && rowIdx == m.weekAllDayIndex {
 						style = style.Background(colorAccent).Foreground(colorAccentText)
 					} else if ev.TaskID != "" {
-						style = style.Foreground(colorAccent)
+						style = style.Foreground(weekTaskColor(ev))
 					} else {
 						style = style.Foreground(colorMuted)
 					}
@@ -722,7 +729,7 @@ func (m *tuiModel) renderWeekSlot(dayIdx, slot, width int) string {
 		text := ""
 		if ok {
 			if slot == ev.StartSlot {
-				text = ev.Summary
+				text = weekEventLabel(ev)
 			} else {
 				text = "|"
 			}
@@ -736,7 +743,7 @@ func (m *tuiModel) renderWeekSlot(dayIdx, slot, width int) string {
 		if ok && selected && selectedSlot && ev.Column == selectedEvent.Column {
 			cell = lipgloss.NewStyle().Background(colorAccent).Foreground(colorAccentText).Width(width).Render(text)
 		} else if ok && ev.TaskID != "" {
-			cell = lipgloss.NewStyle().Foreground(colorAccent).Width(width).Render(text)
+			cell = lipgloss.NewStyle().Foreground(weekTaskColor(ev)).Width(width).Render(text)
 		} else if ok || text != "" {
 			cell = lipgloss.NewStyle().Foreground(colorMuted).Width(width).Render(text)
 		}
@@ -762,6 +769,12 @@ func (m *tuiModel) renderWeekDetails(width int) string {
 				if task, ok := m.resolveTaskByID(ev.TaskID); ok {
 					lines = append(lines, fmt.Sprintf("List: %s", task.ListName))
 					lines = append(lines, fmt.Sprintf("Section: %s", task.Section))
+					if p := priorityLabel(task.Priority); p != "" {
+						lines = append(lines, fmt.Sprintf("Priority: %s", p))
+					}
+					if tags := formatTags(task.Tags); tags != "" {
+						lines = append(lines, fmt.Sprintf("Tags: %s", tags))
+					}
 					if text := recurrenceText(task.Recurrence, m.app.Location); text != "" {
 						lines = append(lines, fmt.Sprintf("Repeats: %s", text))
 					}
@@ -770,9 +783,12 @@ func (m *tuiModel) renderWeekDetails(width int) string {
 		}
 	} else {
 		if task, ok := m.selectedWeekTask(); ok {
-			lines = append(lines, lipgloss.NewStyle().Bold(true).Render(recurringTitle(task.TitleVal, task.Recurrence)))
+			lines = append(lines, priorityStyledTitle(lipgloss.NewStyle().Bold(true).Render(recurringTitle(task.TitleVal, task.Recurrence)), task.Priority))
 			lines = append(lines, fmt.Sprintf("List: %s", task.ListName))
 			lines = append(lines, fmt.Sprintf("Section: %s", task.Section))
+			if tags := formatTags(task.Tags); tags != "" {
+				lines = append(lines, fmt.Sprintf("Tags: %s", tags))
+			}
 			if task.HasDue {
 				lines = append(lines, fmt.Sprintf("Due: %s", task.Due.Format("2006-01-02")))
 			}
@@ -864,6 +880,20 @@ func slotRange(start, end time.Time) (int, int) {
 	return startSlot, endSlot
 }
 
+func weekEventLabel(ev weekEvent) string {
+	if label := priorityLabel(ev.Priority); label != "" {
+		return label + " " + ev.Summary
+	}
+	return ev.Summary
+}
+
+func weekTaskColor(ev weekEvent) lipgloss.Color {
+	if color, ok := priorityColors[ev.Priority]; ok {
+		return color
+	}
+	return colorAccent
+}
+
 func truncateText(text string, max int) string {
 	if max <= 0 {
 		return ""
@@ -3,30 +3,38 @@ package cli
 import (
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
+
+	"justdoit/internal/history"
 )
 
 func newUndoCmd() *cobra.Command {
 	var (
 		list      string
 		markEvent bool
-		title     string
-		section   string
+		match     titleQuery
+		last      bool
+		entryID   int
 	)
 	cmd := &cobra.Command{
 		Use:   "undo [taskID]",
-		Short: "Mark a completed task as not completed (and optionally unmark calendar event)",
+		Short: "Revert the last change (--last, --id) or mark a completed task as not completed",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 1 {
 				return cobra.ExactArgs(1)(cmd, args)
 			}
+			if last || entryID > 0 {
+				if len(args) > 0 || match.isSet() {
+					return errors.New("--last/--id cannot be combined with a task")
+				}
+				return nil
+			}
 			if len(args) == 1 {
 				return nil
 			}
-			if strings.TrimSpace(title) == "" {
-				return errors.New("requires either [taskID] arg or --title")
+			if !match.isSet() {
+				return errors.New("requires [taskID], --title, --last or --id")
 			}
 			return nil
 		},
@@ -35,32 +43,53 @@ func newUndoCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			listID, err := resolveListID(app, list, list != "")
-			if err != nil {
-				return err
+			if last || entryID > 0 {
+				var entry history.Entry
+				if entryID > 0 {
+					entry, err = loadHistoryEntry(app, func(log *history.Log) *history.Entry { return log.Find(entryID) }, fmt.Sprintf("history entry #%d not found", entryID))
+					if err == nil {
+						entry, err = undoEntry(app, entry)
+					}
+				} else {
+					entry, err = undoLast(app)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("↩️  Undone: %s %s\n", entry.Action, entry.Summary)
+				return nil
 			}
-
-			taskID := ""
+			listID, taskID := "", ""
 			if len(args) == 1 {
-				taskID = args[0]
+				target, err := resolveTaskRef(app, list, args[0])
+				if err != nil {
+					return err
+				}
+				listID, taskID = target.ListID, target.ID
 			} else {
-				resolved, err := resolveTaskIDByTitleInteractiveWithOptions(app, listID, strings.TrimSpace(title), strings.TrimSpace(section), true)
+				target, err := resolveTaskByTitle(app, list, match, true)
 				if err != nil {
 					return err
 				}
-				taskID = resolved
+				listID, taskID = target.ListID, target.ID
 			}
 
-			if err := markTaskUndone(app, listID, taskID, markEvent); err != nil {
+			if err := recordOp(app, "undone", taskScope(listID, taskID), func() error {
+				return markTaskUndone(app, listID, taskID, markEvent)
+			}); err != nil {
 				return err
 			}
 			fmt.Println("↩️  Task marked as not completed")
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json); found from the task ID when omitted")
 	cmd.Flags().BoolVar(&markEvent, "mark-event", true, "Remove ✅ prefix from linked calendar event")
-	cmd.Flags().StringVar(&title, "title", "", "Undo completion by exact title (alternative to taskID)")
-	cmd.Flags().StringVar(&section, "section", "", "Only match tasks in this section when using --title")
+	cmd.Flags().StringVar(&match.Text, "title", "", "Undo completion by title, fuzzy matched across lists (alternative to taskID)")
+	cmd.Flags().StringVar(&match.Section, "section", "", "Only match tasks in this section when using --title")
+	cmd.Flags().BoolVar(&match.First, "first", false, "With --title, take the best match instead of asking")
+	cmd.Flags().BoolVar(&match.Strict, "strict", false, "With --title, only accept an exact title and never ask")
+	cmd.Flags().BoolVar(&last, "last", false, "Revert the most recent change (see `justdoit history`)")
+	cmd.Flags().IntVar(&entryID, "id", 0, "Revert a specific history entry")
 	return cmd
 }
@@ -9,6 +9,7 @@ import (
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/tasks/v1"
 
+	"justdoit/internal/cache"
 	"justdoit/internal/metadata"
 	"justdoit/internal/sync"
 	"justdoit/internal/timeparse"
@@ -16,48 +17,117 @@ import (
 
 func newUpdateCmd() *cobra.Command {
 	var (
-		list    string
-		dateStr string
-		timeStr string
-		section string
-		notes   string
-		title   string
+		list     string
+		dateStr  string
+		timeStr  string
+		section  string
+		notes    string
+		title    string
+		priority string
+		startStr string
+		tagFlags []string
+		untag    []string
+		match    titleQuery
+		bulk     bulkOptions
 	)
 	cmd := &cobra.Command{
-		Use:   "update [taskID] [new title]",
-		Short: "Update a task (title/date/time/section)",
-		Args:  cobra.MinimumNArgs(1),
+		Use:   "update [taskID[,taskID...]] [new title]",
+		Short: "Update tasks (title/date/time/section/priority)",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if !bulk.hasSource(args) && !match.isSet() {
+				return fmt.Errorf("requires [taskID], --match, --query or --stdin")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			app, err := initApp(cmd)
 			if err != nil {
 				return err
 			}
 
-			listID, err := resolveListID(app, list, list != "")
+			var targets []bulkTarget
+			newTitle := title
+			if match.isSet() {
+				// The task is picked by --match, so every argument is the new title.
+				if len(args) > 0 {
+					newTitle = strings.Join(args, " ")
+				}
+				var target bulkTarget
+				target, err = resolveTaskByTitle(app, list, match, false)
+				targets = []bulkTarget{target}
+			} else {
+				var ids []string
+				var argTitle string
+				ids, argTitle, err = splitUpdateArgs(loadTaskCache(app), args)
+				if err != nil {
+					return err
+				}
+				if argTitle != "" {
+					newTitle = argTitle
+				}
+				targets, err = collectTargets(app, list, ids, bulk)
+			}
 			if err != nil {
 				return err
 			}
 
-			taskID := args[0]
-			newTitle := title
-			if len(args) > 1 {
-				newTitle = strings.Join(args[1:], " ")
+			newPriority, err := parsePriority(priority)
+			if err != nil {
+				return err
+			}
+			startDate, err := parseStartInput(startStr, app.Now(), app.Location)
+			if err != nil {
+				return err
+			}
+			addTags, err := parseTags(tagFlags)
+			if err != nil {
+				return err
+			}
+			removeTags, err := parseTags(untag)
+			if err != nil {
+				return err
 			}
 
 			params := UpdateParams{
-				Title:      newTitle,
-				HasTitle:   newTitle != "",
-				Notes:      notes,
-				HasNotes:   cmd.Flags().Changed("notes"),
-				Section:    section,
-				HasSection: cmd.Flags().Changed("section"),
-				Date:       dateStr,
-				HasDate:    cmd.Flags().Changed("date"),
-				Time:       timeStr,
-				HasTime:    cmd.Flags().Changed("time"),
+				Title:       newTitle,
+				HasTitle:    newTitle != "",
+				Notes:       notes,
+				HasNotes:    cmd.Flags().Changed("notes"),
+				Section:     section,
+				HasSection:  cmd.Flags().Changed("section"),
+				Date:        dateStr,
+				HasDate:     cmd.Flags().Changed("date"),
+				Time:        timeStr,
+				HasTime:     cmd.Flags().Changed("time"),
+				Priority:    newPriority,
+				HasPriority: cmd.Flags().Changed("priority"),
+				Start:       startDate,
+				HasStart:    cmd.Flags().Changed("start"),
+				AddTags:     addTags,
+				RemoveTags:  removeTags,
 			}
 
-			result, err := updateTaskWithParams(app, listID, taskID, params)
+			if bulk.isBulk(targets) {
+				if params.HasTitle {
+					return fmt.Errorf("a new title can only be set on one task")
+				}
+				if !bulk.DryRun && params.HasSection && strings.TrimSpace(section) != "" {
+					// Create the section once so parallel updates don't race.
+					if err := ensureTargetSections(app, targets, section); err != nil {
+						return err
+					}
+				}
+				return runBulk(app, targets, bulk, "update", "updated", targetScope(targets), func(target bulkTarget) ([]string, error) {
+					_, err := updateTaskWithParams(app, target.ListID, target.ID, params)
+					return nil, err
+				})
+			}
+
+			var result UpdateResult
+			err = recordOp(app, "update", targetScope(targets), func() error {
+				result, err = updateTaskWithParams(app, targets[0].ListID, targets[0].ID, params)
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -75,28 +145,49 @@ func newUpdateCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json)")
+	cmd.Flags().StringVar(&list, "list", "", "List name (mapped via config.json); found from the task ID when omitted")
 	cmd.Flags().StringVar(&title, "title", "", "New title")
 	cmd.Flags().StringVar(&dateStr, "date", "", "Due date (natural language, e.g. 'tomorrow')")
 	cmd.Flags().StringVar(&timeStr, "time", "", "Time block (HH:MM-HH:MM or 1h)")
 	cmd.Flags().StringVar(&section, "section", "", "Move task to section (sublist)")
 	cmd.Flags().StringVar(&notes, "notes", "", "Replace task notes")
+	cmd.Flags().StringVar(&priority, "priority", "", "Priority (p1-p4, or none to clear)")
+	cmd.Flags().StringVar(&startStr, "start", "", "Start date that hides the task from next until then (or none to clear)")
+	cmd.Flags().StringSliceVar(&tagFlags, "tag", nil, "Add a tag (repeatable)")
+	cmd.Flags().StringSliceVar(&untag, "untag", nil, "Remove a tag (repeatable)")
+	cmd.Flags().StringVar(&match.Text, "match", "", "Pick the task by title, fuzzy matched across lists (alternative to taskID)")
+	cmd.Flags().BoolVar(&match.First, "first", false, "With --match, take the best match instead of asking")
+	cmd.Flags().BoolVar(&match.Strict, "strict", false, "With --match, only accept an exact title and never ask")
+	addBulkFlags(cmd, &bulk)
 
 	return cmd
 }
 
-func mergeNotes(userNotes, existing string) string {
-	result := strings.TrimSpace(userNotes)
-	for _, key := range []string{sync.TaskEventIDKey, "justdoit_rrule", "justdoit_section"} {
-		if value, ok := metadata.Extract(existing, key); ok {
-			result = metadata.Append(result, key, value)
-		}
+// splitUpdateArgs takes the first argument as the task and the rest as its
+// new title. When the rest is only task references (update id1 id2), the
+// caller meant several tasks; renaming id1 to "id2" would be a surprise, so
+// that is an error pointing at the comma form and --title.
+func splitUpdateArgs(c *cache.Cache, args []string) ([]string, string, error) {
+	if len(args) == 0 {
+		return nil, "", nil
+	}
+	rest := args[1:]
+	refs := len(rest) > 0
+	for _, arg := range rest {
+		refs = refs && isTaskRef(c, arg)
+	}
+	if refs {
+		return nil, "", fmt.Errorf("update takes one task argument: use %s to update several, or --title to rename", strings.Join(args, ","))
 	}
-	return result
+	return args[:1], strings.Join(rest, " "), nil
+}
+
+func mergeNotes(userNotes, existing string) string {
+	return metadata.WithText(existing, userNotes)
 }
 
 func findLinkedEvent(app *App, task *tasks.Task) (*calendar.Event, bool, error) {
-	if eventID, ok := sync.ExtractMetadata(task.Notes, sync.TaskEventIDKey); ok {
+	if eventID, ok := sync.TaskEventID.Get(task.Notes); ok {
 		event, err := app.Calendar.GetEvent(app.Config.CalendarID, eventID)
 		if err == nil {
 			return event, true, nil
@@ -111,7 +202,8 @@ func findLinkedEvent(app *App, task *tasks.Task) (*calendar.Event, bool, error)
 func createLinkedEvent(app *App, task *tasks.Task, start, end *time.Time) (*calendar.Event, error) {
 	event := &calendar.Event{
 		Summary:     task.Title,
-		Description: metadata.Append("", sync.EventTaskIDKey, task.Id),
+		Description: sync.EventTaskID.Set("", task.Id),
+		ColorId:     tagEventColor(app.Config, notesTags(task.Notes)),
 		Start: &calendar.EventDateTime{
 			DateTime: start.Format(time.RFC3339),
 		},
@@ -10,7 +10,7 @@ import (
 	"google.golang.org/api/calendar/v3"
 
 	"justdoit/internal/agenda"
-	"justdoit/internal/metadata"
+	"justdoit/internal/sync"
 	"justdoit/internal/timeparse"
 )
 
@@ -157,7 +157,7 @@ func collectTasks(app *App, day time.Time) ([]taskView, error) {
 			due = due.In(app.Location)
 			if sameDay(due, day) {
 				task := taskView{ID: t.Id, Title: t.Title, List: name, Due: due}
-				if rule, ok := metadata.Extract(t.Notes, "justdoit_rrule"); ok {
+				if rule, ok := sync.TaskRRule.Get(t.Notes); ok {
 					task.Recurrence = rule
 				}
 				result = append(result, task)
@@ -9,9 +9,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
 
 	"justdoit/internal/cache"
-	"justdoit/internal/metadata"
 	"justdoit/internal/sync"
 )
 
@@ -112,7 +112,7 @@ func buildWeekDataFromCache(app *App, c *cache.Cache, weekStart time.Time) (week
 				continue
 			}
 			name := calendarNames[calendarID]
-			taskID, _ := sync.ExtractMetadata(e.Description, sync.EventTaskIDKey)
+			taskID, _ := sync.EventTaskID.Get(e.Description)
 			if taskID != "" {
 				taskHasEvent[taskID] = true
 			}
@@ -124,6 +124,7 @@ func buildWeekDataFromCache(app *App, c *cache.Cache, weekStart time.Time) (week
 				Start:        start,
 				End:          end,
 				AllDay:       allDay,
+				Priority:     taskByID[taskID].Priority,
 			}
 			if allDay {
 				addAllDayEvent(allDayByDay, days, event)
@@ -146,11 +147,12 @@ func buildWeekDataFromCache(app *App, c *cache.Cache, weekStart time.Time) (week
 			dayStart := time.Date(task.Due.Year(), task.Due.Month(), task.Due.Day(), 0, 0, 0, 0, app.Location)
 			dayEnd := dayStart.AddDate(0, 0, 1)
 			event := weekEvent{
-				Summary: recurringTitle(task.TitleVal, task.Recurrence),
-				TaskID:  task.ID,
-				Start:   dayStart,
-				End:     dayEnd,
-				AllDay:  true,
+				Summary:  recurringTitle(task.TitleVal, task.Recurrence),
+				TaskID:   task.ID,
+				Start:    dayStart,
+				End:      dayEnd,
+				AllDay:   true,
+				Priority: task.Priority,
 			}
 			addAllDayEvent(allDayByDay, days, event)
 		}
@@ -254,13 +256,13 @@ func collectTasksFromCache(app *App, c *cache.Cache, start, end time.Time) ([]ta
 		}
 		sections := map[string]string{}
 		for _, entry := range items {
-			if _, ok := metadata.Extract(entry.Notes, "justdoit_section"); ok {
+			if sectionMarker.Has(entry.Notes) {
 				sections[entry.ID] = entry.Title
 			}
 		}
 		for _, entry := range items {
 			section := "General"
-			if parent, ok := sections[entry.Parent]; ok {
+			if parent, ok := sections[sectionIDOf(&tasks.Task{Parent: entry.Parent, Notes: entry.Notes}, sections)]; ok {
 				section = parent
 			}
 			due, hasDue, hasTime := parseTaskDue(entry.Due, app.Location)
@@ -274,11 +276,13 @@ func collectTasksFromCache(app *App, c *cache.Cache, start, end time.Time) ([]ta
 				HasDue:   hasDue,
 				HasTime:  hasTime,
 				Recurrence: func() string {
-					if rule, ok := metadata.Extract(entry.Notes, "justdoit_rrule"); ok {
+					if rule, ok := sync.TaskRRule.Get(entry.Notes); ok {
 						return rule
 					}
 					return ""
 				}(),
+				Priority: notesPriority(entry.Notes),
+				Tags:     notesTags(entry.Notes),
 			}
 			byID[item.ID] = item
 			if !hasDue {
@@ -16,6 +16,15 @@ type Config struct {
 	WorkdayEnd           string            `json:"workday_end"`
 	Timezone             string            `json:"timezone"`
 	Lists                map[string]string `json:"lists"`
+	TagColors            map[string]string `json:"tag_colors,omitempty"`
+	TokenStore           string            `json:"token_store,omitempty"`
+	CachePath            string            `json:"cache_path,omitempty"`
+	AutoLists            bool              `json:"auto_lists,omitempty"`
+	ListAliases          map[string]string `json:"list_aliases,omitempty"`
+	// ListTitles is the Google Tasks title of each mapped list ID as last
+	// seen by list sync, so renames in Google Tasks can be told apart from
+	// local names chosen by the user.
+	ListTitles map[string]string `json:"list_titles,omitempty"`
 }
 
 func Load(path string) (*Config, error) {
@@ -32,9 +41,39 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// ListID resolves a list name or an alias from list_aliases.
 func (c *Config) ListID(name string) (string, bool) {
-	id, ok := c.Lists[name]
-	return id, ok
+	if id, ok := c.Lists[name]; ok {
+		return id, true
+	}
+	if target, ok := c.ListAliases[name]; ok {
+		id, ok := c.Lists[target]
+		return id, ok
+	}
+	return "", false
+}
+
+// Clone returns a deep copy of c.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.ViewCalendars = append([]string(nil), c.ViewCalendars...)
+	clone.BacklogExcludedLists = append([]string(nil), c.BacklogExcludedLists...)
+	clone.Lists = cloneMap(c.Lists)
+	clone.TagColors = cloneMap(c.TagColors)
+	clone.ListAliases = cloneMap(c.ListAliases)
+	clone.ListTitles = cloneMap(c.ListTitles)
+	return &clone
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
 }
 
 func Save(path string, cfg *Config) error {
@@ -106,6 +145,19 @@ func normalize(cfg *Config) {
 	if cfg.Lists == nil {
 		cfg.Lists = map[string]string{}
 	}
+	cfg.TokenStore = strings.ToLower(strings.TrimSpace(cfg.TokenStore))
+	if len(cfg.TagColors) > 0 {
+		colors := make(map[string]string, len(cfg.TagColors))
+		for tag, color := range cfg.TagColors {
+			tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "+"))
+			color = strings.TrimSpace(color)
+			if tag == "" || color == "" {
+				continue
+			}
+			colors[tag] = color
+		}
+		cfg.TagColors = colors
+	}
 	if len(cfg.ViewCalendars) == 0 {
 		cfg.ViewCalendars = []string{cfg.CalendarID}
 	} else {
@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a config.json setting that can be read and written by key
+// (`config get/set`), and for Layered fields overridden by a JUSTDOIT_*
+// environment variable or a global flag.
+type Field struct {
+	Key     string
+	Help    string
+	Layered bool
+	get     func(*Config) string
+	set     func(*Config, string) error
+}
+
+// Fields are the scalar settings. Lists, aliases and tag colors are
+// addressed as lists.<name>, list_aliases.<alias> and tag_colors.<tag>.
+var Fields = []Field{
+	{
+		Key: "calendar_id", Help: "Calendar for time-blocked events", Layered: true,
+		get: func(c *Config) string { return c.CalendarID },
+		set: func(c *Config, v string) error { c.CalendarID = v; return nil },
+	},
+	{
+		Key: "default_list", Help: "List used when --list is omitted", Layered: true,
+		get: func(c *Config) string { return c.DefaultList },
+		set: func(c *Config, v string) error { c.DefaultList = v; return nil },
+	},
+	{
+		Key: "timezone", Help: "IANA timezone or \"local\"", Layered: true,
+		get: func(c *Config) string { return c.Timezone },
+		set: func(c *Config, v string) error {
+			if v != "" && !strings.EqualFold(v, "local") {
+				if _, err := time.LoadLocation(v); err != nil {
+					return fmt.Errorf("unknown timezone %q", v)
+				}
+			}
+			c.Timezone = v
+			return nil
+		},
+	},
+	{
+		Key: "workday_start", Help: "Start of the workday (HH:MM)", Layered: true,
+		get: func(c *Config) string { return c.WorkdayStart },
+		set: func(c *Config, v string) error { return setClock(&c.WorkdayStart, v) },
+	},
+	{
+		Key: "workday_end", Help: "End of the workday (HH:MM)", Layered: true,
+		get: func(c *Config) string { return c.WorkdayEnd },
+		set: func(c *Config, v string) error { return setClock(&c.WorkdayEnd, v) },
+	},
+	{
+		Key: "view_calendars", Help: "Comma-separated calendars shown in views", Layered: true,
+		get: func(c *Config) string { return strings.Join(c.ViewCalendars, ",") },
+		set: func(c *Config, v string) error { c.ViewCalendars = splitList(v); return nil },
+	},
+	{
+		Key: "backlog_excluded_lists", Help: "Comma-separated lists left out of the backlog",
+		get: func(c *Config) string { return strings.Join(c.BacklogExcludedLists, ",") },
+		set: func(c *Config, v string) error { c.BacklogExcludedLists = splitList(v); return nil },
+	},
+	{
+		Key: "cache_path", Help: "Cache file location (defaults to the profile directory)", Layered: true,
+		get: func(c *Config) string { return c.CachePath },
+		set: func(c *Config, v string) error { c.CachePath = v; return nil },
+	},
+	{
+		Key: "auto_lists", Help: "Map every Google Tasks list by its name and follow renames",
+		get: func(c *Config) string { return strconv.FormatBool(c.AutoLists) },
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.AutoLists = false
+				return nil
+			}
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("want true or false")
+			}
+			c.AutoLists = enabled
+			return nil
+		},
+	},
+	{
+		Key: "token_store", Help: "auto, file, secret-service or keyring",
+		get: func(c *Config) string { return c.TokenStore },
+		set: func(c *Config, v string) error {
+			v = strings.ToLower(v)
+			for _, store := range tokenStores {
+				if v == store {
+					c.TokenStore = v
+					return nil
+				}
+			}
+			return fmt.Errorf("token_store must be auto, file, secret-service or keyring")
+		},
+	},
+}
+
+// LookupField returns the scalar field named key.
+func LookupField(key string) (Field, bool) {
+	for _, field := range Fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// EnvName is the environment variable that overrides a layered field.
+func (f Field) EnvName() string {
+	return "JUSTDOIT_" + strings.ToUpper(f.Key)
+}
+
+// FlagName is the global flag that overrides a layered field.
+func (f Field) FlagName() string {
+	return strings.ReplaceAll(f.Key, "_", "-")
+}
+
+// Get returns the value of key, which may also be lists.<name>,
+// list_aliases.<alias> or tag_colors.<tag>.
+func Get(c *Config, key string) (string, error) {
+	if alias, ok := strings.CutPrefix(key, "list_aliases."); ok {
+		name, found := c.ListAliases[alias]
+		if !found {
+			return "", fmt.Errorf("alias %q is not defined", alias)
+		}
+		return name, nil
+	}
+	if name, ok := strings.CutPrefix(key, "lists."); ok {
+		id, found := c.Lists[name]
+		if !found {
+			return "", fmt.Errorf("list %q is not mapped", name)
+		}
+		return id, nil
+	}
+	if tag, ok := strings.CutPrefix(key, "tag_colors."); ok {
+		color, found := c.TagColors[normalizeTag(tag)]
+		if !found {
+			return "", fmt.Errorf("tag %q has no color", tag)
+		}
+		return color, nil
+	}
+	field, ok := LookupField(key)
+	if !ok {
+		return "", unknownKey(key)
+	}
+	return field.get(c), nil
+}
+
+// Set stores value under key. An empty value removes a lists.<name>,
+// list_aliases.<alias> or tag_colors.<tag> entry and resets a scalar field
+// to its default.
+func Set(c *Config, key, value string) error {
+	value = strings.TrimSpace(value)
+	if alias, ok := strings.CutPrefix(key, "list_aliases."); ok {
+		if alias == "" {
+			return fmt.Errorf("alias is required: list_aliases.<alias>")
+		}
+		if value == "" {
+			delete(c.ListAliases, alias)
+			return nil
+		}
+		if _, ok := c.Lists[value]; !ok {
+			return fmt.Errorf("alias target %q is not in lists", value)
+		}
+		if c.ListAliases == nil {
+			c.ListAliases = map[string]string{}
+		}
+		c.ListAliases[alias] = value
+		return nil
+	}
+	if name, ok := strings.CutPrefix(key, "lists."); ok {
+		if name == "" {
+			return fmt.Errorf("list name is required: lists.<name>")
+		}
+		if value == "" {
+			delete(c.Lists, name)
+			return nil
+		}
+		if c.Lists == nil {
+			c.Lists = map[string]string{}
+		}
+		c.Lists[name] = value
+		return nil
+	}
+	if tag, ok := strings.CutPrefix(key, "tag_colors."); ok {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			return fmt.Errorf("tag is required: tag_colors.<tag>")
+		}
+		if value == "" {
+			delete(c.TagColors, tag)
+			return nil
+		}
+		if c.TagColors == nil {
+			c.TagColors = map[string]string{}
+		}
+		c.TagColors[tag] = value
+		return nil
+	}
+	field, ok := LookupField(key)
+	if !ok {
+		return unknownKey(key)
+	}
+	if err := field.set(c, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	normalize(c)
+	return nil
+}
+
+// Keys lists every key Get accepts for c, sorted.
+func Keys(c *Config) []string {
+	keys := []string{}
+	for _, field := range Fields {
+		keys = append(keys, field.Key)
+	}
+	for name := range c.Lists {
+		keys = append(keys, "lists."+name)
+	}
+	for tag := range c.TagColors {
+		keys = append(keys, "tag_colors."+tag)
+	}
+	for alias := range c.ListAliases {
+		keys = append(keys, "list_aliases."+alias)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Override is a value from the environment or a flag that wins over
+// config.json for this run.
+type Override struct {
+	Key    string
+	Value  string
+	Source string
+	// previous is the config.json value, restored before saving; effective
+	// is the value as stored after applying the override.
+	previous  string
+	effective string
+}
+
+// EnvOverrides collects the JUSTDOIT_* variables set for layered fields.
+func EnvOverrides(getenv func(string) string) []Override {
+	overrides := []Override{}
+	for _, field := range Fields {
+		if !field.Layered {
+			continue
+		}
+		if value := strings.TrimSpace(getenv(field.EnvName())); value != "" {
+			overrides = append(overrides, Override{Key: field.Key, Value: value, Source: "env " + field.EnvName()})
+		}
+	}
+	return overrides
+}
+
+// ApplyOverrides sets each override on c, later ones winning, and returns
+// them ready for WithoutOverrides.
+func ApplyOverrides(c *Config, overrides []Override) ([]Override, error) {
+	applied := make([]Override, 0, len(overrides))
+	for _, override := range overrides {
+		previous, err := Get(c, override.Key)
+		if err != nil {
+			return nil, err
+		}
+		if err := Set(c, override.Key, override.Value); err != nil {
+			return nil, fmt.Errorf("%s: %w", override.Source, err)
+		}
+		override.previous = previous
+		override.effective, _ = Get(c, override.Key)
+		applied = append(applied, override)
+	}
+	return applied, nil
+}
+
+// WithoutOverrides returns a copy of c with every overridden field that was
+// not changed since back at its config.json value, so saving never writes
+// env or flag values to disk.
+func WithoutOverrides(c *Config, applied []Override) *Config {
+	clone := c.Clone()
+	for i := len(applied) - 1; i >= 0; i-- {
+		override := applied[i]
+		field, ok := LookupField(override.Key)
+		if !ok {
+			continue
+		}
+		if field.get(clone) == override.effective {
+			_ = field.set(clone, override.previous)
+		}
+	}
+	normalize(clone)
+	return clone
+}
+
+func setClock(dst *string, value string) error {
+	if value != "" {
+		if _, err := parseClock(value); err != nil {
+			return err
+		}
+	}
+	*dst = value
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "+"))
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q (see: justdoit config get)", key)
+}
@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestOverridesAreNotSaved(t *testing.T) {
+	cfg := Default()
+	cfg.Timezone = "UTC"
+	overrides := EnvOverrides(func(name string) string {
+		if name == "JUSTDOIT_TIMEZONE" {
+			return "Europe/Berlin"
+		}
+		return ""
+	})
+	overrides = append(overrides, Override{Key: "workday_start", Value: "08:00", Source: "flag --workday-start"})
+	applied, err := ApplyOverrides(cfg, overrides)
+	if err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	if cfg.Timezone != "Europe/Berlin" || cfg.WorkdayStart != "08:00" {
+		t.Fatalf("overrides not applied: %+v", cfg)
+	}
+
+	// A change made during the run is kept; untouched overrides revert.
+	cfg.WorkdayStart = "07:00"
+	saved := WithoutOverrides(cfg, applied)
+	if saved.Timezone != "UTC" {
+		t.Fatalf("timezone = %q, want the config.json value", saved.Timezone)
+	}
+	if saved.WorkdayStart != "07:00" {
+		t.Fatalf("workday_start = %q, want the value changed during the run", saved.WorkdayStart)
+	}
+	if cfg.Timezone != "Europe/Berlin" {
+		t.Fatalf("WithoutOverrides modified the live config")
+	}
+}
+
+func TestSetValidatesAndAddressesMaps(t *testing.T) {
+	cfg := Default()
+	if err := Set(cfg, "workday_end", "25:00"); err == nil {
+		t.Fatalf("expected an invalid clock to be rejected")
+	}
+	if err := Set(cfg, "nope", "x"); err == nil {
+		t.Fatalf("expected an unknown key to be rejected")
+	}
+	if err := Set(cfg, "lists.Work", "abc"); err != nil {
+		t.Fatalf("Set list: %v", err)
+	}
+	if id, err := Get(cfg, "lists.Work"); err != nil || id != "abc" {
+		t.Fatalf("Get list = %q, %v", id, err)
+	}
+	if err := Set(cfg, "lists.Work", ""); err != nil {
+		t.Fatalf("remove list: %v", err)
+	}
+	if _, ok := cfg.Lists["Work"]; ok {
+		t.Fatalf("list not removed")
+	}
+	if err := Set(cfg, "view_calendars", "a, b,,"); err != nil || len(cfg.ViewCalendars) != 2 {
+		t.Fatalf("view_calendars = %v, %v", cfg.ViewCalendars, err)
+	}
+}
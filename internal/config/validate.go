@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var tokenStores = []string{"", "auto", "file", "secret-service", "keyring"}
+
+// Problems lists the settings that are invalid on their own, without asking
+// Google: bad clocks or timezone, a default list that is not mapped, and
+// similar. An empty result means the config is usable.
+func (c *Config) Problems() []string {
+	problems := []string{}
+	if c.Timezone != "" && !strings.EqualFold(c.Timezone, "local") {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("timezone %q is not a known IANA zone", c.Timezone))
+		}
+	}
+	start, startErr := parseClock(c.WorkdayStart)
+	if startErr != nil {
+		problems = append(problems, fmt.Sprintf("workday_start %q: %v", c.WorkdayStart, startErr))
+	}
+	end, endErr := parseClock(c.WorkdayEnd)
+	if endErr != nil {
+		problems = append(problems, fmt.Sprintf("workday_end %q: %v", c.WorkdayEnd, endErr))
+	}
+	if startErr == nil && endErr == nil && end <= start {
+		problems = append(problems, fmt.Sprintf("workday_end %s must be after workday_start %s", c.WorkdayEnd, c.WorkdayStart))
+	}
+	if strings.TrimSpace(c.CalendarID) == "" {
+		problems = append(problems, "calendar_id is empty")
+	}
+	if _, ok := c.ListID(c.DefaultList); !ok {
+		problems = append(problems, fmt.Sprintf("default_list %q is not in lists", c.DefaultList))
+	}
+	names := make([]string, 0, len(c.Lists))
+	for name := range c.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, "lists has an entry with an empty name")
+		}
+		if strings.TrimSpace(c.Lists[name]) == "" {
+			problems = append(problems, fmt.Sprintf("list %q has no ID", name))
+		}
+	}
+	aliases := make([]string, 0, len(c.ListAliases))
+	for alias := range c.ListAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if _, ok := c.Lists[c.ListAliases[alias]]; !ok {
+			problems = append(problems, fmt.Sprintf("list alias %q points at %q, which is not in lists", alias, c.ListAliases[alias]))
+		}
+	}
+	for _, name := range c.BacklogExcludedLists {
+		if _, ok := c.Lists[name]; !ok {
+			problems = append(problems, fmt.Sprintf("backlog_excluded_lists names %q, which is not in lists", name))
+		}
+	}
+	known := false
+	for _, store := range tokenStores {
+		known = known || c.TokenStore == store
+	}
+	if !known {
+		problems = append(problems, fmt.Sprintf("token_store %q is not one of auto, file, secret-service, keyring", c.TokenStore))
+	}
+	return problems
+}
+
+// parseClock returns minutes since midnight for an HH:MM clock.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("want HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
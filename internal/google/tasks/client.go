@@ -92,7 +92,7 @@ func (c *Client) ListTasksWithOptions(listID string, showCompleted, showHidden,
 	return resp.Items, nil
 }
 
-func (c *Client) MoveTask(listID, taskID, parentID string) (*tasks.Task, error) {
+func (c *Client) MoveTask(listID, taskID, parentID, previousID string) (*tasks.Task, error) {
 	if listID == "" || taskID == "" {
 		return nil, fmt.Errorf("listID and taskID are required")
 	}
@@ -100,6 +100,9 @@ func (c *Client) MoveTask(listID, taskID, parentID string) (*tasks.Task, error)
 	if parentID != "" {
 		call.Parent(parentID)
 	}
+	if previousID != "" {
+		call.Previous(previousID)
+	}
 	return call.Do()
 }
 
@@ -0,0 +1,182 @@
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+)
+
+// DiffTasks compares two snapshots of one list and returns a change for every
+// task that was created, deleted or modified.
+func DiffTasks(listID string, before, after []*tasks.Task) []Change {
+	beforeByID := indexTasks(before)
+	afterByID := indexTasks(after)
+	changes := []Change{}
+	for _, item := range before {
+		if item == nil || item.Deleted {
+			continue
+		}
+		next := afterByID[item.Id]
+		if next != nil && !taskChanged(item, next) {
+			continue
+		}
+		changes = append(changes, Change{
+			ListID:   listID,
+			Before:   item,
+			After:    next,
+			Previous: previousSibling(before, item),
+		})
+	}
+	for _, item := range after {
+		if item == nil || item.Deleted || beforeByID[item.Id] != nil {
+			continue
+		}
+		changes = append(changes, Change{ListID: listID, After: item})
+	}
+	return changes
+}
+
+func indexTasks(items []*tasks.Task) map[string]*tasks.Task {
+	byID := make(map[string]*tasks.Task, len(items))
+	for _, item := range items {
+		if item != nil && !item.Deleted {
+			byID[item.Id] = item
+		}
+	}
+	return byID
+}
+
+func taskChanged(a, b *tasks.Task) bool {
+	return a.Title != b.Title ||
+		a.Notes != b.Notes ||
+		a.Status != b.Status ||
+		a.Due != b.Due ||
+		a.Parent != b.Parent ||
+		a.Position != b.Position
+}
+
+// previousSibling returns the ID of the task right before item under the same
+// parent, or "" when it is first.
+func previousSibling(items []*tasks.Task, item *tasks.Task) string {
+	var previous *tasks.Task
+	for _, other := range items {
+		if other == nil || other.Deleted || other.Id == item.Id || other.Parent != item.Parent {
+			continue
+		}
+		if other.Position < item.Position && (previous == nil || other.Position > previous.Position) {
+			previous = other
+		}
+	}
+	if previous == nil {
+		return ""
+	}
+	return previous.Id
+}
+
+// EventChanged reports whether the parts of an event justdoit edits differ.
+// A nil event means it does not exist.
+func EventChanged(a, b *calendar.Event) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	return a.Summary != b.Summary ||
+		a.Description != b.Description ||
+		a.ColorId != b.ColorId ||
+		!reflect.DeepEqual(a.Start, b.Start) ||
+		!reflect.DeepEqual(a.End, b.End) ||
+		!reflect.DeepEqual(a.Recurrence, b.Recurrence)
+}
+
+// Describe summarises changes for the history listing, naming the first task.
+func Describe(changes []Change) string {
+	title := ""
+	count := 0
+	for _, change := range changes {
+		if change.IsEvent() {
+			continue
+		}
+		count++
+		if title != "" {
+			continue
+		}
+		if change.Before != nil {
+			title = change.Before.Title
+		} else if change.After != nil {
+			title = change.After.Title
+		}
+	}
+	switch {
+	case count == 0:
+		return fmt.Sprintf("%d event(s)", len(changes))
+	case count == 1:
+		return title
+	default:
+		return fmt.Sprintf("%s (+%d more)", title, count-1)
+	}
+}
+
+// ListIDs returns the task lists touched by changes.
+func ListIDs(changes []Change) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, change := range changes {
+		if change.ListID != "" && !seen[change.ListID] {
+			seen[change.ListID] = true
+			ids = append(ids, change.ListID)
+		}
+	}
+	return ids
+}
+
+// TaskIDs returns every task changes touch, before or after the change.
+func TaskIDs(changes []Change) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, change := range changes {
+		for _, item := range []*tasks.Task{change.Before, change.After} {
+			if item != nil && item.Id != "" && !seen[item.Id] {
+				seen[item.Id] = true
+				ids = append(ids, item.Id)
+			}
+		}
+	}
+	return ids
+}
+
+// EventIDs returns the events that currently exist according to changes.
+func EventIDs(changes []Change) []string {
+	ids := []string{}
+	for _, change := range changes {
+		if change.EventAfter != nil {
+			ids = append(ids, change.EventAfter.Id)
+		}
+	}
+	return ids
+}
+
+// RecreateOrder sorts deleted tasks so parents come before their children and
+// siblings keep their order.
+func RecreateOrder(changes []Change) []Change {
+	deleted := []Change{}
+	for _, change := range changes {
+		if change.Before != nil && change.After == nil {
+			deleted = append(deleted, change)
+		}
+	}
+	depth := func(change Change) int {
+		if change.Before.Parent == "" {
+			return 0
+		}
+		return 1
+	}
+	sort.SliceStable(deleted, func(i, j int) bool {
+		if depth(deleted[i]) != depth(deleted[j]) {
+			return depth(deleted[i]) < depth(deleted[j])
+		}
+		return deleted[i].Before.Position < deleted[j].Before.Position
+	})
+	return deleted
+}
@@ -0,0 +1,166 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+)
+
+// MaxEntries caps the log; the oldest entries are dropped first.
+const MaxEntries = 200
+
+type Log struct {
+	Version int     `json:"version"`
+	NextID  int     `json:"next_id"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is one user action (a command or a TUI key press) and everything it
+// changed.
+type Entry struct {
+	ID      int      `json:"id"`
+	Time    string   `json:"time"`
+	Action  string   `json:"action"`
+	Summary string   `json:"summary"`
+	Changes []Change `json:"changes"`
+	Undone  bool     `json:"undone,omitempty"`
+	// UndoneSeq orders undos so redo picks the most recent one.
+	UndoneSeq int `json:"undone_seq,omitempty"`
+	// Reverted holds the changes made by the undo; redo reverts them again.
+	Reverted []Change `json:"reverted,omitempty"`
+}
+
+// Change is a before/after snapshot of one task or one calendar event. A nil
+// Before means the operation created it, a nil After that it was deleted.
+type Change struct {
+	ListID string      `json:"list_id,omitempty"`
+	Before *tasks.Task `json:"before,omitempty"`
+	After  *tasks.Task `json:"after,omitempty"`
+	// Previous is the sibling the task followed before the change, used to
+	// restore its position.
+	Previous string `json:"previous,omitempty"`
+
+	CalendarID  string          `json:"calendar_id,omitempty"`
+	EventBefore *calendar.Event `json:"event_before,omitempty"`
+	EventAfter  *calendar.Event `json:"event_after,omitempty"`
+}
+
+func (c Change) IsEvent() bool {
+	return c.EventBefore != nil || c.EventAfter != nil
+}
+
+func Default() *Log {
+	return &Log{Version: 1, NextID: 1}
+}
+
+func Load(path string) (*Log, error) {
+	// #nosec G304 -- path is controlled by the app config location
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return nil, err
+	}
+	var log Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, err
+	}
+	if log.Version == 0 {
+		log.Version = 1
+	}
+	if log.NextID == 0 {
+		log.NextID = 1
+	}
+	return &log, nil
+}
+
+func Save(path string, log *Log) error {
+	if log == nil {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Append records a new entry. Undone entries can no longer be redone once
+// something new happens, so they are dropped.
+func (l *Log) Append(entry Entry) Entry {
+	kept := l.Entries[:0]
+	for _, existing := range l.Entries {
+		if !existing.Undone {
+			kept = append(kept, existing)
+		}
+	}
+	entry.ID = l.NextID
+	l.NextID++
+	kept = append(kept, entry)
+	if len(kept) > MaxEntries {
+		kept = kept[len(kept)-MaxEntries:]
+	}
+	l.Entries = kept
+	return entry
+}
+
+func (l *Log) Find(id int) *Entry {
+	for i := range l.Entries {
+		if l.Entries[i].ID == id {
+			return &l.Entries[i]
+		}
+	}
+	return nil
+}
+
+// LastUndoable returns the most recent entry that is not undone.
+func (l *Log) LastUndoable() *Entry {
+	for i := len(l.Entries) - 1; i >= 0; i-- {
+		if !l.Entries[i].Undone {
+			return &l.Entries[i]
+		}
+	}
+	return nil
+}
+
+// LastRedoable returns the most recently undone entry.
+func (l *Log) LastRedoable() *Entry {
+	var found *Entry
+	for i := range l.Entries {
+		entry := &l.Entries[i]
+		if entry.Undone && (found == nil || entry.UndoneSeq > found.UndoneSeq) {
+			found = entry
+		}
+	}
+	return found
+}
+
+// MarkUndone flags entry as undone, keeping the changes the undo made.
+func (l *Log) MarkUndone(entry *Entry, reverted []Change) {
+	seq := 0
+	for _, existing := range l.Entries {
+		if existing.UndoneSeq > seq {
+			seq = existing.UndoneSeq
+		}
+	}
+	entry.Undone = true
+	entry.UndoneSeq = seq + 1
+	entry.Reverted = reverted
+}
+
+// MarkRedone flags entry as applied again; changes are the ones the redo made.
+func (l *Log) MarkRedone(entry *Entry, changes []Change) {
+	entry.Undone = false
+	entry.UndoneSeq = 0
+	entry.Reverted = nil
+	entry.Changes = changes
+}
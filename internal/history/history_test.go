@@ -0,0 +1,103 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestDiffTasks(t *testing.T) {
+	before := []*tasks.Task{
+		{Id: "a", Title: "First", Position: "1"},
+		{Id: "b", Title: "Second", Position: "2"},
+		{Id: "c", Title: "Third", Position: "3"},
+	}
+	after := []*tasks.Task{
+		{Id: "a", Title: "First", Position: "1"},
+		{Id: "c", Title: "Third", Position: "3", Status: "completed"},
+		{Id: "d", Title: "Spawned", Position: "4"},
+	}
+	changes := DiffTasks("list-1", before, after)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %#v", changes)
+	}
+	deleted, modified, created := changes[0], changes[1], changes[2]
+	if deleted.Before.Id != "b" || deleted.After != nil || deleted.Previous != "a" {
+		t.Fatalf("unexpected delete %#v", deleted)
+	}
+	if modified.Before.Id != "c" || modified.After.Status != "completed" || modified.Previous != "b" {
+		t.Fatalf("unexpected update %#v", modified)
+	}
+	if created.Before != nil || created.After.Id != "d" || created.ListID != "list-1" {
+		t.Fatalf("unexpected create %#v", created)
+	}
+	if got := Describe(changes); got != "Second (+2 more)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+
+func TestEventChanged(t *testing.T) {
+	event := &calendar.Event{Id: "e", Summary: "Call", Start: &calendar.EventDateTime{DateTime: "2026-01-01T10:00:00Z"}}
+	same := &calendar.Event{Id: "e", Summary: "Call", Start: &calendar.EventDateTime{DateTime: "2026-01-01T10:00:00Z"}, Etag: "new"}
+	if EventChanged(event, same) {
+		t.Fatalf("etag alone should not count as a change")
+	}
+	if !EventChanged(event, nil) || !EventChanged(nil, event) {
+		t.Fatalf("expected created/deleted events to count as changes")
+	}
+	if EventChanged(nil, nil) {
+		t.Fatalf("expected missing events to be equal")
+	}
+}
+
+func TestLogUndoRedoOrder(t *testing.T) {
+	log := Default()
+	first := log.Append(Entry{Action: "add"})
+	second := log.Append(Entry{Action: "delete"})
+	if got := log.LastUndoable(); got == nil || got.ID != second.ID {
+		t.Fatalf("expected #%d to be undoable, got %#v", second.ID, got)
+	}
+	log.MarkUndone(log.Find(second.ID), nil)
+	log.MarkUndone(log.Find(first.ID), nil)
+	if got := log.LastUndoable(); got != nil {
+		t.Fatalf("expected nothing left to undo, got #%d", got.ID)
+	}
+	if got := log.LastRedoable(); got == nil || got.ID != first.ID {
+		t.Fatalf("expected #%d to be redone first, got %#v", first.ID, got)
+	}
+
+	log.MarkRedone(log.Find(first.ID), nil)
+	third := log.Append(Entry{Action: "move"})
+	if log.Find(second.ID) != nil {
+		t.Fatalf("expected undone entries to be dropped by a new change")
+	}
+	if third.ID != 3 || len(log.Entries) != 2 {
+		t.Fatalf("unexpected entries %#v", log.Entries)
+	}
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := Save(path, log); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if loaded.NextID != 4 || len(loaded.Entries) != 2 {
+		t.Fatalf("unexpected loaded log %#v", loaded)
+	}
+}
+
+func TestTaskIDs(t *testing.T) {
+	changes := []Change{
+		{Before: &tasks.Task{Id: "a"}, After: &tasks.Task{Id: "a"}},
+		{Before: &tasks.Task{Id: "b"}},
+		{After: &tasks.Task{Id: "c"}},
+		{EventBefore: &calendar.Event{Id: "ev"}},
+	}
+	if got := TaskIDs(changes); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("unexpected task IDs %v", got)
+	}
+}
@@ -0,0 +1,209 @@
+// Package ics writes iCalendar (RFC 5545) feeds: VTODOs for tasks and
+// VEVENTs for their time blocks.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	// maxLine is the longest content line in octets before folding.
+	maxLine = 75
+)
+
+type Calendar struct {
+	Name   string
+	Todos  []Todo
+	Events []Event
+}
+
+// Todo is a task. A zero Due means no due date; DueHasTime picks a
+// date-time over a plain date. Start is the day the task becomes actionable,
+// zero for none.
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	Due         time.Time
+	DueHasTime  bool
+	Completed   bool
+	CompletedAt time.Time
+	// RRule is the rule without the "RRULE:" prefix.
+	RRule      string
+	Categories []string
+	// Priority uses the iCalendar scale: 1 is highest, 9 lowest, 0 none.
+	Priority  int
+	RelatedTo string
+	Modified  time.Time
+}
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	// Recurrence holds full RRULE/EXDATE/RDATE lines as Google returns them.
+	Recurrence []string
+	RelatedTo  string
+	Modified   time.Time
+}
+
+// Write renders the calendar; stamp is used as DTSTAMP.
+func (c *Calendar) Write(w io.Writer, stamp time.Time) error {
+	out := &writer{w: bufio.NewWriter(w)}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//justdoit//justdoit//EN")
+	out.line("CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		out.text("X-WR-CALNAME", c.Name)
+	}
+	for _, todo := range c.Todos {
+		out.line("BEGIN:VTODO")
+		out.line("UID:" + todo.UID)
+		out.line("DTSTAMP:" + stamp.UTC().Format(dateTimeFormat))
+		out.text("SUMMARY", todo.Summary)
+		if todo.Description != "" {
+			out.text("DESCRIPTION", todo.Description)
+		}
+		if start, ok := todo.start(); ok {
+			if todo.Due.IsZero() || !todo.DueHasTime {
+				out.line("DTSTART;VALUE=DATE:" + start.Format(dateFormat))
+			} else {
+				out.line("DTSTART:" + start.UTC().Format(dateTimeFormat))
+			}
+		}
+		if !todo.Due.IsZero() {
+			if todo.DueHasTime {
+				out.line("DUE:" + todo.Due.UTC().Format(dateTimeFormat))
+			} else {
+				out.line("DUE;VALUE=DATE:" + todo.Due.Format(dateFormat))
+			}
+			if todo.RRule != "" {
+				out.line("RRULE:" + todo.RRule)
+			}
+		}
+		if todo.Completed {
+			out.line("STATUS:COMPLETED")
+			if !todo.CompletedAt.IsZero() {
+				out.line("COMPLETED:" + todo.CompletedAt.UTC().Format(dateTimeFormat))
+			}
+		} else {
+			out.line("STATUS:NEEDS-ACTION")
+		}
+		if len(todo.Categories) > 0 {
+			escaped := make([]string, 0, len(todo.Categories))
+			for _, category := range todo.Categories {
+				escaped = append(escaped, escapeText(category))
+			}
+			out.line("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		if todo.Priority > 0 {
+			out.line(fmt.Sprintf("PRIORITY:%d", todo.Priority))
+		}
+		if todo.RelatedTo != "" {
+			out.line("RELATED-TO:" + todo.RelatedTo)
+		}
+		if !todo.Modified.IsZero() {
+			out.line("LAST-MODIFIED:" + todo.Modified.UTC().Format(dateTimeFormat))
+		}
+		out.line("END:VTODO")
+	}
+	for _, event := range c.Events {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + event.UID)
+		out.line("DTSTAMP:" + stamp.UTC().Format(dateTimeFormat))
+		out.text("SUMMARY", event.Summary)
+		if event.Description != "" {
+			out.text("DESCRIPTION", event.Description)
+		}
+		if event.AllDay {
+			out.line("DTSTART;VALUE=DATE:" + event.Start.Format(dateFormat))
+			out.line("DTEND;VALUE=DATE:" + event.End.Format(dateFormat))
+		} else {
+			out.line("DTSTART:" + event.Start.UTC().Format(dateTimeFormat))
+			out.line("DTEND:" + event.End.UTC().Format(dateTimeFormat))
+		}
+		for _, rule := range event.Recurrence {
+			out.line(rule)
+		}
+		if event.RelatedTo != "" {
+			out.line("RELATED-TO:" + event.RelatedTo)
+		}
+		if !event.Modified.IsZero() {
+			out.line("LAST-MODIFIED:" + event.Modified.UTC().Format(dateTimeFormat))
+		}
+		out.line("END:VEVENT")
+	}
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// start returns the DTSTART of a todo: the start day at midnight. RFC 5545
+// wants DTSTART strictly before DUE, so a start that is not is left out,
+// except that a recurring todo needs one and gets the day before its due.
+func (t Todo) start() (time.Time, bool) {
+	start := t.Start
+	if !start.IsZero() {
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	}
+	if t.Due.IsZero() {
+		return start, !start.IsZero()
+	}
+	if !start.IsZero() && start.Before(t.Due) && (t.DueHasTime || start.Format(dateFormat) < t.Due.Format(dateFormat)) {
+		return start, true
+	}
+	if t.RRule != "" {
+		return t.Due.AddDate(0, 0, -1), true
+	}
+	return time.Time{}, false
+}
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (o *writer) text(name, value string) {
+	o.line(name + ":" + escapeText(value))
+}
+
+// line writes one content line, folded at 75 octets without splitting a
+// UTF-8 sequence, with CRLF endings.
+func (o *writer) line(s string) {
+	if o.err != nil {
+		return
+	}
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if _, o.err = o.w.WriteString(s[:cut] + "\r\n "); o.err != nil {
+			return
+		}
+		s = s[cut:]
+		// Continuation lines start with a space, which counts.
+		limit = maxLine - 1
+	}
+	_, o.err = o.w.WriteString(s + "\r\n")
+}
+
+func escapeText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
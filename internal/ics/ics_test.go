@@ -0,0 +1,106 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	due := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	cal := &Calendar{
+		Name: "justdoit",
+		Todos: []Todo{{
+			UID:         "t1@justdoit",
+			Summary:     "Pay rent; utilities, too",
+			Description: "line one\nline two",
+			Due:         due,
+			RRule:       "FREQ=MONTHLY",
+			Categories:  []string{"Home", "Bills, paper"},
+			Priority:    1,
+		}},
+		Events: []Event{{
+			UID:     "ev1@google.com",
+			Summary: "Pay rent",
+			Start:   time.Date(2026, 3, 2, 9, 0, 0, 0, time.FixedZone("CET", 3600)),
+			End:     time.Date(2026, 3, 2, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+		}},
+	}
+	var buf bytes.Buffer
+	if err := cal.Write(&buf, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"SUMMARY:Pay rent\\; utilities\\, too\r\n",
+		"DESCRIPTION:line one\\nline two\r\n",
+		"DTSTART;VALUE=DATE:20260301\r\n",
+		"DUE;VALUE=DATE:20260302\r\n",
+		"RRULE:FREQ=MONTHLY\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"CATEGORIES:Home,Bills\\, paper\r\n",
+		"DTSTART:20260302T080000Z\r\n",
+		"DTEND:20260302T090000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTodoStart(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	due := time.Date(2026, 3, 9, 0, 0, 0, 0, loc)
+	dueAt := time.Date(2026, 3, 9, 15, 0, 0, 0, loc)
+	start := time.Date(2026, 3, 5, 0, 0, 0, 0, loc)
+	for name, tc := range map[string]struct {
+		todo Todo
+		want string
+	}{
+		"due only":             {Todo{Due: due}, ""},
+		"start before due":     {Todo{Start: start, Due: due}, "DTSTART;VALUE=DATE:20260305"},
+		"start on due day":     {Todo{Start: due, Due: due}, ""},
+		"start after due":      {Todo{Start: due.AddDate(0, 0, 1), Due: due}, ""},
+		"start without due":    {Todo{Start: start}, "DTSTART;VALUE=DATE:20260305"},
+		"timed due":            {Todo{Start: start, Due: dueAt, DueHasTime: true}, "DTSTART:20260305T050000Z"},
+		"start day, timed due": {Todo{Start: due, Due: dueAt, DueHasTime: true}, "DTSTART:20260309T050000Z"},
+		"recurring":            {Todo{Due: due, RRule: "FREQ=WEEKLY"}, "DTSTART;VALUE=DATE:20260308"},
+		"recurring, timed":     {Todo{Due: dueAt, DueHasTime: true, RRule: "FREQ=WEEKLY"}, "DTSTART:20260308T200000Z"},
+		"recurring with start": {Todo{Start: start, Due: due, RRule: "FREQ=WEEKLY"}, "DTSTART;VALUE=DATE:20260305"},
+	} {
+		var buf bytes.Buffer
+		tc.todo.UID = "x"
+		if err := (&Calendar{Todos: []Todo{tc.todo}}).Write(&buf, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		for _, line := range strings.Split(buf.String(), "\r\n") {
+			if strings.HasPrefix(line, "DTSTART") {
+				got = line
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%s: DTSTART = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	var buf bytes.Buffer
+	cal := &Calendar{Todos: []Todo{{UID: "x", Summary: strings.Repeat("é", 100)}}}
+	if err := cal.Write(&buf, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Fatalf("folding lost data:\n%s", buf.String())
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line of %d octets: %q", len(line), line)
+		}
+	}
+}
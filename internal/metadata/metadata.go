@@ -1,28 +1,376 @@
+// Package metadata stores justdoit bookkeeping inside free-form task notes and
+// calendar event descriptions.
+//
+// Values live in a delimited, versioned block appended after the user's text:
+//
+//	Buy the blue one, not the red one.
+//
+//	[justdoit:v1]
+//	justdoit_event_id=abc123
+//	justdoit_rrule=RRULE:FREQ=WEEKLY
+//	[/justdoit]
+//
+// Entries keep the historical key=value shape so older builds (and the
+// Calendar full-text search used to find events by task ID) still see them.
+// Loose key=value lines written by those older builds are migrated into the
+// block the first time the text is rewritten, but only for registered keys,
+// so user lines that happen to contain "=" are never touched.
 package metadata
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-func Append(text, key, value string) string {
-	marker := fmt.Sprintf("%s=%s", key, value)
-	if strings.Contains(text, marker) {
-		return text
+// Version is the block format written by Render.
+const Version = 1
+
+const blockClose = "[/justdoit]"
+
+var blockOpenRe = regexp.MustCompile(`^\[justdoit:v(\d+)\]$`)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]string{}
+)
+
+// Register records key as a known metadata field of the given kind. It is
+// called by the field constructors; registering the same key twice with a
+// different kind panics, since both owners would be decoding the same value.
+func Register(key, kind string) {
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, "=\n") {
+		panic(fmt.Sprintf("metadata: invalid key %q", key))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing, ok := registry[key]; ok && existing != kind {
+		panic(fmt.Sprintf("metadata: key %q already registered as %s", key, existing))
+	}
+	registry[key] = kind
+}
+
+// Registered returns the registered keys in sorted order.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	keys := make([]string, 0, len(registry))
+	for key := range registry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isRegistered(key string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[key]
+	return ok
+}
+
+// Block is the decoded metadata section of a text.
+type Block struct {
+	Version int
+	keys    []string
+	values  map[string]string
+}
+
+// Get returns the raw value stored for key.
+func (b *Block) Get(key string) (string, bool) {
+	if b == nil || b.values == nil {
+		return "", false
 	}
-	if text == "" {
-		return marker
+	value, ok := b.values[key]
+	return value, ok
+}
+
+// Set stores the raw value for key, keeping the original entry order.
+func (b *Block) Set(key, value string) {
+	if b.values == nil {
+		b.values = map[string]string{}
+	}
+	if _, ok := b.values[key]; !ok {
+		b.keys = append(b.keys, key)
 	}
-	return strings.TrimSpace(text) + "\n" + marker
+	b.values[key] = strings.ReplaceAll(value, "\n", " ")
 }
 
+// Delete removes key from the block.
+func (b *Block) Delete(key string) {
+	if b == nil || b.values == nil {
+		return
+	}
+	if _, ok := b.values[key]; !ok {
+		return
+	}
+	delete(b.values, key)
+	for i, k := range b.keys {
+		if k == key {
+			b.keys = append(b.keys[:i], b.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the stored keys in insertion order.
+func (b *Block) Keys() []string {
+	if b == nil {
+		return nil
+	}
+	return append([]string(nil), b.keys...)
+}
+
+// Empty reports whether the block holds no values.
+func (b *Block) Empty() bool {
+	return b == nil || len(b.keys) == 0
+}
+
+// Parse splits text into the user's text and its metadata block. The user's
+// text is returned byte for byte, minus the blank line Render puts before the
+// block. Legacy key=value lines for registered keys are lifted out of it.
+func Parse(text string) (string, Block) {
+	block := Block{Version: Version}
+	user := text
+	lines := strings.Split(text, "\n")
+	start, end := -1, -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == blockClose {
+			end = i
+			continue
+		}
+		if match := blockOpenRe.FindStringSubmatch(strings.TrimSpace(lines[i])); match != nil && end > i {
+			start = i
+			if v, err := strconv.Atoi(match[1]); err == nil {
+				block.Version = v
+			}
+			break
+		}
+	}
+	if start >= 0 {
+		for _, line := range lines[start+1 : end] {
+			if key, value, ok := splitEntry(line); ok {
+				block.Set(key, value)
+			}
+		}
+		before := strings.Join(lines[:start], "\n")
+		if start > 0 {
+			// before ended with the newline in front of the block line.
+			before += "\n"
+		}
+		if strings.HasSuffix(before, "\n\n") {
+			before = strings.TrimSuffix(before, "\n\n")
+		} else {
+			before = strings.TrimSuffix(before, "\n")
+		}
+		user = before
+		if end+1 < len(lines) {
+			if after := strings.Join(lines[end+1:], "\n"); after != "" {
+				if user != "" {
+					user += "\n"
+				}
+				user += after
+			}
+		}
+	}
+
+	userLines := strings.Split(user, "\n")
+	kept := make([]string, 0, len(userLines))
+	migrated := false
+	for _, line := range userLines {
+		key, value, ok := splitEntry(line)
+		if ok && isRegistered(key) {
+			if _, exists := block.Get(key); !exists {
+				block.Set(key, value)
+			}
+			migrated = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if migrated {
+		// Blank lines that only separated the legacy entries go with them.
+		user = strings.TrimRight(strings.Join(kept, "\n"), "\r\n")
+	}
+	return user, block
+}
+
+// Render joins the user's text and the block back into a single text.
+func Render(user string, block Block) string {
+	if block.Empty() {
+		return user
+	}
+	var b strings.Builder
+	if user != "" {
+		b.WriteString(user)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "[justdoit:v%d]\n", Version)
+	for _, key := range block.keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, block.values[key])
+	}
+	b.WriteString(blockClose)
+	return b.String()
+}
+
+// Strip returns only the user's part of text, unchanged.
+func Strip(text string) string {
+	user, _ := Parse(text)
+	return user
+}
+
+// WithText replaces the user's part of text, keeping its metadata.
+func WithText(text, user string) string {
+	_, block := Parse(text)
+	return Render(strings.TrimSpace(user), block)
+}
+
+// Append stores value under key, replacing any previous value.
+func Append(text, key, value string) string {
+	user, block := Parse(text)
+	block.Set(key, value)
+	return Render(user, block)
+}
+
+// Extract returns the raw value stored under key, also looking at legacy
+// lines for keys that were never registered.
 func Extract(text, key string) (string, bool) {
-	prefix := key + "="
+	_, block := Parse(text)
+	if value, ok := block.Get(key); ok {
+		return value, true
+	}
 	for _, line := range strings.Split(text, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, prefix) {
-			return strings.TrimPrefix(line, prefix), true
+		if k, value, ok := splitEntry(line); ok && k == key {
+			return value, true
 		}
 	}
 	return "", false
 }
+
+// Remove deletes key from the metadata of text.
+func Remove(text, key string) string {
+	user, block := Parse(text)
+	block.Delete(key)
+	return Render(user, block)
+}
+
+func splitEntry(line string) (string, string, bool) {
+	trim := strings.TrimSpace(line)
+	key, value, ok := strings.Cut(trim, "=")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// Field is a typed metadata entry. Fields are declared once by the package
+// that owns them, which also registers the key for legacy migration.
+type Field[T any] struct {
+	Key    string
+	encode func(T) string
+	decode func(string) (T, bool)
+}
+
+// NewField registers key and returns a field using the given codec.
+func NewField[T any](key, kind string, encode func(T) string, decode func(string) (T, bool)) Field[T] {
+	Register(key, kind)
+	return Field[T]{Key: key, encode: encode, decode: decode}
+}
+
+// Get decodes the field from text.
+func (f Field[T]) Get(text string) (T, bool) {
+	var zero T
+	raw, ok := Extract(text, f.Key)
+	if !ok {
+		return zero, false
+	}
+	return f.decode(raw)
+}
+
+// Has reports whether the field is present in text.
+func (f Field[T]) Has(text string) bool {
+	_, ok := Extract(text, f.Key)
+	return ok
+}
+
+// Set stores value in text.
+func (f Field[T]) Set(text string, value T) string {
+	return Append(text, f.Key, f.encode(value))
+}
+
+// Remove deletes the field from text.
+func (f Field[T]) Remove(text string) string {
+	return Remove(text, f.Key)
+}
+
+// String declares a plain string field.
+func String(key string) Field[string] {
+	return NewField(key, "string",
+		func(v string) string { return strings.TrimSpace(v) },
+		func(raw string) (string, bool) { return strings.TrimSpace(raw), true },
+	)
+}
+
+// Bool declares a flag stored as "1".
+func Bool(key string) Field[bool] {
+	return NewField(key, "bool",
+		func(v bool) string {
+			if v {
+				return "1"
+			}
+			return "0"
+		},
+		func(raw string) (bool, bool) {
+			switch strings.ToLower(strings.TrimSpace(raw)) {
+			case "1", "true", "yes":
+				return true, true
+			case "0", "false", "no":
+				return false, true
+			}
+			return false, false
+		},
+	)
+}
+
+// Int declares an integer field.
+func Int(key string) Field[int] {
+	return NewField(key, "int",
+		strconv.Itoa,
+		func(raw string) (int, bool) {
+			v, err := strconv.Atoi(strings.TrimSpace(raw))
+			return v, err == nil
+		},
+	)
+}
+
+// List declares a comma-separated list of strings.
+func List(key string) Field[[]string] {
+	return NewField(key, "list",
+		func(v []string) string {
+			clean := make([]string, 0, len(v))
+			for _, item := range v {
+				if item = strings.TrimSpace(item); item != "" {
+					clean = append(clean, item)
+				}
+			}
+			return strings.Join(clean, ",")
+		},
+		func(raw string) ([]string, bool) {
+			var values []string
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					values = append(values, item)
+				}
+			}
+			return values, true
+		},
+	)
+}
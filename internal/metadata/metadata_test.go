@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	testEventID = String("test_event_id")
+	testFlag    = Bool("test_flag")
+	testCount   = Int("test_count")
+	testTags    = List("test_tags")
+)
+
+func TestFieldRoundTrip(t *testing.T) {
+	text := "Buy milk"
+	text = testEventID.Set(text, "evt-1")
+	text = testFlag.Set(text, true)
+	text = testCount.Set(text, 3)
+	text = testTags.Set(text, []string{"errand", " home "})
+
+	want := "Buy milk\n\n[justdoit:v1]\ntest_event_id=evt-1\ntest_flag=1\ntest_count=3\ntest_tags=errand,home\n[/justdoit]"
+	if text != want {
+		t.Fatalf("unexpected render:\n%s", text)
+	}
+	if got, ok := testEventID.Get(text); !ok || got != "evt-1" {
+		t.Fatalf("unexpected event id %q", got)
+	}
+	if got, ok := testFlag.Get(text); !ok || !got {
+		t.Fatalf("expected flag to be set")
+	}
+	if got, ok := testCount.Get(text); !ok || got != 3 {
+		t.Fatalf("unexpected count %d", got)
+	}
+	if got, _ := testTags.Get(text); !reflect.DeepEqual(got, []string{"errand", "home"}) {
+		t.Fatalf("unexpected tags %#v", got)
+	}
+	if Strip(text) != "Buy milk" {
+		t.Fatalf("unexpected user text %q", Strip(text))
+	}
+
+	text = testCount.Remove(text)
+	if testCount.Has(text) {
+		t.Fatalf("expected count to be removed")
+	}
+}
+
+func TestParseMigratesLegacyLines(t *testing.T) {
+	legacy := "Call about invoice\nratio=2:1\ntest_event_id=evt-9"
+	user, block := Parse(legacy)
+	if user != "Call about invoice\nratio=2:1" {
+		t.Fatalf("expected unregistered lines to stay in user text, got %q", user)
+	}
+	if got, ok := block.Get("test_event_id"); !ok || got != "evt-9" {
+		t.Fatalf("expected legacy key to be migrated, got %q", got)
+	}
+
+	updated := testFlag.Set(legacy, true)
+	want := "Call about invoice\nratio=2:1\n\n[justdoit:v1]\ntest_event_id=evt-9\ntest_flag=1\n[/justdoit]"
+	if updated != want {
+		t.Fatalf("unexpected migrated text:\n%s", updated)
+	}
+}
+
+func TestWithTextPreservesMetadata(t *testing.T) {
+	text := testEventID.Set("  indented\n\nsecond paragraph", "evt-1")
+	if Strip(text) != "  indented\n\nsecond paragraph" {
+		t.Fatalf("unexpected user text %q", Strip(text))
+	}
+	replaced := WithText(text, "new notes")
+	if Strip(replaced) != "new notes" {
+		t.Fatalf("unexpected replaced text %q", Strip(replaced))
+	}
+	if got, _ := testEventID.Get(replaced); got != "evt-1" {
+		t.Fatalf("expected metadata to survive, got %q", got)
+	}
+	if WithText(testEventID.Remove(text), "") != "" {
+		t.Fatalf("expected empty text when nothing is left")
+	}
+}
+
+func TestUserTextSurvivesRendering(t *testing.T) {
+	for _, user := range []string{"", "plain", "trailing spaces   ", "two lines\n", "  both ends \n\n", "tab\t"} {
+		text := testEventID.Set(user, "evt-1")
+		text = testCount.Set(text, 2)
+		text = testCount.Remove(text)
+		if got := Strip(text); got != user {
+			t.Fatalf("user text %q came back as %q", user, got)
+		}
+		if got := Strip(testEventID.Remove(text)); got != user {
+			t.Fatalf("user text %q came back as %q without metadata", user, got)
+		}
+	}
+}
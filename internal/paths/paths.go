@@ -1,19 +1,182 @@
 package paths
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 const (
-	appDirName = "justdoit"
-	configFile = "config.json"
-	tokenFile  = "token.json"
-	credsFile  = "credentials.json"
-	cacheFile  = "cache.json"
+	appDirName  = "justdoit"
+	configFile  = "config.json"
+	tokenFile   = "token.json"
+	credsFile   = "credentials.json"
+	cacheFile   = "cache.json"
+	historyFile = "history.json"
+	profilesDir = "profiles"
+	// currentFile holds the profile chosen with `profile use`.
+	currentFile = "profile"
 )
 
+// DefaultProfile keeps its files directly in the app directory, as before
+// profiles existed.
+const DefaultProfile = "default"
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// override is the profile passed with --profile.
+var override string
+
+// SetProfile selects the profile for this run, taking precedence over
+// JUSTDOIT_PROFILE and `profile use`.
+func SetProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if err := ValidateProfile(name); err != nil {
+		return err
+	}
+	override = name
+	return nil
+}
+
+func ValidateProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// Profile returns the active profile: --profile, then JUSTDOIT_PROFILE, then
+// the one saved by `profile use`, then the default.
+func Profile() string {
+	if override != "" {
+		return override
+	}
+	if env := strings.TrimSpace(os.Getenv("JUSTDOIT_PROFILE")); env != "" {
+		return env
+	}
+	if current, err := CurrentProfile(); err == nil && current != "" {
+		return current
+	}
+	return DefaultProfile
+}
+
+// CurrentProfile returns the profile saved by `profile use`, or "".
+func CurrentProfile() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	// #nosec G304 -- fixed file in the app config directory
+	data, err := os.ReadFile(filepath.Join(dir, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetCurrentProfile saves name as the profile used when none is given.
+func SetCurrentProfile(name string) error {
+	dir, err := appDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, currentFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0o600)
+}
+
+// Profiles lists the default profile and every profile directory.
+func Profiles() ([]string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	entries, err := os.ReadDir(filepath.Join(dir, profilesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && profileName.MatchString(entry.Name()) && entry.Name() != DefaultProfile {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// ProfileDir returns where profile keeps its config, token and cache.
+func ProfileDir(profile string) (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" || profile == DefaultProfile {
+		return dir, nil
+	}
+	if err := ValidateProfile(profile); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profilesDir, profile), nil
+}
+
+// Files are the paths one profile uses.
+type Files struct {
+	Dir         string
+	Config      string
+	Token       string
+	Credentials string
+	Cache       string
+	History     string
+}
+
+// ForProfile returns the files of profile. A profile without its own
+// credentials.json shares the default one.
+func ForProfile(profile string) (Files, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return Files{}, err
+	}
+	files := Files{
+		Dir:         dir,
+		Config:      filepath.Join(dir, configFile),
+		Token:       filepath.Join(dir, tokenFile),
+		Credentials: filepath.Join(dir, credsFile),
+		Cache:       filepath.Join(dir, cacheFile),
+		History:     filepath.Join(dir, historyFile),
+	}
+	if _, err := os.Stat(files.Credentials); errors.Is(err, os.ErrNotExist) {
+		if base, err := appDir(); err == nil {
+			files.Credentials = filepath.Join(base, credsFile)
+		}
+	}
+	return files, nil
+}
+
+// ConfigDir is the active profile's directory.
 func ConfigDir() (string, error) {
+	return ProfileDir(Profile())
+}
+
+func appDir() (string, error) {
+	if dir := os.Getenv("JUSTDOIT_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
 	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
 		return filepath.Join(xdg, appDirName), nil
 	}
@@ -31,33 +194,26 @@ func ConfigDir() (string, error) {
 }
 
 func ConfigPath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, configFile), nil
+	files, err := ForProfile(Profile())
+	return files.Config, err
 }
 
 func TokenPath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, tokenFile), nil
+	files, err := ForProfile(Profile())
+	return files.Token, err
 }
 
 func CredentialsPath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, credsFile), nil
+	files, err := ForProfile(Profile())
+	return files.Credentials, err
 }
 
 func CachePath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, cacheFile), nil
+	files, err := ForProfile(Profile())
+	return files.Cache, err
+}
+
+func HistoryPath() (string, error) {
+	files, err := ForProfile(Profile())
+	return files.History, err
 }
@@ -0,0 +1,101 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useAppDir points the app directory at a fresh temp dir and clears every
+// profile source.
+func useAppDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("JUSTDOIT_CONFIG_DIR", dir)
+	t.Setenv("JUSTDOIT_PROFILE", "")
+	override = ""
+	t.Cleanup(func() { override = "" })
+	return dir
+}
+
+func TestProfileResolutionOrder(t *testing.T) {
+	useAppDir(t)
+	if got := Profile(); got != DefaultProfile {
+		t.Fatalf("expected the default profile, got %q", got)
+	}
+	if err := SetCurrentProfile("saved"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Profile(); got != "saved" {
+		t.Fatalf("expected the profile from `profile use`, got %q", got)
+	}
+	t.Setenv("JUSTDOIT_PROFILE", "env")
+	if got := Profile(); got != "env" {
+		t.Fatalf("expected JUSTDOIT_PROFILE over the saved profile, got %q", got)
+	}
+	if err := SetProfile("flag"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Profile(); got != "flag" {
+		t.Fatalf("expected --profile over JUSTDOIT_PROFILE, got %q", got)
+	}
+	if err := SetProfile("../etc"); err == nil {
+		t.Fatalf("expected an invalid profile name to be rejected")
+	}
+
+	override = ""
+	t.Setenv("JUSTDOIT_PROFILE", "")
+	if err := SetCurrentProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if got := Profile(); got != DefaultProfile {
+		t.Fatalf("expected `profile use default` to clear the saved profile, got %q", got)
+	}
+}
+
+func TestForProfileSharesDefaultCredentials(t *testing.T) {
+	dir := useAppDir(t)
+
+	files, err := ForProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := filepath.Join(dir, profilesDir, "work")
+	if files.Dir != workDir || files.Config != filepath.Join(workDir, configFile) || files.Token != filepath.Join(workDir, tokenFile) {
+		t.Fatalf("unexpected profile files %#v", files)
+	}
+	if files.Credentials != filepath.Join(dir, credsFile) {
+		t.Fatalf("expected the default credentials without a profile copy, got %s", files.Credentials)
+	}
+
+	if err := os.MkdirAll(workDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	own := filepath.Join(workDir, credsFile)
+	if err := os.WriteFile(own, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := ForProfile("work"); err != nil || files.Credentials != own {
+		t.Fatalf("expected the profile's own credentials, got %s (%v)", files.Credentials, err)
+	}
+
+	if files, err := ForProfile(DefaultProfile); err != nil || files.Dir != dir {
+		t.Fatalf("expected the default profile in the app directory, got %#v (%v)", files, err)
+	}
+}
+
+func TestProfilesListsDefaultFirst(t *testing.T) {
+	dir := useAppDir(t)
+	for _, name := range []string{"work", "home", "not valid"} {
+		if err := os.MkdirAll(filepath.Join(dir, profilesDir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != DefaultProfile || got[1] != "home" || got[2] != "work" {
+		t.Fatalf("unexpected profiles %v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package sync
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -14,8 +16,19 @@ import (
 )
 
 const (
-	EventTaskIDKey = "justdoit_task_id"
-	TaskEventIDKey = "justdoit_event_id"
+	EventTaskIDKey     = "justdoit_task_id"
+	TaskEventIDKey     = "justdoit_event_id"
+	TaskRRuleKey       = "justdoit_rrule"
+	TaskSeriesIDKey    = "justdoit_series"
+	TaskSpawnedFromKey = "justdoit_spawned_from"
+)
+
+var (
+	EventTaskID     = metadata.String(EventTaskIDKey)
+	TaskEventID     = metadata.String(TaskEventIDKey)
+	TaskRRule       = metadata.String(TaskRRuleKey)
+	TaskSeriesID    = metadata.String(TaskSeriesIDKey)
+	TaskSpawnedFrom = metadata.String(TaskSpawnedFromKey)
 )
 
 type Wrapper struct {
@@ -34,6 +47,13 @@ type CreateInput struct {
 	TimeStart   *time.Time
 	TimeEnd     *time.Time
 	ParentID    string
+	// SeriesID groups every instance of a recurring task. A new one is
+	// generated when empty and the task has a recurrence.
+	SeriesID string
+	// SpawnedFrom is the ID of the completed instance this task follows.
+	SpawnedFrom string
+	// ColorID is the calendar colorId for the event, if any.
+	ColorID string
 }
 
 func (w *Wrapper) Create(input CreateInput) (*tasks.Task, *calendar.Event, error) {
@@ -49,7 +69,15 @@ func (w *Wrapper) Create(input CreateInput) (*tasks.Task, *calendar.Event, error
 		task.Due = input.Due.Format(time.RFC3339)
 	}
 	if len(input.Recurrence) > 0 {
-		task.Notes = metadata.Append(task.Notes, "justdoit_rrule", strings.Join(input.Recurrence, ";"))
+		task.Notes = TaskRRule.Set(task.Notes, strings.Join(input.Recurrence, ";"))
+		seriesID := input.SeriesID
+		if seriesID == "" {
+			seriesID = NewSeriesID()
+		}
+		task.Notes = TaskSeriesID.Set(task.Notes, seriesID)
+		if input.SpawnedFrom != "" {
+			task.Notes = TaskSpawnedFrom.Set(task.Notes, input.SpawnedFrom)
+		}
 	}
 	var (
 		createdTask *tasks.Task
@@ -70,7 +98,7 @@ func (w *Wrapper) Create(input CreateInput) (*tasks.Task, *calendar.Event, error
 
 	event := &calendar.Event{
 		Summary:     title,
-		Description: metadata.Append("", EventTaskIDKey, createdTask.Id),
+		Description: EventTaskID.Set("", createdTask.Id),
 		Start: &calendar.EventDateTime{
 			DateTime: input.TimeStart.Format(time.RFC3339),
 		},
@@ -81,12 +109,15 @@ func (w *Wrapper) Create(input CreateInput) (*tasks.Task, *calendar.Event, error
 	if len(input.Recurrence) > 0 && input.RepeatEvent {
 		event.Recurrence = input.Recurrence
 	}
+	if input.ColorID != "" {
+		event.ColorId = input.ColorID
+	}
 	createdEvent, err := w.Calendar.CreateEvent(w.CalendarID, event)
 	if err != nil {
 		return createdTask, nil, err
 	}
 
-	createdTask.Notes = metadata.Append(createdTask.Notes, TaskEventIDKey, createdEvent.Id)
+	createdTask.Notes = TaskEventID.Set(createdTask.Notes, createdEvent.Id)
 	if _, err := w.Tasks.UpdateTask(input.ListID, createdTask); err != nil {
 		return createdTask, createdEvent, err
 	}
@@ -105,6 +136,11 @@ func ensureRecurringTitle(title string, hasRecurrence bool) string {
 	return "🔁 " + title
 }
 
-func ExtractMetadata(text, key string) (string, bool) {
-	return metadata.Extract(text, key)
+// NewSeriesID returns a random identifier for a new recurring series.
+func NewSeriesID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
 }
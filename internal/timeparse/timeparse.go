@@ -2,6 +2,8 @@ package timeparse
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +31,54 @@ func ParseDate(dateStr string, now time.Time, loc *time.Location) (time.Time, er
 	return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, loc), nil
 }
 
+// ParseFutureDate is ParseDate for inputs that point forward, such as snooze
+// targets: a bare weekday means the next one, not the last.
+func ParseFutureDate(dateStr string, now time.Time, loc *time.Location) (time.Time, error) {
+	if dateStr == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := naturaldate.Parse(dateStr, now.In(loc), naturaldate.WithDirection(naturaldate.Future))
+	if err != nil {
+		return ParseDate(dateStr, now, loc)
+	}
+	return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// ParseDateTime parses a natural date with a time of day, e.g. "next monday
+// 9am" or "15:00" (today). Like ParseFutureDate, bare weekdays look ahead.
+func ParseDateTime(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	parsed, err := naturaldate.Parse(value, now.In(loc), naturaldate.WithDirection(naturaldate.Future))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.In(loc), nil
+}
+
+var offsetRe = regexp.MustCompile(`^\+\s*(\d+)\s*(m|min|mins|minutes?|h|hours?|d|days?|w|weeks?)$`)
+
+// ParseOffset parses relative offsets such as "+2d", "+1w" or "+3h". Days and
+// weeks are returned as days so callers can keep the time of day across DST.
+func ParseOffset(value string) (days int, d time.Duration, ok bool) {
+	m := offsetRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if m == nil {
+		return 0, 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	switch m[2][0] {
+	case 'w':
+		return n * 7, 0, true
+	case 'd':
+		return n, 0, true
+	case 'h':
+		return 0, time.Duration(n) * time.Hour, true
+	default:
+		return 0, time.Duration(n) * time.Minute, true
+	}
+}
+
 func ParseClock(clock string, base time.Time, loc *time.Location) (time.Time, error) {
 	parts := strings.Split(clock, ":")
 	if len(parts) != 2 {